@@ -2,73 +2,44 @@ package auth
 
 import (
 	"errors"
-	"os"
 	"phrasmotica/bore-score-api/models"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
 type JWTClaim struct {
 	Username    string   `json:"username"`
 	Email       string   `json:"email"`
 	Permissions []string `json:"permissions"`
+	SessionID   string   `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
-const tokenLifetime = 1 * time.Hour
+// access tokens are short-lived, since clients can get a new one with their refresh token
+const tokenLifetime = 15 * time.Minute
 
-func GenerateJWT(user *models.User) (tokenString string, err error) {
+func GenerateJWT(user *models.User, sessionId string) (tokenString string, err error) {
 	expirationTime := time.Now().Add(tokenLifetime)
 
+	// these are only for the client's information, since permissions are checked against
+	// the database on each request
+	permissions := []string{}
+	for _, p := range user.EffectivePermissions() {
+		permissions = append(permissions, string(p))
+	}
+
 	claims := &JWTClaim{
 		Email:       user.Email,
 		Username:    user.Username,
-		Permissions: user.Permissions,
+		Permissions: permissions,
+		SessionID:   sessionId,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err = token.SignedString(jwtKey)
-	return
-}
-
-// https://www.sohamkamani.com/golang/jwt-authentication/
-func RefreshJWT(tokenStr string) (newToken string, err error) {
-	claims := &JWTClaim{}
-
-	newToken = ""
-
-	parsedToken, err := jwt.ParseWithClaims(
-		tokenStr,
-		claims,
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		},
-	)
-
-	if err != nil {
-		return
-	}
-
-	if !parsedToken.Valid {
-		return
-	}
-
-	// don't refresh if more than 30 seconds until expiry
-	if time.Until(time.Unix(claims.ExpiresAt, 0)) > 30*time.Second {
-		newToken = tokenStr
-		return
-	}
-
-	expirationTime := time.Now().Add(tokenLifetime)
-	claims.ExpiresAt = expirationTime.Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	newToken, err = token.SignedString(jwtKey)
+	tokenString, err = keys.sign(claims)
 	return
 }
 
@@ -76,9 +47,7 @@ func ValidateToken(signedToken string) (err error, claims *JWTClaim) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&JWTClaim{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		},
+		keys.keyFunc,
 	)
 
 	if err != nil {
@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// a public key that access tokens can be verified with
+type verificationKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	Key    crypto.PublicKey
+}
+
+type keyring struct {
+	signingKeyId  string
+	signingMethod jwt.SigningMethod
+	signingKey    interface{}
+
+	// keys that tokens can be verified with, by key ID. This includes the signing key,
+	// plus any keys that are being rotated in or out
+	verificationKeys map[string]verificationKey
+
+	// HS256 secret for tokens that don't have a key ID. These were issued before
+	// asymmetric keys were supported
+	secret []byte
+}
+
+var keys = &keyring{
+	verificationKeys: map[string]verificationKey{},
+}
+
+// LoadKeysFromEnv sets up the keys that access tokens are signed and verified with.
+// JWT_SIGNING_KEY_FILE is a PEM-encoded RSA or Ed25519 private key to sign tokens with,
+// and JWT_VERIFICATION_KEY_FILES is a comma-separated list of PEM-encoded keys that
+// tokens can also be verified with. JWT_SECRET_KEY is an HS256 secret that is used to
+// sign tokens if there is no signing key file, and to verify tokens without a key ID
+func LoadKeysFromEnv() error {
+	newKeys, err := loadKeys(
+		os.Getenv("JWT_SIGNING_KEY_FILE"),
+		splitList(os.Getenv("JWT_VERIFICATION_KEY_FILES")),
+		os.Getenv("JWT_SECRET_KEY"),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	keys = newKeys
+	return nil
+}
+
+func loadKeys(signingKeyFile string, verificationKeyFiles []string, secret string) (*keyring, error) {
+	newKeys := &keyring{
+		verificationKeys: map[string]verificationKey{},
+		secret:           []byte(secret),
+	}
+
+	if signingKeyFile != "" {
+		privateKey, err := readPrivateKey(signingKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newVerificationKey(privateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+
+		newKeys.signingKeyId = key.ID
+		newKeys.signingMethod = key.Method
+		newKeys.signingKey = privateKey
+		newKeys.verificationKeys[key.ID] = key
+	} else if secret != "" {
+		newKeys.signingMethod = jwt.SigningMethodHS256
+		newKeys.signingKey = newKeys.secret
+	} else {
+		return nil, errors.New("no JWT_SIGNING_KEY_FILE or JWT_SECRET_KEY environment variable found")
+	}
+
+	for _, file := range verificationKeyFiles {
+		publicKey, err := readPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newVerificationKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		newKeys.verificationKeys[key.ID] = key
+	}
+
+	return newKeys, nil
+}
+
+// signs the given claims with the current signing key
+func (k *keyring) sign(claims jwt.Claims) (string, error) {
+	if k.signingMethod == nil {
+		return "", errors.New("no signing key has been loaded")
+	}
+
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+
+	if k.signingKeyId != "" {
+		token.Header["kid"] = k.signingKeyId
+	}
+
+	return token.SignedString(k.signingKey)
+}
+
+// returns the key that the given token should be verified with, making sure that the
+// token's algorithm matches it
+func (k *keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		if len(k.secret) <= 0 || token.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("token has no key ID")
+		}
+
+		return k.secret, nil
+	}
+
+	key, ok := k.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %s", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("token algorithm %s does not match key %s", token.Method.Alg(), kid)
+	}
+
+	return key.Key, nil
+}
+
+// JSONWebKey is the public part of a verification key, as described in RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys that access tokens can currently be verified with
+func JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{
+		Keys: []JSONWebKey{},
+	}
+
+	for _, key := range keys.verificationKeys {
+		jwk, err := toJSONWebKey(key.Key)
+		if err != nil {
+			continue
+		}
+
+		jwk.KeyID = key.ID
+		jwk.Use = "sig"
+		jwk.Algorithm = key.Method.Alg()
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func newVerificationKey(publicKey crypto.PublicKey) (verificationKey, error) {
+	var method jwt.SigningMethod
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+
+	kid, err := thumbprint(publicKey)
+	if err != nil {
+		return verificationKey{}, err
+	}
+
+	return verificationKey{
+		ID:     kid,
+		Method: method,
+		Key:    publicKey,
+	}, nil
+}
+
+func toJSONWebKey(publicKey crypto.PublicKey) (JSONWebKey, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType:  "RSA",
+			Modulus:  base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+
+	return JSONWebKey{}, fmt.Errorf("unsupported key type %T", publicKey)
+}
+
+// returns the RFC 7638 thumbprint of the key, which is used as its key ID so that
+// key IDs don't need to be configured
+func thumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := toJSONWebKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// the thumbprint is of the required members only, in lexicographic order
+	var members interface{}
+	if jwk.KeyType == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.Exponent, jwk.KeyType, jwk.Modulus}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	bytes, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(bytes)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func readPrivateKey(file string) (crypto.Signer, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key in %s: %w", file, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key in %s", file)
+	}
+
+	return signer, nil
+}
+
+// reads a public key, or the public part of a private key
+func readPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "PUBLIC KEY" {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse public key in %s: %w", file, err)
+		}
+
+		return key, nil
+	}
+
+	privateKey, err := readPrivateKey(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return privateKey.Public(), nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", file)
+	}
+
+	return block, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
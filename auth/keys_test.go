@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func writeKey(t *testing.T, name string, key interface{}) string {
+	bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Could not marshal key: %s", err)
+	}
+
+	file := filepath.Join(t.TempDir(), name)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bytes})
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatalf("Could not write key: %s", err)
+	}
+
+	return file
+}
+
+func testClaims() *JWTClaim {
+	return &JWTClaim{
+		Username: "someone",
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, key := range map[string]interface{}{"rsa": rsaKey, "ed25519": edKey} {
+		keys, err := loadKeys(writeKey(t, name, key), nil, "")
+		if err != nil {
+			t.Fatalf("Could not load %s key: %s", name, err)
+		}
+
+		tokenString, err := keys.sign(testClaims())
+		if err != nil {
+			t.Fatalf("Could not sign with %s key: %s", name, err)
+		}
+
+		token, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, keys.keyFunc)
+		if err != nil || !token.Valid {
+			t.Errorf("Could not verify token signed with %s key: %s", name, err)
+		}
+
+		if token.Header["kid"] != keys.signingKeyId {
+			t.Errorf("Token signed with %s key has the wrong key ID: %s", name, token.Header["kid"])
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	oldFile := writeKey(t, "old", oldKey)
+	newFile := writeKey(t, "new", newKey)
+
+	oldKeys, _ := loadKeys(oldFile, nil, "")
+	tokenString, _ := oldKeys.sign(testClaims())
+
+	// tokens signed with the old key are still accepted while it's a verification key
+	rotatedKeys, err := loadKeys(newFile, []string{oldFile}, "")
+	if err != nil {
+		t.Fatalf("Could not load keys: %s", err)
+	}
+
+	if _, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, rotatedKeys.keyFunc); err != nil {
+		t.Errorf("Token signed with old key was rejected: %s", err)
+	}
+
+	if len(rotatedKeys.verificationKeys) != 2 {
+		t.Errorf("Wrong number of verification keys! Actual: %d", len(rotatedKeys.verificationKeys))
+	}
+
+	// and rejected once it's removed
+	newKeys, _ := loadKeys(newFile, nil, "")
+	if _, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, newKeys.keyFunc); err == nil {
+		t.Error("Token signed with removed key was accepted")
+	}
+}
+
+func TestSecretKey(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	secretKeys, err := loadKeys("", nil, "secret")
+	if err != nil {
+		t.Fatalf("Could not load secret key: %s", err)
+	}
+
+	tokenString, _ := secretKeys.sign(testClaims())
+
+	// tokens without a key ID are still accepted while the secret is configured
+	keys, _ := loadKeys(writeKey(t, "key", edKey), nil, "secret")
+	if _, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, keys.keyFunc); err != nil {
+		t.Errorf("Token signed with secret was rejected: %s", err)
+	}
+
+	keys, _ = loadKeys(writeKey(t, "key", edKey), nil, "")
+	if _, err := jwt.ParseWithClaims(tokenString, &JWTClaim{}, keys.keyFunc); err == nil {
+		t.Error("Token signed with secret was accepted without the secret")
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	if _, err := loadKeys("", nil, ""); err == nil {
+		t.Error("Loading no keys did not fail")
+	}
+}
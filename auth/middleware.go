@@ -4,6 +4,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"phrasmotica/bore-score-api/models"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -33,7 +34,43 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-func TokenAuth(optional bool) gin.HandlerFunc {
+// SessionValidator returns whether the given session of the given user has not been revoked
+type SessionValidator func(username string, sessionId string) bool
+
+var sessionValidator SessionValidator
+
+// SetSessionValidator sets the function that TokenAuth uses to reject access tokens
+// whose session has been revoked
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
+// APIKeyPrefix is the start of every API key, which tells them apart from access tokens
+const APIKeyPrefix = "bsk_"
+
+// APIKeyIdentity describes who an API key acts as, and what it can be used for
+type APIKeyIdentity struct {
+	KeyID    string
+	Username string
+	Email    string
+	Scopes   []models.APIKeyScope
+	GroupID  string
+}
+
+// APIKeyValidator returns who the given API key acts as, if it exists and has not been revoked
+type APIKeyValidator func(key string) (bool, *APIKeyIdentity)
+
+var apiKeyValidator APIKeyValidator
+
+// SetAPIKeyValidator sets the function that TokenAuth uses to authenticate API keys
+func SetAPIKeyValidator(validator APIKeyValidator) {
+	apiKeyValidator = validator
+}
+
+// TokenAuth authenticates the request's access token. API keys are also accepted if they
+// have at least one of the given scopes, so endpoints with no scopes can't be called
+// with an API key
+func TokenAuth(optional bool, scopes ...models.APIKeyScope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		success, tokenString := parseToken(c)
 
@@ -48,6 +85,11 @@ func TokenAuth(optional bool) gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(tokenString, APIKeyPrefix) {
+			authenticateAPIKey(c, tokenString, scopes)
+			return
+		}
+
 		err, claims := ValidateToken(tokenString)
 		if err != nil {
 			Error.Println(err.Error())
@@ -55,7 +97,14 @@ func TokenAuth(optional bool) gin.HandlerFunc {
 			return
 		}
 
+		if claims.SessionID != "" && sessionValidator != nil && !sessionValidator(claims.Username, claims.SessionID) {
+			Error.Printf("Session %s of user %s has been revoked\n", claims.SessionID, claims.Username)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
 		c.Set("token", tokenString)
+		c.Set("sessionId", claims.SessionID)
 
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
@@ -66,12 +115,75 @@ func TokenAuth(optional bool) gin.HandlerFunc {
 	}
 }
 
-func CheckPermission(permission string) gin.HandlerFunc {
+func authenticateAPIKey(c *gin.Context, key string, scopes []models.APIKeyScope) {
+	if len(scopes) <= 0 {
+		Error.Println("API keys cannot be used for this endpoint")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if apiKeyValidator == nil {
+		Error.Println("API keys are not supported")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	success, identity := apiKeyValidator(key)
+	if !success {
+		Error.Println("API key does not exist or has been revoked")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	hasScope := false
+	for _, s := range scopes {
+		if slices.Contains(identity.Scopes, s) {
+			hasScope = true
+			break
+		}
+	}
+
+	if !hasScope {
+		Error.Printf("API key %s does not have any of the scopes %v\n", identity.KeyID, scopes)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	c.Set("username", identity.Username)
+	c.Set("email", identity.Email)
+
+	// API keys never have any of the user's permissions
+	c.Set("permissions", []string{})
+
+	c.Set("apiKeyId", identity.KeyID)
+	c.Set("apiKeyGroupId", identity.GroupID)
+
+	c.Next()
+}
+
+// PermissionChecker returns whether the given user currently has the given permission
+type PermissionChecker func(username string, permission models.Permission) bool
+
+var permissionChecker PermissionChecker
+
+// SetPermissionChecker sets the function that CheckPermission uses to look up the user's
+// current permissions, so that revoking a permission takes effect before their access
+// token expires. Without one, the permissions in the access token are used
+func SetPermissionChecker(checker PermissionChecker) {
+	permissionChecker = checker
+}
+
+func CheckPermission(permission models.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username := c.GetString("username")
-		permissions := c.GetStringSlice("permissions")
 
-		if !slices.Contains(permissions, permission) {
+		if c.GetString("apiKeyId") != "" {
+			Error.Printf("API keys cannot use the %s permission\n", permission)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if !hasPermission(c, username, permission) {
 			Error.Printf("User %s does not have the %s permission\n", username, permission)
 			c.AbortWithStatus(http.StatusForbidden)
 			return
@@ -81,6 +193,15 @@ func CheckPermission(permission string) gin.HandlerFunc {
 	}
 }
 
+func hasPermission(c *gin.Context, username string, permission models.Permission) bool {
+	if permissionChecker != nil {
+		return permissionChecker(username, permission)
+	}
+
+	permissions := c.GetStringSlice("permissions")
+	return slices.Contains(permissions, string(models.Superuser)) || slices.Contains(permissions, string(permission))
+}
+
 func parseToken(c *gin.Context) (bool, string) {
 	header := c.GetHeader("Authorization")
 	splitToken := strings.Split(header, "Bearer ")
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"phrasmotica/bore-score-api/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func runTokenAuth(handler gin.HandlerFunc, key string) int {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", handler, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Authorization", "Bearer "+key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func TestTokenAuthWithAPIKey(t *testing.T) {
+	SetAPIKeyValidator(func(key string) (bool, *APIKeyIdentity) {
+		if key != APIKeyPrefix+"valid" {
+			return false, nil
+		}
+
+		return true, &APIKeyIdentity{
+			Username: "someone",
+			Scopes:   []models.APIKeyScope{models.ReadResultsScope},
+		}
+	})
+	defer SetAPIKeyValidator(nil)
+
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		key      string
+		expected int
+	}{
+		{"in scope", TokenAuth(false, models.ReadResultsScope), APIKeyPrefix + "valid", http.StatusOK},
+		{"out of scope", TokenAuth(false, models.WriteResultsScope), APIKeyPrefix + "valid", http.StatusForbidden},
+		{"no scopes", TokenAuth(false), APIKeyPrefix + "valid", http.StatusForbidden},
+		{"unknown key", TokenAuth(false, models.ReadResultsScope), APIKeyPrefix + "invalid", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		if actual := runTokenAuth(test.handler, test.key); actual != test.expected {
+			t.Errorf("Wrong status for %s! Expected: %d, actual: %d", test.name, test.expected, actual)
+		}
+	}
+}
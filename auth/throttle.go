@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"phrasmotica/bore-score-api/models"
+	"time"
+)
+
+// ThrottlePolicy decides how long someone has to wait between failed logins
+type ThrottlePolicy struct {
+	// failures allowed before any delay
+	FreeAttempts int
+
+	// delay after the first delayed failure, which doubles with each further failure
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// failures after which logins are refused for LockoutDuration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+
+	// how long without a failure before the count starts again
+	ResetAfter time.Duration
+}
+
+var (
+	AccountThrottlePolicy = ThrottlePolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+
+	// more lenient, since many people can share an IP address
+	IPAddressThrottlePolicy = ThrottlePolicy{
+		FreeAttempts:     10,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		LockoutThreshold: 100,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+)
+
+// Delay returns how long to wait after the given number of consecutive failures
+func (p *ThrottlePolicy) Delay(failures int) time.Duration {
+	if failures < p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < failures; i++ {
+		delay *= 2
+
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return delay
+}
+
+// RetryAfter returns how long until another login is allowed, or zero if one is allowed now
+func (p *ThrottlePolicy) RetryAfter(throttle *models.LoginThrottle, now time.Time) time.Duration {
+	if lockedUntil := time.Unix(throttle.TimeLockedUntil, 0); now.Before(lockedUntil) {
+		return lockedUntil.Sub(now)
+	}
+
+	if p.hasExpired(throttle, now) {
+		return 0
+	}
+
+	allowedFrom := time.Unix(throttle.TimeLastFailure, 0).Add(p.Delay(throttle.FailedAttempts))
+	if now.Before(allowedFrom) {
+		return allowedFrom.Sub(now)
+	}
+
+	return 0
+}
+
+// RecordFailure counts a failed login, and returns whether it caused a lockout
+func (p *ThrottlePolicy) RecordFailure(throttle *models.LoginThrottle, now time.Time) bool {
+	if p.hasExpired(throttle, now) {
+		throttle.FailedAttempts = 0
+	}
+
+	throttle.FailedAttempts++
+	throttle.TimeLastFailure = now.Unix()
+
+	if throttle.FailedAttempts >= p.LockoutThreshold {
+		throttle.FailedAttempts = 0
+		throttle.TimeLockedUntil = now.Add(p.LockoutDuration).Unix()
+		return true
+	}
+
+	return false
+}
+
+func (p *ThrottlePolicy) hasExpired(throttle *models.LoginThrottle, now time.Time) bool {
+	return now.Sub(time.Unix(throttle.TimeLastFailure, 0)) >= p.ResetAfter
+}
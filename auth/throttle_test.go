@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"phrasmotica/bore-score-api/models"
+	"testing"
+	"time"
+)
+
+var testPolicy = ThrottlePolicy{
+	FreeAttempts:     3,
+	BaseDelay:        time.Second,
+	MaxDelay:         10 * time.Second,
+	LockoutThreshold: 8,
+	LockoutDuration:  15 * time.Minute,
+	ResetAfter:       time.Hour,
+}
+
+func TestDelay(t *testing.T) {
+	expected := []time.Duration{
+		0, 0, 0,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+
+	for failures, e := range expected {
+		if actual := testPolicy.Delay(failures); actual != e {
+			t.Errorf("Wrong delay after %d failures! Expected: %s, actual: %s", failures, e, actual)
+		}
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	throttle := &models.LoginThrottle{}
+
+	for i := 0; i < 3; i++ {
+		testPolicy.RecordFailure(throttle, now)
+	}
+
+	if retryAfter := testPolicy.RetryAfter(throttle, now); retryAfter != time.Second {
+		t.Errorf("Wrong retry time after 3 failures! Actual: %s", retryAfter)
+	}
+
+	locked := false
+	for i := 0; i < 5; i++ {
+		locked = testPolicy.RecordFailure(throttle, now)
+	}
+
+	if !locked {
+		t.Fatal("Reaching the threshold did not lock the account")
+	}
+
+	if retryAfter := testPolicy.RetryAfter(throttle, now.Add(time.Minute)); retryAfter != 14*time.Minute {
+		t.Errorf("Wrong retry time while locked! Actual: %s", retryAfter)
+	}
+
+	if retryAfter := testPolicy.RetryAfter(throttle, now.Add(15*time.Minute)); retryAfter != 0 {
+		t.Errorf("Lockout did not end! Actual: %s", retryAfter)
+	}
+}
+
+func TestFailuresReset(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	throttle := &models.LoginThrottle{}
+
+	for i := 0; i < 5; i++ {
+		testPolicy.RecordFailure(throttle, now)
+	}
+
+	later := now.Add(2 * time.Hour)
+	if retryAfter := testPolicy.RetryAfter(throttle, later); retryAfter != 0 {
+		t.Errorf("Old failures still caused a delay! Actual: %s", retryAfter)
+	}
+
+	testPolicy.RecordFailure(throttle, later)
+	if throttle.FailedAttempts != 1 {
+		t.Errorf("Old failures were still counted! Actual: %d", throttle.FailedAttempts)
+	}
+}
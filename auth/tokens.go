@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// number of random bytes in an opaque token
+const opaqueTokenLength = 32
+
+// GenerateOpaqueToken returns a random URL-safe token to give to the user, along
+// with the hash of it that should be stored instead of the token itself
+func GenerateOpaqueToken() (token string, hash string, err error) {
+	bytes := make([]byte, opaqueTokenLength)
+	if _, err = rand.Read(bytes); err != nil {
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	hash = HashOpaqueToken(token)
+	return
+}
+
+// HashOpaqueToken returns the hash of a token created by GenerateOpaqueToken. The tokens
+// are long and random enough that a fast hash is fine, which also means they can be
+// looked up by their hash
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,19 @@
+package auth
+
+import "testing"
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	token, hash, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("Could not generate token: %s", err)
+	}
+
+	if HashOpaqueToken(token) != hash {
+		t.Error("Returned hash does not match the token")
+	}
+
+	otherToken, _, _ := GenerateOpaqueToken()
+	if token == otherToken {
+		t.Error("Generated the same token twice")
+	}
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters from RFC 6238. These are what authenticator apps assume by default
+const (
+	totpPeriod       = 30 * time.Second
+	totpDigits       = 6
+	totpSecretLength = 20
+
+	// how many periods either side of the current one are accepted, to allow for clock skew
+	totpSkew = 1
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random base32-encoded secret for an authenticator app
+func GenerateTOTPSecret() (string, error) {
+	bytes := make([]byte, totpSecretLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base32NoPadding.EncodeToString(bytes), nil
+}
+
+// TOTPURI returns the otpauth URI that authenticator apps scan to add the given secret
+func TOTPURI(issuer string, accountName string, secret string) string {
+	label := url.PathEscape(issuer + ":" + accountName)
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TOTPStep returns the number of the TOTP period that the given time is in
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod.Seconds())
+}
+
+// TOTPCode returns the code for the given secret in the given period
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, step, totpDigits), nil
+}
+
+// ValidateTOTP returns the period of the code if it's valid for the given secret at
+// the given time, so that callers can stop the same code being used twice
+func ValidateTOTP(secret string, code string, now time.Time) (bool, int64) {
+	step := TOTPStep(now)
+
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		expected, err := TOTPCode(secret, step+offset)
+		if err != nil {
+			return false, 0
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true, step + offset
+		}
+	}
+
+	return false, 0
+}
+
+// HOTP from RFC 4226
+func hotp(key []byte, counter int64, digits int) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulus *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%modulus)
+}
+
+// GenerateRecoveryCodes returns the given number of random one-time codes, formatted
+// to be easy to type
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+
+	for i := range codes {
+		bytes := make([]byte, 5)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32NoPadding.EncodeToString(bytes))
+		codes[i] = code[:4] + "-" + code[4:]
+	}
+
+	return codes, nil
+}
+
+// NormaliseRecoveryCode removes the formatting from a recovery code, so that the
+// user doesn't have to type it exactly as it was shown
+func NormaliseRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// the SHA1 test vectors from RFC 6238, appendix B
+func TestHOTPVectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		time     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, test := range tests {
+		step := TOTPStep(time.Unix(test.time, 0))
+
+		if actual := hotp(key, step, 8); actual != test.expected {
+			t.Errorf("Wrong code at time %d! Expected: %s, actual: %s", test.time, test.expected, actual)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+
+	code, err := TOTPCode(secret, TOTPStep(now))
+	if err != nil {
+		t.Fatalf("Could not generate code: %s", err)
+	}
+
+	if code != "050471" {
+		t.Errorf("Wrong code! Expected: 050471, actual: %s", code)
+	}
+
+	if valid, step := ValidateTOTP(secret, code, now.Add(totpPeriod)); !valid || step != TOTPStep(now) {
+		t.Error("Code from the previous period was rejected")
+	}
+
+	if valid, _ := ValidateTOTP(secret, code, now.Add(3*totpPeriod)); valid {
+		t.Error("Code from several periods ago was accepted")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("Could not generate recovery codes: %s", err)
+	}
+
+	if len(codes) != 10 {
+		t.Fatalf("Wrong number of recovery codes! Actual: %d", len(codes))
+	}
+
+	if NormaliseRecoveryCode(" "+codes[0]+" ") != NormaliseRecoveryCode(codes[0]) {
+		t.Error("Recovery code was not normalised")
+	}
+}
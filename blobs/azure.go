@@ -0,0 +1,234 @@
+package blobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const azureStorageVersion = "2020-10-02"
+
+// AzureStore keeps blobs in a container in an Azure Blob Storage account, such as the
+// one that Azurite emulates. It talks to the REST API directly, signing each request
+// with the account key
+type AzureStore struct {
+	AccountName string
+	AccountKey  []byte
+	Endpoint    string
+	Container   string
+
+	client *http.Client
+}
+
+// NewAzureStore creates a store from a storage account connection string, creating the
+// container if it doesn't exist yet
+func NewAzureStore(connStr string, container string) (*AzureStore, error) {
+	settings := map[string]string{}
+	for _, part := range strings.Split(connStr, ";") {
+		if key, value, found := strings.Cut(part, "="); found {
+			settings[key] = value
+		}
+	}
+
+	accountKey, err := base64.StdEncoding.DecodeString(settings["AccountKey"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid account key: %w", err)
+	}
+
+	if settings["AccountName"] == "" || settings["BlobEndpoint"] == "" {
+		return nil, errors.New("connection string must contain AccountName and BlobEndpoint")
+	}
+
+	s := &AzureStore{
+		AccountName: settings["AccountName"],
+		AccountKey:  accountKey,
+		Endpoint:    strings.TrimSuffix(settings["BlobEndpoint"], "/"),
+		Container:   container,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+
+	response, err := s.do(context.Background(), http.MethodPut, "", url.Values{"restype": {"container"}}, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusConflict {
+		return nil, fmt.Errorf("could not create container %s: %s", container, response.Status)
+	}
+
+	return s, nil
+}
+
+func (s *AzureStore) Put(ctx context.Context, name string, contentType string, data []byte) error {
+	response, err := s.do(ctx, http.MethodPut, name, nil, data, contentType)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("could not put blob %s: %s", name, response.Status)
+	}
+
+	return nil
+}
+
+func (s *AzureStore) Get(ctx context.Context, name string) ([]byte, string, error) {
+	response, err := s.do(ctx, http.MethodGet, name, nil, nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, "", ErrNotFound
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("could not get blob %s: %s", name, response.Status)
+	}
+
+	return response.Body, response.Header.Get("Content-Type"), nil
+}
+
+func (s *AzureStore) Delete(ctx context.Context, name string) error {
+	response, err := s.do(ctx, http.MethodDelete, name, nil, nil, "")
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	if response.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("could not delete blob %s: %s", name, response.Status)
+	}
+
+	return nil
+}
+
+type azureResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// sends a request about the container, or a blob in it if name isn't empty
+func (s *AzureStore) do(ctx context.Context, method string, name string, query url.Values, body []byte, contentType string) (*azureResponse, error) {
+	path := "/" + s.Container
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+
+	requestUrl := s.Endpoint + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, requestUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	request.Header.Set("x-ms-version", azureStorageVersion)
+
+	if method == http.MethodPut && name != "" {
+		request.Header.Set("x-ms-blob-type", "BlockBlob")
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	request.Header.Set("Authorization", "SharedKey "+s.AccountName+":"+s.sign(request, len(body)))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureResponse{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       responseBody,
+	}, nil
+}
+
+// computes the Shared Key signature of the request, see
+// https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (s *AzureStore) sign(request *http.Request, contentLength int) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	msHeaders := []string{}
+	for key, values := range request.Header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-ms-") {
+			msHeaders = append(msHeaders, key+":"+strings.Join(values, ","))
+		}
+	}
+
+	sort.Strings(msHeaders)
+
+	stringToSign := strings.Join([]string{
+		request.Method,
+		request.Header.Get("Content-Encoding"),
+		request.Header.Get("Content-Language"),
+		length,
+		request.Header.Get("Content-MD5"),
+		request.Header.Get("Content-Type"),
+		"", // Date, since x-ms-date is used instead
+		request.Header.Get("If-Modified-Since"),
+		request.Header.Get("If-Match"),
+		request.Header.Get("If-None-Match"),
+		request.Header.Get("If-Unmodified-Since"),
+		request.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		canonicalizedResource(s.AccountName, request.URL),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, s.AccountKey)
+	mac.Write([]byte(stringToSign))
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func canonicalizedResource(accountName string, u *url.URL) string {
+	resource := "/" + accountName + u.EscapedPath()
+
+	query := u.Query()
+
+	keys := []string{}
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(key) + ":" + strings.Join(values, ",")
+	}
+
+	return resource
+}
@@ -0,0 +1,37 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+var ErrNotFound = errors.New("blob does not exist")
+
+// Store keeps uploaded files, such as images, under a name
+type Store interface {
+	Put(ctx context.Context, name string, contentType string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, string, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// NewStoreFromEnv creates the blob store chosen by the BLOB_STORE environment variable.
+// This is the local filesystem unless it's set to "azure", in which case blobs are
+// kept in the Azure Blob Storage account given by AZURE_BLOB_CONNECTION_STRING
+func NewStoreFromEnv() (Store, error) {
+	if os.Getenv("BLOB_STORE") == "azure" {
+		container := os.Getenv("AZURE_BLOB_CONTAINER")
+		if container == "" {
+			container = "uploads"
+		}
+
+		return NewAzureStore(os.Getenv("AZURE_BLOB_CONNECTION_STRING"), container)
+	}
+
+	directory := os.Getenv("BLOB_DIRECTORY")
+	if directory == "" {
+		directory = "uploads"
+	}
+
+	return NewLocalStore(directory)
+}
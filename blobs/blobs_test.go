@@ -0,0 +1,61 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Could not create store: %s", err)
+	}
+
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "picture.png", "image/png", []byte("data")); err != nil {
+		t.Fatalf("Could not put blob: %s", err)
+	}
+
+	data, contentType, err := store.Get(ctx, "picture.png")
+	if err != nil {
+		t.Fatalf("Could not get blob: %s", err)
+	}
+
+	if string(data) != "data" || contentType != "image/png" {
+		t.Errorf("Blob was incorrect! Actual: %s %s", data, contentType)
+	}
+
+	if err := store.Delete(ctx, "picture.png"); err != nil {
+		t.Fatalf("Could not delete blob: %s", err)
+	}
+
+	if _, _, err := store.Get(ctx, "picture.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Deleted blob could still be got! Error: %v", err)
+	}
+}
+
+func TestLocalStoreRejectsPaths(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Could not create store: %s", err)
+	}
+
+	for _, name := range []string{"../secret", "a/b", "..", ""} {
+		if err := store.Put(context.Background(), name, "text/plain", []byte("data")); err == nil {
+			t.Errorf("Blob named %q was put outside the store", name)
+		}
+	}
+}
+
+func TestCanonicalizedResource(t *testing.T) {
+	u, _ := url.Parse("http://azurite:10000/devstoreaccount1/uploads?restype=container&comp=list")
+
+	expected := "/devstoreaccount1/devstoreaccount1/uploads\ncomp:list\nrestype:container"
+
+	if actual := canonicalizedResource("devstoreaccount1", u); actual != expected {
+		t.Errorf("Canonicalized resource was incorrect! Actual: %q", actual)
+	}
+}
@@ -0,0 +1,82 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files in a directory. The content type of a blob comes from
+// the extension in its name
+type LocalStore struct {
+	Directory string
+}
+
+func NewLocalStore(directory string) (*LocalStore, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		Directory: directory,
+	}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, name string, contentType string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *LocalStore) Get(ctx context.Context, name string) ([]byte, string, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, "", ErrNotFound
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return data, contentType, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// returns the path of the blob's file, making sure that it's inside the directory
+func (s *LocalStore) path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", ErrNotFound
+	}
+
+	return filepath.Join(s.Directory, name), nil
+}
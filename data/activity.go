@@ -0,0 +1,69 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) AddActivity(ctx context.Context, newActivity *models.Activity) bool {
+	_, err := d.Database.Collection("Activity").InsertOne(ctx, newActivity)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) GetActivityForGroup(ctx context.Context, groupId string) (bool, []models.Activity) {
+	cursor, err := d.Database.Collection("Activity").Find(ctx, bson.D{{"groupId", groupId}})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var activity []models.Activity
+
+	err = cursor.All(ctx, &activity)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, activity
+}
+
+func (d *MongoDatabase) ScrubActivityWithUser(ctx context.Context, username string) (bool, int64) {
+	collection := d.Database.Collection("Activity")
+
+	result, err := collection.UpdateMany(ctx, bson.D{{"username", username}}, bson.D{{"$set", bson.D{{"username", ""}}}})
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	scrubCount := result.ModifiedCount
+
+	result, err = collection.UpdateMany(ctx, bson.D{{"targetUsername", username}}, bson.D{{"$set", bson.D{{"targetUsername", ""}}}})
+	if err != nil {
+		Error.Println(err)
+		return false, scrubCount
+	}
+
+	return true, scrubCount + result.ModifiedCount
+}
+
+func (d *MongoDatabase) DeleteActivityForGroup(ctx context.Context, groupId string) (bool, int64) {
+	filter := bson.D{{"groupId", groupId}}
+	deleteResult, err := d.Database.Collection("Activity").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
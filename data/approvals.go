@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetApprovalsByUser(ctx context.Context, username string) (bool, []models.Approval) {
+	filter := bson.D{{"username", username}}
+
+	cursor, err := d.Database.Collection("Approvals").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var approvals []models.Approval
+
+	err = cursor.All(ctx, &approvals)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, approvals
+}
+
+func (d *MongoDatabase) UpdateApproval(ctx context.Context, approval *models.Approval) bool {
+	filter := bson.D{{"id", approval.ID}}
+	_, err := d.Database.Collection("Approvals").ReplaceOne(ctx, filter, approval)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteApprovals(ctx context.Context, resultId string) (bool, int64) {
+	filter := bson.D{{"resultId", resultId}}
+	deleteResult, err := d.Database.Collection("Approvals").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetAttachments(ctx context.Context, resultId string) (bool, []models.Attachment) {
+	filter := bson.D{{"resultId", resultId}}
+
+	cursor, err := d.Database.Collection("Attachments").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var attachments []models.Attachment
+
+	err = cursor.All(ctx, &attachments)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, attachments
+}
+
+func (d *MongoDatabase) GetAttachment(ctx context.Context, resultId string, attachmentId string) (bool, *models.Attachment) {
+	filter := bson.D{{"resultId", resultId}, {"id", attachmentId}}
+	result := d.Database.Collection("Attachments").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var attachment models.Attachment
+
+	if err := result.Decode(&attachment); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &attachment
+}
+
+func (d *MongoDatabase) AddAttachment(ctx context.Context, newAttachment *models.Attachment) bool {
+	newAttachment.ID = uuid.NewString()
+	newAttachment.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Attachments").InsertOne(ctx, newAttachment)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteAttachment(ctx context.Context, resultId string, attachmentId string) bool {
+	filter := bson.D{{"resultId", resultId}, {"id", attachmentId}}
+	_, err := d.Database.Collection("Attachments").DeleteOne(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
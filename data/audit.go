@@ -0,0 +1,42 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetAuditEntries(ctx context.Context) (bool, []models.AuditEntry) {
+	cursor, err := d.Database.Collection("AuditLog").Find(ctx, bson.D{})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var entries []models.AuditEntry
+
+	err = cursor.All(ctx, &entries)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, entries
+}
+
+func (d *MongoDatabase) AddAuditEntry(ctx context.Context, newEntry *models.AuditEntry) bool {
+	newEntry.ID = uuid.NewString()
+	newEntry.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("AuditLog").InsertOne(ctx, newEntry)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
@@ -39,6 +40,104 @@ func CreateTableStorageClient(connStr string) *aztables.ServiceClient {
 	return client
 }
 
+// AddActivity implements IDatabase
+func (d *TableStorageDatabase) AddActivity(ctx context.Context, newActivity *models.Activity) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newActivity.GroupID,
+			RowKey:       newActivity.ID,
+		},
+		Properties: map[string]interface{}{
+			"GroupID":        newActivity.GroupID,
+			"TimeCreated":    aztables.EDMInt64(newActivity.TimeCreated),
+			"Type":           string(newActivity.Type),
+			"Username":       newActivity.Username,
+			"TargetUsername": newActivity.TargetUsername,
+			"ResultID":       newActivity.ResultID,
+			"GameID":         newActivity.GameID,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Activity").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// GetActivityForGroup implements IDatabase
+func (d *TableStorageDatabase) GetActivityForGroup(ctx context.Context, groupId string) (bool, []models.Activity) {
+	activity := list(ctx, d.Client, "Activity", createActivity, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+
+	return true, activity
+}
+
+// ScrubActivityWithUser implements IDatabase
+func (d *TableStorageDatabase) ScrubActivityWithUser(ctx context.Context, username string) (bool, int64) {
+	client := d.Client.NewClient("Activity")
+
+	entities := listEntities(ctx, client, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s' or TargetUsername eq '%s'", username, username)),
+	})
+
+	scrubCount := 0
+	for i := 0; i < len(entities); i++ {
+		activity := createActivity(&entities[i])
+
+		if activity.Username == username {
+			activity.Username = ""
+		}
+
+		if activity.TargetUsername == username {
+			activity.TargetUsername = ""
+		}
+
+		entity := aztables.EDMEntity{
+			Entity: aztables.Entity{
+				PartitionKey: entities[i].PartitionKey,
+				RowKey:       entities[i].RowKey,
+			},
+			Properties: map[string]interface{}{
+				"Username":       activity.Username,
+				"TargetUsername": activity.TargetUsername,
+			},
+		}
+
+		marshalled, err := json.Marshal(entity)
+		if err != nil {
+			Error.Println(err)
+			return false, int64(scrubCount)
+		}
+
+		_, updateErr := client.UpdateEntity(ctx, marshalled, nil)
+		if updateErr != nil {
+			Error.Println(updateErr)
+			return false, int64(scrubCount)
+		}
+
+		scrubCount++
+	}
+
+	return true, int64(scrubCount)
+}
+
+// DeleteActivityForGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteActivityForGroup(ctx context.Context, groupId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Activity"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+}
+
 // AddApproval implements IDatabase
 func (d *TableStorageDatabase) AddApproval(ctx context.Context, newApproval *models.Approval) bool {
 	entity := aztables.EDMEntity{
@@ -77,6 +176,287 @@ func (d *TableStorageDatabase) GetApprovals(ctx context.Context, resultId string
 	return true, approvals
 }
 
+// GetApprovalsByUser implements IDatabase
+func (d *TableStorageDatabase) GetApprovalsByUser(ctx context.Context, username string) (bool, []models.Approval) {
+	approvals := list(ctx, d.Client, "Approvals", createApproval, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s'", username)),
+	})
+	return true, approvals
+}
+
+// UpdateApproval implements IDatabase
+func (d *TableStorageDatabase) UpdateApproval(ctx context.Context, approval *models.Approval) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: approval.ResultID,
+			RowKey:       approval.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":       approval.Username,
+			"ApprovalStatus": string(approval.ApprovalStatus),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Approvals").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteApprovals implements IDatabase
+func (d *TableStorageDatabase) DeleteApprovals(ctx context.Context, resultId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Approvals"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+}
+
+// GetAttachments implements IDatabase
+func (d *TableStorageDatabase) GetAttachments(ctx context.Context, resultId string) (bool, []models.Attachment) {
+	attachments := list(ctx, d.Client, "Attachments", createAttachment, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+	return true, attachments
+}
+
+// GetAttachment implements IDatabase
+func (d *TableStorageDatabase) GetAttachment(ctx context.Context, resultId string, attachmentId string) (bool, *models.Attachment) {
+	entities := listEntities(ctx, d.Client.NewClient("Attachments"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", resultId, attachmentId)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	attachment := createAttachment(&entities[0])
+	return true, &attachment
+}
+
+// AddAttachment implements IDatabase
+func (d *TableStorageDatabase) AddAttachment(ctx context.Context, newAttachment *models.Attachment) bool {
+	newAttachment.ID = uuid.NewString()
+	newAttachment.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newAttachment.ResultID,
+			RowKey:       newAttachment.ID,
+		},
+		Properties: map[string]interface{}{
+			"ResultID":    newAttachment.ResultID,
+			"TimeCreated": aztables.EDMInt64(newAttachment.TimeCreated),
+			"UploadedBy":  newAttachment.UploadedBy,
+			"BlobName":    newAttachment.BlobName,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Attachments").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteAttachment implements IDatabase
+func (d *TableStorageDatabase) DeleteAttachment(ctx context.Context, resultId string, attachmentId string) bool {
+	_, err := d.Client.NewClient("Attachments").DeleteEntity(ctx, resultId, attachmentId, nil)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+// GetComments implements IDatabase
+func (d *TableStorageDatabase) GetComments(ctx context.Context, resultId string) (bool, []models.Comment) {
+	comments := list(ctx, d.Client, "Comments", createComment, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+	return true, comments
+}
+
+// GetCommentsByUser implements IDatabase
+func (d *TableStorageDatabase) GetCommentsByUser(ctx context.Context, username string) (bool, []models.Comment) {
+	comments := list(ctx, d.Client, "Comments", createComment, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s'", username)),
+	})
+	return true, comments
+}
+
+// GetComment implements IDatabase
+func (d *TableStorageDatabase) GetComment(ctx context.Context, resultId string, commentId string) (bool, *models.Comment) {
+	entities := listEntities(ctx, d.Client.NewClient("Comments"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", resultId, commentId)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	comment := createComment(&entities[0])
+	return true, &comment
+}
+
+// AddComment implements IDatabase
+func (d *TableStorageDatabase) AddComment(ctx context.Context, newComment *models.Comment) bool {
+	newComment.ID = uuid.NewString()
+	newComment.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newComment.ResultID,
+			RowKey:       newComment.ID,
+		},
+		Properties: map[string]interface{}{
+			"ResultID":    newComment.ResultID,
+			"ParentID":    newComment.ParentID,
+			"TimeCreated": aztables.EDMInt64(newComment.TimeCreated),
+			"Username":    newComment.Username,
+			"Text":        newComment.Text,
+			"TimeEdited":  aztables.EDMInt64(newComment.TimeEdited),
+			"Deleted":     newComment.Deleted,
+			"DeletedBy":   newComment.DeletedBy,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Comments").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateComment implements IDatabase
+func (d *TableStorageDatabase) UpdateComment(ctx context.Context, comment *models.Comment) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: comment.ResultID,
+			RowKey:       comment.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":   comment.Username,
+			"Text":       comment.Text,
+			"TimeEdited": aztables.EDMInt64(comment.TimeEdited),
+			"Deleted":    comment.Deleted,
+			"DeletedBy":  comment.DeletedBy,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Comments").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteComments implements IDatabase
+func (d *TableStorageDatabase) DeleteComments(ctx context.Context, resultId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Comments"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+}
+
+// GetReactions implements IDatabase
+func (d *TableStorageDatabase) GetReactions(ctx context.Context, resultId string) (bool, []models.Reaction) {
+	reactions := list(ctx, d.Client, "Reactions", createReaction, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+	return true, reactions
+}
+
+// GetReactionsByUser implements IDatabase
+func (d *TableStorageDatabase) GetReactionsByUser(ctx context.Context, username string) (bool, []models.Reaction) {
+	reactions := list(ctx, d.Client, "Reactions", createReaction, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s'", username)),
+	})
+	return true, reactions
+}
+
+// AddReaction implements IDatabase
+func (d *TableStorageDatabase) AddReaction(ctx context.Context, newReaction *models.Reaction) bool {
+	newReaction.ID = uuid.NewString()
+	newReaction.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newReaction.ResultID,
+			RowKey:       newReaction.ID,
+		},
+		Properties: map[string]interface{}{
+			"ResultID":    newReaction.ResultID,
+			"TimeCreated": aztables.EDMInt64(newReaction.TimeCreated),
+			"Username":    newReaction.Username,
+			"Emoji":       newReaction.Emoji,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Reactions").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteReaction implements IDatabase
+func (d *TableStorageDatabase) DeleteReaction(ctx context.Context, resultId string, reactionId string) bool {
+	_, err := d.Client.NewClient("Reactions").DeleteEntity(ctx, resultId, reactionId, nil)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+// DeleteReactions implements IDatabase
+func (d *TableStorageDatabase) DeleteReactions(ctx context.Context, resultId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Reactions"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", resultId)),
+	})
+}
+
 // GetAllGames implements IDatabase
 func (d *TableStorageDatabase) GetAllGames(ctx context.Context) (bool, []models.Game) {
 	games := list(ctx, d.Client, "Games", createGame, nil)
@@ -124,6 +504,8 @@ func (d *TableStorageDatabase) AddGame(ctx context.Context, newGame *models.Game
 			"WinMethod":   newGame.WinMethod,
 			"ImageLink":   newGame.ImageLink,
 			"Links":       string(links),
+
+			"UploadedImage": newGame.UploadedImage,
 		},
 	}
 
@@ -142,6 +524,48 @@ func (d *TableStorageDatabase) AddGame(ctx context.Context, newGame *models.Game
 	return true
 }
 
+// UpdateGame implements IDatabase
+func (d *TableStorageDatabase) UpdateGame(ctx context.Context, game *models.Game) bool {
+	links, linksErr := json.Marshal(game.Links)
+	if linksErr != nil {
+		Error.Println(linksErr)
+		return false
+	}
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "Games",
+			RowKey:       game.ID,
+		},
+		Properties: map[string]interface{}{
+			"DisplayName": game.DisplayName,
+			"Synopsis":    game.Synopsis,
+			"Description": game.Description,
+			"MinPlayers":  game.MinPlayers,
+			"MaxPlayers":  game.MaxPlayers,
+			"WinMethod":   game.WinMethod,
+			"ImageLink":   game.ImageLink,
+			"Links":       string(links),
+
+			"UploadedImage": game.UploadedImage,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Games").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
 // DeleteGame implements IDatabase
 func (d *TableStorageDatabase) DeleteGame(ctx context.Context, id string) bool {
 	game := d.findGame(ctx, id)
@@ -215,6 +639,10 @@ func (d *TableStorageDatabase) AddGroup(ctx context.Context, newGroup *models.Gr
 			"ProfilePicture": newGroup.ProfilePicture,
 			"Visibility":     string(newGroup.Visibility),
 			"CreatedBy":      newGroup.CreatedBy,
+			"Archived":       newGroup.Archived,
+			"TimeArchived":   aztables.EDMInt64(newGroup.TimeArchived),
+
+			"UploadedPicture": newGroup.UploadedPicture,
 		},
 	}
 
@@ -233,8 +661,42 @@ func (d *TableStorageDatabase) AddGroup(ctx context.Context, newGroup *models.Gr
 	return true
 }
 
-// DeleteGroup implements IDatabase
-func (d *TableStorageDatabase) DeleteGroup(ctx context.Context, id string) bool {
+// UpdateGroup implements IDatabase
+func (d *TableStorageDatabase) UpdateGroup(ctx context.Context, group *models.Group) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: group.CreatedBy,
+			RowKey:       group.ID,
+		},
+		Properties: map[string]interface{}{
+			"DisplayName":    group.DisplayName,
+			"Description":    group.Description,
+			"ProfilePicture": group.ProfilePicture,
+			"Visibility":     string(group.Visibility),
+			"Archived":       group.Archived,
+			"TimeArchived":   aztables.EDMInt64(group.TimeArchived),
+
+			"UploadedPicture": group.UploadedPicture,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Groups").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteGroup(ctx context.Context, id string) bool {
 	group := d.findGroup(ctx, id)
 	if group == nil {
 		return false
@@ -287,6 +749,15 @@ func (d *TableStorageDatabase) GetGroupInvitationsForGroup(ctx context.Context,
 	return true, invitations
 }
 
+// GetGroupInvitationsInvolving implements IDatabase
+func (d *TableStorageDatabase) GetGroupInvitationsInvolving(ctx context.Context, username string) (bool, []models.GroupInvitation) {
+	invitations := list(ctx, d.Client, "GroupInvitations", createGroupInvitation, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s' or InviterUsername eq '%s'", username, username)),
+	})
+
+	return true, invitations
+}
+
 // IsInvitedToGroup implements IDatabase
 func (d *TableStorageDatabase) IsInvitedToGroup(ctx context.Context, groupId string, username string) bool {
 	success, invitations := d.GetGroupInvitations(ctx, username)
@@ -362,6 +833,22 @@ func (d *TableStorageDatabase) UpdateGroupInvitation(ctx context.Context, newGro
 	return true
 }
 
+// DeleteGroupInvitation implements IDatabase
+func (d *TableStorageDatabase) DeleteGroupInvitation(ctx context.Context, invitationId string) bool {
+	success, deleteCount := deleteEntities(ctx, d.Client.NewClient("GroupInvitations"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", invitationId)),
+	})
+
+	return success && deleteCount > 0
+}
+
+// DeleteGroupInvitationsForGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("GroupInvitations"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+}
+
 // GetGroupMemberships implements IDatabase
 func (d *TableStorageDatabase) GetGroupMemberships(ctx context.Context, username string) (bool, []models.GroupMembership) {
 	if !d.UserExists(ctx, username) {
@@ -434,74 +921,94 @@ func (d *TableStorageDatabase) AddGroupMembership(ctx context.Context, newGroupM
 	return true
 }
 
-func (d *TableStorageDatabase) GetAllLinkTypes(ctx context.Context) (bool, []models.LinkType) {
-	linkTypes := list(ctx, d.Client, "LinkTypes", createLinkType, nil)
-	return true, linkTypes
-}
-
-// GetAllPlayers implements IDatabase
-func (d *TableStorageDatabase) GetAllPlayers(ctx context.Context) (bool, []models.Player) {
-	players := list(ctx, d.Client, "Players", createPlayer, nil)
-	return true, players
-}
+// UpdateGroupMembership implements IDatabase
+func (d *TableStorageDatabase) UpdateGroupMembership(ctx context.Context, membership *models.GroupMembership) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: membership.GroupID,
+			RowKey:       membership.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username": membership.Username,
+		},
+	}
 
-// GetPlayersInGroup implements IDatabase
-func (d *TableStorageDatabase) GetPlayersInGroup(ctx context.Context, groupId string) (bool, []models.Player) {
-	success, players := d.GetAllPlayers(ctx)
-	if !success {
-		return false, []models.Player{}
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
 	}
 
-	success, memberships := d.GetGroupMembershipsForGroup(ctx, groupId)
-	if !success {
-		return false, []models.Player{}
+	_, updateErr := d.Client.NewClient("GroupMemberships").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
 	}
 
-	playersInGroup := []models.Player{}
+	return true
+}
 
-	for _, m := range memberships {
-		// returns the index of the player with this membership
-		playerIndex := slices.IndexFunc(players, func(p models.Player) bool {
-			return p.Username == m.Username
-		})
+// DeleteGroupMembership implements IDatabase
+func (d *TableStorageDatabase) DeleteGroupMembership(ctx context.Context, groupId string, username string) bool {
+	success, deleteCount := deleteEntities(ctx, d.Client.NewClient("GroupMemberships"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and Username eq '%s'", groupId, username)),
+	})
 
-		if playerIndex >= 0 {
-			playersInGroup = append(playersInGroup, players[playerIndex])
-		}
-	}
+	return success && deleteCount > 0
+}
 
-	return true, playersInGroup
+// DeleteGroupMembershipsForGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("GroupMemberships"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
 }
 
-// GetPlayer implements IDatabase
-func (d *TableStorageDatabase) GetPlayer(ctx context.Context, username string) (bool, *models.Player) {
-	result := d.findPlayer(ctx, username)
-	if result == nil {
+func (d *TableStorageDatabase) GetAllLinkTypes(ctx context.Context) (bool, []models.LinkType) {
+	linkTypes := list(ctx, d.Client, "LinkTypes", createLinkType, nil)
+	return true, linkTypes
+}
+
+// GetNotifications implements IDatabase
+func (d *TableStorageDatabase) GetNotifications(ctx context.Context, username string) (bool, []models.Notification) {
+	notifications := list(ctx, d.Client, "Notifications", createNotification, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+
+	return true, notifications
+}
+
+// GetNotification implements IDatabase
+func (d *TableStorageDatabase) GetNotification(ctx context.Context, notificationId string) (bool, *models.Notification) {
+	entity := d.findNotification(ctx, notificationId)
+	if entity == nil {
 		return false, nil
 	}
 
-	player := createPlayer(result)
-	return true, &player
+	notification := createNotification(entity)
+	return true, &notification
 }
 
-// PlayerExists implements IDatabase
-func (d *TableStorageDatabase) PlayerExists(ctx context.Context, username string) bool {
-	result := d.findPlayer(ctx, username)
-	return result != nil
-}
+// AddNotification implements IDatabase
+func (d *TableStorageDatabase) AddNotification(ctx context.Context, newNotification *models.Notification) bool {
+	newNotification.ID = uuid.NewString()
+	newNotification.TimeCreated = time.Now().UTC().Unix()
 
-// AddPlayer implements IDatabase
-func (d *TableStorageDatabase) AddPlayer(ctx context.Context, newPlayer *models.Player) bool {
 	entity := aztables.EDMEntity{
 		Entity: aztables.Entity{
-			PartitionKey: "Players",
-			RowKey:       newPlayer.ID,
+			PartitionKey: newNotification.Username,
+			RowKey:       newNotification.ID,
 		},
 		Properties: map[string]interface{}{
-			"Username":       newPlayer.Username,
-			"TimeCreated":    aztables.EDMInt64(newPlayer.TimeCreated),
-			"DisplayName":    newPlayer.DisplayName,
-			"ProfilePicture": newPlayer.ProfilePicture,
+			"Username":     newNotification.Username,
+			"TimeCreated":  aztables.EDMInt64(newNotification.TimeCreated),
+			"Type":         string(newNotification.Type),
+			"FromUsername": newNotification.FromUsername,
+			"GroupID":      newNotification.GroupID,
+			"ResultID":     newNotification.ResultID,
+			"InvitationID": newNotification.InvitationID,
+			"Read":         newNotification.Read,
+			"TimeRead":     aztables.EDMInt64(newNotification.TimeRead),
 		},
 	}
 
@@ -511,7 +1018,7 @@ func (d *TableStorageDatabase) AddPlayer(ctx context.Context, newPlayer *models.
 		return false
 	}
 
-	_, addErr := d.Client.NewClient("Players").AddEntity(ctx, marshalled, nil)
+	_, addErr := d.Client.NewClient("Notifications").AddEntity(ctx, marshalled, nil)
 	if addErr != nil {
 		Error.Println(addErr)
 		return false
@@ -520,16 +1027,16 @@ func (d *TableStorageDatabase) AddPlayer(ctx context.Context, newPlayer *models.
 	return true
 }
 
-// UpdatePlayer implements IDatabase
-func (d *TableStorageDatabase) UpdatePlayer(ctx context.Context, player *models.Player) bool {
+// UpdateNotification implements IDatabase
+func (d *TableStorageDatabase) UpdateNotification(ctx context.Context, notification *models.Notification) bool {
 	entity := aztables.EDMEntity{
 		Entity: aztables.Entity{
-			PartitionKey: "Players",
-			RowKey:       player.ID,
+			PartitionKey: notification.Username,
+			RowKey:       notification.ID,
 		},
 		Properties: map[string]interface{}{
-			"DisplayName":    player.DisplayName,
-			"ProfilePicture": player.ProfilePicture,
+			"Read":     notification.Read,
+			"TimeRead": aztables.EDMInt64(notification.TimeRead),
 		},
 	}
 
@@ -539,7 +1046,63 @@ func (d *TableStorageDatabase) UpdatePlayer(ctx context.Context, player *models.
 		return false
 	}
 
-	_, addErr := d.Client.NewClient("Players").UpdateEntity(ctx, marshalled, nil)
+	_, updateErr := d.Client.NewClient("Notifications").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteNotifications implements IDatabase
+func (d *TableStorageDatabase) DeleteNotifications(ctx context.Context, username string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Notifications"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+}
+
+// GetPendingOutboxMessages implements IDatabase
+func (d *TableStorageDatabase) GetPendingOutboxMessages(ctx context.Context) (bool, []models.OutboxMessage) {
+	messages := list(ctx, d.Client, "Outbox", createOutboxMessage, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Status eq '%s'", models.DeliveryPending)),
+	})
+
+	return true, messages
+}
+
+// AddOutboxMessage implements IDatabase
+func (d *TableStorageDatabase) AddOutboxMessage(ctx context.Context, newMessage *models.OutboxMessage) bool {
+	newMessage.ID = uuid.NewString()
+	newMessage.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "Outbox",
+			RowKey:       newMessage.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeCreated":     aztables.EDMInt64(newMessage.TimeCreated),
+			"Type":            newMessage.Type,
+			"To":              newMessage.To,
+			"Subject":         newMessage.Subject,
+			"TextBody":        newMessage.TextBody,
+			"HTMLBody":        newMessage.HTMLBody,
+			"Status":          string(newMessage.Status),
+			"Attempts":        newMessage.Attempts,
+			"TimeNextAttempt": aztables.EDMInt64(newMessage.TimeNextAttempt),
+			"TimeSent":        aztables.EDMInt64(newMessage.TimeSent),
+			"LastError":       newMessage.LastError,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Outbox").AddEntity(ctx, marshalled, nil)
 	if addErr != nil {
 		Error.Println(addErr)
 		return false
@@ -548,22 +1111,145 @@ func (d *TableStorageDatabase) UpdatePlayer(ctx context.Context, player *models.
 	return true
 }
 
-// DeletePlayer implements IDatabase
-func (d *TableStorageDatabase) DeletePlayer(ctx context.Context, username string) bool {
-	player := d.findPlayer(ctx, username)
-	if player == nil {
-		return false
+// UpdateOutboxMessage implements IDatabase
+func (d *TableStorageDatabase) UpdateOutboxMessage(ctx context.Context, message *models.OutboxMessage) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "Outbox",
+			RowKey:       message.ID,
+		},
+		Properties: map[string]interface{}{
+			"Status":          string(message.Status),
+			"Attempts":        message.Attempts,
+			"TimeNextAttempt": aztables.EDMInt64(message.TimeNextAttempt),
+			"TimeSent":        aztables.EDMInt64(message.TimeSent),
+			"LastError":       message.LastError,
+		},
 	}
 
-	_, err := d.Client.NewClient("Players").DeleteEntity(ctx, player.PartitionKey, player.RowKey, nil)
+	marshalled, err := json.Marshal(entity)
 	if err != nil {
 		Error.Println(err)
 		return false
 	}
 
+	_, updateErr := d.Client.NewClient("Outbox").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
 	return true
 }
 
+// GetAllPlayers implements IDatabase
+func (d *TableStorageDatabase) GetAllPlayers(ctx context.Context) (bool, []models.Player) {
+	success, users := d.GetAllUsers(ctx)
+	if !success {
+		return false, []models.Player{}
+	}
+
+	players := []models.Player{}
+	for _, u := range users {
+		players = append(players, u.ToPlayer())
+	}
+
+	return true, players
+}
+
+// GetPlayersInGroup implements IDatabase
+func (d *TableStorageDatabase) GetPlayersInGroup(ctx context.Context, groupId string) (bool, []models.Player) {
+	success, players := d.GetAllPlayers(ctx)
+	if !success {
+		return false, []models.Player{}
+	}
+
+	success, memberships := d.GetGroupMembershipsForGroup(ctx, groupId)
+	if !success {
+		return false, []models.Player{}
+	}
+
+	playersInGroup := []models.Player{}
+
+	for _, m := range memberships {
+		// returns the index of the player with this membership
+		playerIndex := slices.IndexFunc(players, func(p models.Player) bool {
+			return p.Username == m.Username
+		})
+
+		if playerIndex >= 0 {
+			playersInGroup = append(playersInGroup, players[playerIndex])
+		}
+	}
+
+	return true, playersInGroup
+}
+
+// GetPlayer implements IDatabase
+func (d *TableStorageDatabase) GetPlayer(ctx context.Context, username string) (bool, *models.Player) {
+	success, user := d.GetUser(ctx, username)
+	if !success {
+		return false, nil
+	}
+
+	player := user.ToPlayer()
+	return true, &player
+}
+
+// PlayerExists implements IDatabase
+func (d *TableStorageDatabase) PlayerExists(ctx context.Context, username string) bool {
+	return d.UserExists(ctx, username)
+}
+
+// MigratePlayers implements IDatabase. It moves each row of the old Players table into
+// the user with the same username, creating a user without an email address or password
+// for players that never had an account, and then deletes the row. Rows that fail are
+// left for the next run
+func (d *TableStorageDatabase) MigratePlayers(ctx context.Context) (bool, int64) {
+	client := d.Client.NewClient("Players")
+
+	var migratedCount int64
+
+	for _, entity := range listEntities(ctx, client, nil) {
+		player := createPlayer(&entity)
+
+		if success, user := d.GetUser(ctx, player.Username); success {
+			// the player's profile wins, since that's the one the user could edit
+			user.DisplayName = player.DisplayName
+			user.ProfilePicture = player.ProfilePicture
+
+			if success := d.UpdateUser(ctx, user); !success {
+				return false, migratedCount
+			}
+		} else {
+			newUser := models.User{
+				ID:             player.ID,
+				Username:       player.Username,
+				TimeCreated:    player.TimeCreated,
+				Permissions:    []string{},
+				DisplayName:    player.DisplayName,
+				ProfilePicture: player.ProfilePicture,
+
+				EmailPreferences: models.DefaultEmailPreferences(),
+				Privacy:          models.DefaultPrivacySettings(),
+			}
+
+			if success := d.AddUser(ctx, &newUser); !success {
+				return false, migratedCount
+			}
+		}
+
+		if _, err := client.DeleteEntity(ctx, entity.PartitionKey, entity.RowKey, nil); err != nil {
+			Error.Println(err)
+			return false, migratedCount
+		}
+
+		migratedCount++
+	}
+
+	return true, migratedCount
+}
+
 // GetAllResults implements IDatabase
 func (d *TableStorageDatabase) GetAllResults(ctx context.Context) (bool, []models.Result) {
 	results := list(ctx, d.Client, "Results", createResult, nil)
@@ -578,6 +1264,14 @@ func (d *TableStorageDatabase) GetResultsForGroup(ctx context.Context, groupId s
 	return true, results
 }
 
+// GetResultsWithGame implements IDatabase
+func (d *TableStorageDatabase) GetResultsWithGame(ctx context.Context, gameId string) (bool, []models.Result) {
+	results := list(ctx, d.Client, "Results", createResult, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("GameID eq '%s'", gameId)),
+	})
+	return true, results
+}
+
 // GetResultsForGroupAndGame implements IDatabase
 func (d *TableStorageDatabase) GetResultsForGroupAndGame(ctx context.Context, groupId string, gameId string) (bool, []models.Result) {
 	results := list(ctx, d.Client, "Results", createResult, &aztables.ListEntitiesOptions{
@@ -670,42 +1364,99 @@ func (d *TableStorageDatabase) AddResult(ctx context.Context, newResult *models.
 	return true
 }
 
-// DeleteResultsWithGame implements IDatabase
-func (d *TableStorageDatabase) DeleteResultsWithGame(ctx context.Context, gameId string) (bool, int64) {
-	game := d.findGame(ctx, gameId)
-	if game == nil {
-		return false, 0
+// UpdateResultScores implements IDatabase
+func (d *TableStorageDatabase) UpdateResultScores(ctx context.Context, result *models.Result) bool {
+	scores, scoresErr := json.Marshal(result.Scores)
+	if scoresErr != nil {
+		Error.Println(scoresErr)
+		return false
 	}
 
-	client := d.Client.NewClient("Results")
-	entities := listEntities(ctx, client, &aztables.ListEntitiesOptions{
-		Filter: to.Ptr(fmt.Sprintf("GameID eq '%s'", game.ID)),
-	})
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: result.GameID,
+			RowKey:       result.ID,
+		},
+		Properties: map[string]interface{}{
+			"Scores": string(scores),
+		},
+	}
 
-	deleteCount := 0
-	for i := 0; i < len(entities); i++ {
-		result := entities[i]
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
 
-		_, err := client.DeleteEntity(ctx, result.PartitionKey, result.RowKey, nil)
-		if err != nil {
-			Error.Println(err)
-		} else {
-			deleteCount++
-		}
+	_, updateErr := d.Client.NewClient("Results").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
 	}
 
-	return true, int64(deleteCount)
+	return true
 }
 
-// ScrubResultsWithPlayer implements IDatabase
-func (d *TableStorageDatabase) ScrubResultsWithPlayer(ctx context.Context, username string) (bool, int64) {
-	success, relevantResults := d.GetResultsWithPlayer(ctx, username)
+// UpdateResultReminder implements IDatabase
+func (d *TableStorageDatabase) UpdateResultReminder(ctx context.Context, result *models.Result) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: result.GameID,
+			RowKey:       result.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeLastReminded": aztables.EDMInt64(result.TimeLastReminded),
+		},
+	}
 
-	if !success {
-		return false, 0
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
 	}
 
-	client := d.Client.NewClient("Results")
+	_, updateErr := d.Client.NewClient("Results").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteResultsWithGame implements IDatabase
+func (d *TableStorageDatabase) DeleteResultsWithGame(ctx context.Context, gameId string) (bool, int64) {
+	game := d.findGame(ctx, gameId)
+	if game == nil {
+		return false, 0
+	}
+
+	return deleteEntities(ctx, d.Client.NewClient("Results"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("GameID eq '%s'", game.ID)),
+	})
+}
+
+// DeleteResultsForGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteResultsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("Results"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("GroupID eq '%s'", groupId)),
+	})
+}
+
+// ScrubResultsWithPlayer implements IDatabase
+func (d *TableStorageDatabase) ScrubResultsWithPlayer(ctx context.Context, username string) (bool, int64) {
+	return d.RenamePlayerInResults(ctx, username, "")
+}
+
+// RenamePlayerInResults implements IDatabase
+func (d *TableStorageDatabase) RenamePlayerInResults(ctx context.Context, oldUsername string, newUsername string) (bool, int64) {
+	success, relevantResults := d.GetResultsWithPlayer(ctx, oldUsername)
+
+	if !success {
+		return false, 0
+	}
+
+	client := d.Client.NewClient("Results")
 
 	updateCount := 0
 	for i := 0; i < len(relevantResults); i++ {
@@ -714,8 +1465,8 @@ func (d *TableStorageDatabase) ScrubResultsWithPlayer(ctx context.Context, usern
 		scores := result.Scores
 		for j := range scores {
 			// TODO: use slices.ContainsFunc(...) to check
-			if scores[j].Username == username {
-				result.Scores[j].Username = ""
+			if scores[j].Username == oldUsername {
+				result.Scores[j].Username = newUsername
 			}
 		}
 
@@ -725,68 +1476,1286 @@ func (d *TableStorageDatabase) ScrubResultsWithPlayer(ctx context.Context, usern
 			continue
 		}
 
-		// create new entity with scrubbed scores data for merging
-		entity := aztables.EDMEntity{
-			Entity: aztables.Entity{
-				PartitionKey: result.GameID,
-				RowKey:       result.ID,
-			},
-			Properties: map[string]interface{}{
-				"Scores": string(marshalledScores),
-			},
-		}
+		// create new entity with renamed scores data for merging
+		entity := aztables.EDMEntity{
+			Entity: aztables.Entity{
+				PartitionKey: result.GameID,
+				RowKey:       result.ID,
+			},
+			Properties: map[string]interface{}{
+				"Scores": string(marshalledScores),
+			},
+		}
+
+		marshalled, err := json.Marshal(entity)
+		if err != nil {
+			Error.Println(err)
+			continue
+		}
+
+		_, updateErr := client.UpdateEntity(ctx, marshalled, nil)
+		if updateErr != nil {
+			Error.Println(updateErr)
+		} else {
+			updateCount++
+		}
+	}
+
+	return true, int64(updateCount)
+}
+
+// GetFriendships implements IDatabase
+func (d *TableStorageDatabase) GetFriendships(ctx context.Context, username string) (bool, []models.Friendship) {
+	friendships := list(ctx, d.Client, "Friendships", createFriendship, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s' or FriendUsername eq '%s'", username, username)),
+	})
+
+	return true, friendships
+}
+
+// GetFriendship implements IDatabase
+func (d *TableStorageDatabase) GetFriendship(ctx context.Context, friendshipId string) (bool, *models.Friendship) {
+	entities := listEntities(ctx, d.Client.NewClient("Friendships"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", friendshipId)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	friendship := createFriendship(&entities[0])
+	return true, &friendship
+}
+
+// AddFriendship implements IDatabase
+func (d *TableStorageDatabase) AddFriendship(ctx context.Context, newFriendship *models.Friendship) bool {
+	newFriendship.ID = uuid.NewString()
+	newFriendship.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newFriendship.Username,
+			RowKey:       newFriendship.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":       newFriendship.Username,
+			"FriendUsername": newFriendship.FriendUsername,
+			"TimeCreated":    aztables.EDMInt64(newFriendship.TimeCreated),
+			"Status":         string(newFriendship.Status),
+			"TimeAccepted":   aztables.EDMInt64(newFriendship.TimeAccepted),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Friendships").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateFriendship implements IDatabase
+func (d *TableStorageDatabase) UpdateFriendship(ctx context.Context, friendship *models.Friendship) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: friendship.Username,
+			RowKey:       friendship.ID,
+		},
+		Properties: map[string]interface{}{
+			"Status":       string(friendship.Status),
+			"TimeAccepted": aztables.EDMInt64(friendship.TimeAccepted),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Friendships").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteFriendship implements IDatabase
+func (d *TableStorageDatabase) DeleteFriendship(ctx context.Context, friendshipId string) bool {
+	success, deleteCount := deleteEntities(ctx, d.Client.NewClient("Friendships"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", friendshipId)),
+	})
+
+	return success && deleteCount > 0
+}
+
+// GetGuestPlayers implements IDatabase
+func (d *TableStorageDatabase) GetGuestPlayers(ctx context.Context, groupId string) (bool, []models.GuestPlayer) {
+	guests := list(ctx, d.Client, "GuestPlayers", createGuestPlayer, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+
+	return true, guests
+}
+
+// GetGuestPlayer implements IDatabase
+func (d *TableStorageDatabase) GetGuestPlayer(ctx context.Context, username string) (bool, *models.GuestPlayer) {
+	entities := listEntities(ctx, d.Client.NewClient("GuestPlayers"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Username eq '%s'", username)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	guest := createGuestPlayer(&entities[0])
+	return true, &guest
+}
+
+// AddGuestPlayer implements IDatabase
+func (d *TableStorageDatabase) AddGuestPlayer(ctx context.Context, newGuest *models.GuestPlayer) bool {
+	newGuest.ID = uuid.NewString()
+	newGuest.Username = models.GuestUsernamePrefix + newGuest.ID
+	newGuest.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newGuest.GroupID,
+			RowKey:       newGuest.ID,
+		},
+		Properties: map[string]interface{}{
+			"GroupID":     newGuest.GroupID,
+			"Username":    newGuest.Username,
+			"DisplayName": newGuest.DisplayName,
+			"CreatedBy":   newGuest.CreatedBy,
+			"TimeCreated": aztables.EDMInt64(newGuest.TimeCreated),
+			"ClaimedBy":   newGuest.ClaimedBy,
+			"TimeClaimed": aztables.EDMInt64(newGuest.TimeClaimed),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("GuestPlayers").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateGuestPlayer implements IDatabase
+func (d *TableStorageDatabase) UpdateGuestPlayer(ctx context.Context, guest *models.GuestPlayer) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: guest.GroupID,
+			RowKey:       guest.ID,
+		},
+		Properties: map[string]interface{}{
+			"DisplayName": guest.DisplayName,
+			"ClaimedBy":   guest.ClaimedBy,
+			"TimeClaimed": aztables.EDMInt64(guest.TimeClaimed),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("GuestPlayers").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteGuestPlayersForGroup implements IDatabase
+func (d *TableStorageDatabase) DeleteGuestPlayersForGroup(ctx context.Context, groupId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("GuestPlayers"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+}
+
+// GetAllUsers implements IDatabase
+func (d *TableStorageDatabase) GetAllUsers(ctx context.Context) (bool, []models.User) {
+	users := list(ctx, d.Client, "Users", createUser, nil)
+	return true, users
+}
+
+// GetUser implements IDatabase
+func (d *TableStorageDatabase) GetUser(ctx context.Context, username string) (bool, *models.User) {
+	result := d.findUser(ctx, username)
+	if result == nil {
+		return false, nil
+	}
+
+	user := createUser(result)
+	return true, &user
+}
+
+// GetUserByEmail implements IDatabase
+func (d *TableStorageDatabase) GetUserByEmail(ctx context.Context, email string) (bool, *models.User) {
+	result := d.findUserByEmail(ctx, email)
+	if result == nil {
+		return false, nil
+	}
+
+	user := createUser(result)
+	return true, &user
+}
+
+func (d *TableStorageDatabase) AddUser(ctx context.Context, newUser *models.User) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "Users",
+			RowKey:       newUser.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":    newUser.Username,
+			"TimeCreated": aztables.EDMInt64(newUser.TimeCreated),
+			"Email":       newUser.Email,
+			"Password":    newUser.Password,
+			"Permissions": strings.Join(newUser.Permissions, ";"),
+			"Roles":       strings.Join(newUser.Roles, ";"),
+			"Disabled":    newUser.Disabled,
+
+			"DisplayName":     newUser.DisplayName,
+			"ProfilePicture":  newUser.ProfilePicture,
+			"UploadedPicture": newUser.UploadedPicture,
+
+			"EmailVerified": newUser.EmailVerified,
+			"HasPassword":   newUser.HasPassword,
+
+			"TwoFactorEnabled":       newUser.TwoFactor.Enabled,
+			"TwoFactorSecret":        newUser.TwoFactor.Secret,
+			"TwoFactorLastStep":      aztables.EDMInt64(newUser.TwoFactor.LastStep),
+			"TwoFactorRecoveryCodes": strings.Join(newUser.TwoFactor.RecoveryCodes, ";"),
+
+			"EmailInvitations":       newUser.EmailPreferences.Invitations,
+			"EmailApprovalReminders": newUser.EmailPreferences.ApprovalReminders,
+
+			"InvitationsFromFriendsOnly": newUser.FriendRestrictions.InvitationsFromFriendsOnly,
+			"ResultsFromFriendsOnly":     newUser.FriendRestrictions.ResultsFromFriendsOnly,
+
+			"ProfileVisibility":        string(newUser.Privacy.ProfileVisibility),
+			"ResultsNeedApproval":      newUser.Privacy.ResultsNeedApproval,
+			"ShowInPublicLeaderboards": newUser.Privacy.ShowInPublicLeaderboards,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Users").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UserExists implements IDatabase
+func (d *TableStorageDatabase) UserExists(ctx context.Context, username string) bool {
+	result := d.findUser(ctx, username)
+	return result != nil
+}
+
+// UserExistsByEmail implements IDatabase
+func (d *TableStorageDatabase) UserExistsByEmail(ctx context.Context, email string) bool {
+	result := d.findUserByEmail(ctx, email)
+	return result != nil
+}
+
+// DeleteUser implements IDatabase
+func (d *TableStorageDatabase) DeleteUser(ctx context.Context, username string) bool {
+	user := d.findUser(ctx, username)
+	if user == nil {
+		return false
+	}
+
+	_, err := d.Client.NewClient("Users").DeleteEntity(ctx, user.PartitionKey, user.RowKey, nil)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+// ReserveUsername implements IDatabase
+func (d *TableStorageDatabase) ReserveUsername(ctx context.Context, username string) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: username,
+			RowKey:       username,
+		},
+		Properties: map[string]interface{}{
+			"TimeReserved": aztables.EDMInt64(time.Now().UTC().Unix()),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, upsertErr := d.Client.NewClient("ReservedUsernames").UpsertEntity(ctx, marshalled, nil)
+	if upsertErr != nil {
+		Error.Println(upsertErr)
+		return false
+	}
+
+	return true
+}
+
+// IsUsernameReserved implements IDatabase
+func (d *TableStorageDatabase) IsUsernameReserved(ctx context.Context, username string) bool {
+	entities := listEntities(ctx, d.Client.NewClient("ReservedUsernames"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+
+	return len(entities) > 0
+}
+
+// UpdateUser implements IDatabase
+func (d *TableStorageDatabase) UpdateUser(ctx context.Context, user *models.User) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "Users",
+			RowKey:       user.ID,
+		},
+		Properties: map[string]interface{}{
+			"Email":       user.Email,
+			"Password":    user.Password,
+			"Permissions": strings.Join(user.Permissions, ";"),
+			"Roles":       strings.Join(user.Roles, ";"),
+			"Disabled":    user.Disabled,
+
+			"DisplayName":     user.DisplayName,
+			"ProfilePicture":  user.ProfilePicture,
+			"UploadedPicture": user.UploadedPicture,
+
+			"EmailVerified": user.EmailVerified,
+			"HasPassword":   user.HasPassword,
+
+			"TwoFactorEnabled":       user.TwoFactor.Enabled,
+			"TwoFactorSecret":        user.TwoFactor.Secret,
+			"TwoFactorLastStep":      aztables.EDMInt64(user.TwoFactor.LastStep),
+			"TwoFactorRecoveryCodes": strings.Join(user.TwoFactor.RecoveryCodes, ";"),
+
+			"EmailInvitations":       user.EmailPreferences.Invitations,
+			"EmailApprovalReminders": user.EmailPreferences.ApprovalReminders,
+
+			"InvitationsFromFriendsOnly": user.FriendRestrictions.InvitationsFromFriendsOnly,
+			"ResultsFromFriendsOnly":     user.FriendRestrictions.ResultsFromFriendsOnly,
+
+			"ProfileVisibility":        string(user.Privacy.ProfileVisibility),
+			"ResultsNeedApproval":      user.Privacy.ResultsNeedApproval,
+			"ShowInPublicLeaderboards": user.Privacy.ShowInPublicLeaderboards,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Users").UpdateEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// GetWebhooks implements IDatabase
+func (d *TableStorageDatabase) GetWebhooks(ctx context.Context, groupId string) (bool, []models.Webhook) {
+	webhooks := list(ctx, d.Client, "Webhooks", createWebhook, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", groupId)),
+	})
+
+	return true, webhooks
+}
+
+// GetWebhook implements IDatabase
+func (d *TableStorageDatabase) GetWebhook(ctx context.Context, webhookId string) (bool, *models.Webhook) {
+	entity := d.findWebhook(ctx, webhookId)
+	if entity == nil {
+		return false, nil
+	}
+
+	webhook := createWebhook(entity)
+	return true, &webhook
+}
+
+// AddWebhook implements IDatabase
+func (d *TableStorageDatabase) AddWebhook(ctx context.Context, newWebhook *models.Webhook) bool {
+	newWebhook.ID = uuid.NewString()
+	newWebhook.TimeCreated = time.Now().UTC().Unix()
+
+	events := []string{}
+	for _, e := range newWebhook.Events {
+		events = append(events, string(e))
+	}
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newWebhook.GroupID,
+			RowKey:       newWebhook.ID,
+		},
+		Properties: map[string]interface{}{
+			"GroupID":     newWebhook.GroupID,
+			"TimeCreated": aztables.EDMInt64(newWebhook.TimeCreated),
+			"CreatedBy":   newWebhook.CreatedBy,
+			"URL":         newWebhook.URL,
+			"Secret":      newWebhook.Secret,
+			"Events":      strings.Join(events, ";"),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Webhooks").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteWebhook implements IDatabase
+func (d *TableStorageDatabase) DeleteWebhook(ctx context.Context, webhookId string) bool {
+	webhook := d.findWebhook(ctx, webhookId)
+	if webhook == nil {
+		return false
+	}
+
+	_, err := d.Client.NewClient("Webhooks").DeleteEntity(ctx, webhook.PartitionKey, webhook.RowKey, nil)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+// GetWebhookDeliveries implements IDatabase
+func (d *TableStorageDatabase) GetWebhookDeliveries(ctx context.Context, webhookId string) (bool, []models.WebhookDelivery) {
+	deliveries := list(ctx, d.Client, "WebhookDeliveries", createWebhookDelivery, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", webhookId)),
+	})
+
+	return true, deliveries
+}
+
+// GetPendingWebhookDeliveries implements IDatabase
+func (d *TableStorageDatabase) GetPendingWebhookDeliveries(ctx context.Context) (bool, []models.WebhookDelivery) {
+	deliveries := list(ctx, d.Client, "WebhookDeliveries", createWebhookDelivery, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Status eq '%s'", models.DeliveryPending)),
+	})
+
+	return true, deliveries
+}
+
+// GetWebhookDelivery implements IDatabase
+func (d *TableStorageDatabase) GetWebhookDelivery(ctx context.Context, deliveryId string) (bool, *models.WebhookDelivery) {
+	entity := d.findWebhookDelivery(ctx, deliveryId)
+	if entity == nil {
+		return false, nil
+	}
+
+	delivery := createWebhookDelivery(entity)
+	return true, &delivery
+}
+
+// AddWebhookDelivery implements IDatabase
+func (d *TableStorageDatabase) AddWebhookDelivery(ctx context.Context, newDelivery *models.WebhookDelivery) bool {
+	newDelivery.ID = uuid.NewString()
+	newDelivery.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newDelivery.WebhookID,
+			RowKey:       newDelivery.ID,
+		},
+		Properties: map[string]interface{}{
+			"WebhookID":       newDelivery.WebhookID,
+			"GroupID":         newDelivery.GroupID,
+			"TimeCreated":     aztables.EDMInt64(newDelivery.TimeCreated),
+			"EventType":       string(newDelivery.EventType),
+			"Payload":         newDelivery.Payload,
+			"Status":          string(newDelivery.Status),
+			"Attempts":        newDelivery.Attempts,
+			"TimeLastAttempt": aztables.EDMInt64(newDelivery.TimeLastAttempt),
+			"TimeNextAttempt": aztables.EDMInt64(newDelivery.TimeNextAttempt),
+			"ResponseStatus":  newDelivery.ResponseStatus,
+			"LastError":       newDelivery.LastError,
+			"ReplayOf":        newDelivery.ReplayOf,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("WebhookDeliveries").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateWebhookDelivery implements IDatabase
+func (d *TableStorageDatabase) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: delivery.WebhookID,
+			RowKey:       delivery.ID,
+		},
+		Properties: map[string]interface{}{
+			"Status":          string(delivery.Status),
+			"Attempts":        delivery.Attempts,
+			"TimeLastAttempt": aztables.EDMInt64(delivery.TimeLastAttempt),
+			"TimeNextAttempt": aztables.EDMInt64(delivery.TimeNextAttempt),
+			"ResponseStatus":  delivery.ResponseStatus,
+			"LastError":       delivery.LastError,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("WebhookDeliveries").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// DeleteWebhookDeliveries implements IDatabase
+func (d *TableStorageDatabase) DeleteWebhookDeliveries(ctx context.Context, webhookId string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("WebhookDeliveries"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", webhookId)),
+	})
+}
+
+// GetUserTokenByHash implements IDatabase
+func (d *TableStorageDatabase) GetUserTokenByHash(ctx context.Context, tokenHash string) (bool, *models.UserToken) {
+	entities := listEntities(ctx, d.Client.NewClient("UserTokens"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("TokenHash eq '%s'", tokenHash)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	token := createUserToken(&entities[0])
+	return true, &token
+}
+
+// GetUserTokens implements IDatabase
+func (d *TableStorageDatabase) GetUserTokens(ctx context.Context, username string, purpose models.TokenPurpose) (bool, []models.UserToken) {
+	tokens := list(ctx, d.Client, "UserTokens", createUserToken, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and Purpose eq '%s'", username, purpose)),
+	})
+
+	return true, tokens
+}
+
+// AddUserToken implements IDatabase
+func (d *TableStorageDatabase) AddUserToken(ctx context.Context, newToken *models.UserToken) bool {
+	newToken.ID = uuid.NewString()
+	newToken.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newToken.Username,
+			RowKey:       newToken.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":    newToken.Username,
+			"Purpose":     string(newToken.Purpose),
+			"TokenHash":   newToken.TokenHash,
+			"Data":        newToken.Data,
+			"TimeCreated": aztables.EDMInt64(newToken.TimeCreated),
+			"TimeExpires": aztables.EDMInt64(newToken.TimeExpires),
+			"Used":        newToken.Used,
+			"TimeUsed":    aztables.EDMInt64(newToken.TimeUsed),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("UserTokens").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateUserToken implements IDatabase
+func (d *TableStorageDatabase) UpdateUserToken(ctx context.Context, token *models.UserToken) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: token.Username,
+			RowKey:       token.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeExpires": aztables.EDMInt64(token.TimeExpires),
+			"Used":        token.Used,
+			"TimeUsed":    aztables.EDMInt64(token.TimeUsed),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("UserTokens").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// UseUserToken implements IDatabase. It fails if the token has changed since it was read,
+// e.g. because it's already been used
+func (d *TableStorageDatabase) UseUserToken(ctx context.Context, token *models.UserToken) bool {
+	token.Used = true
+	token.TimeUsed = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: token.Username,
+			RowKey:       token.ID,
+		},
+		Properties: map[string]interface{}{
+			"Used":     token.Used,
+			"TimeUsed": aztables.EDMInt64(token.TimeUsed),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	response, updateErr := d.Client.NewClient("UserTokens").UpdateEntity(ctx, marshalled, &aztables.UpdateEntityOptions{
+		IfMatch:    to.Ptr(azcore.ETag(token.ETag)),
+		UpdateMode: aztables.UpdateModeMerge,
+	})
+
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	token.ETag = string(response.ETag)
+
+	return true
+}
+
+// DeleteUserTokens implements IDatabase
+func (d *TableStorageDatabase) DeleteUserTokens(ctx context.Context, username string) (bool, int64) {
+	return deleteEntities(ctx, d.Client.NewClient("UserTokens"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+}
+
+// GetSessions implements IDatabase
+func (d *TableStorageDatabase) GetSessions(ctx context.Context, username string) (bool, []models.Session) {
+	sessions := list(ctx, d.Client, "Sessions", createSession, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+
+	return true, sessions
+}
+
+// GetSession implements IDatabase
+func (d *TableStorageDatabase) GetSession(ctx context.Context, username string, sessionId string) (bool, *models.Session) {
+	entities := listEntities(ctx, d.Client.NewClient("Sessions"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", username, sessionId)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	session := createSession(&entities[0])
+	return true, &session
+}
+
+// AddSession implements IDatabase
+func (d *TableStorageDatabase) AddSession(ctx context.Context, newSession *models.Session) bool {
+	newSession.ID = uuid.NewString()
+	newSession.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newSession.Username,
+			RowKey:       newSession.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":     newSession.Username,
+			"TimeCreated":  aztables.EDMInt64(newSession.TimeCreated),
+			"TimeLastUsed": aztables.EDMInt64(newSession.TimeLastUsed),
+			"TimeExpires":  aztables.EDMInt64(newSession.TimeExpires),
+			"UserAgent":    newSession.UserAgent,
+			"IPAddress":    newSession.IPAddress,
+			"Revoked":      newSession.Revoked,
+			"TimeRevoked":  aztables.EDMInt64(newSession.TimeRevoked),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("Sessions").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateSession implements IDatabase
+func (d *TableStorageDatabase) UpdateSession(ctx context.Context, session *models.Session) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: session.Username,
+			RowKey:       session.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeLastUsed": aztables.EDMInt64(session.TimeLastUsed),
+			"TimeExpires":  aztables.EDMInt64(session.TimeExpires),
+			"Revoked":      session.Revoked,
+			"TimeRevoked":  aztables.EDMInt64(session.TimeRevoked),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("Sessions").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// GetAPIKeys implements IDatabase
+func (d *TableStorageDatabase) GetAPIKeys(ctx context.Context, username string) (bool, []models.APIKey) {
+	keys := list(ctx, d.Client, "APIKeys", createAPIKey, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+
+	return true, keys
+}
+
+// GetAPIKeyByHash implements IDatabase
+func (d *TableStorageDatabase) GetAPIKeyByHash(ctx context.Context, keyHash string) (bool, *models.APIKey) {
+	entities := listEntities(ctx, d.Client.NewClient("APIKeys"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("KeyHash eq '%s'", keyHash)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	key := createAPIKey(&entities[0])
+	return true, &key
+}
+
+// AddAPIKey implements IDatabase
+func (d *TableStorageDatabase) AddAPIKey(ctx context.Context, newKey *models.APIKey) bool {
+	newKey.ID = uuid.NewString()
+	newKey.TimeCreated = time.Now().UTC().Unix()
+
+	scopes := make([]string, len(newKey.Scopes))
+	for i, s := range newKey.Scopes {
+		scopes[i] = string(s)
+	}
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newKey.Username,
+			RowKey:       newKey.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":     newKey.Username,
+			"Name":         newKey.Name,
+			"Prefix":       newKey.Prefix,
+			"KeyHash":      newKey.KeyHash,
+			"Scopes":       strings.Join(scopes, ";"),
+			"GroupID":      newKey.GroupID,
+			"TimeCreated":  aztables.EDMInt64(newKey.TimeCreated),
+			"TimeLastUsed": aztables.EDMInt64(newKey.TimeLastUsed),
+			"Revoked":      newKey.Revoked,
+			"TimeRevoked":  aztables.EDMInt64(newKey.TimeRevoked),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("APIKeys").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateAPIKey implements IDatabase
+func (d *TableStorageDatabase) UpdateAPIKey(ctx context.Context, key *models.APIKey) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: key.Username,
+			RowKey:       key.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeLastUsed": aztables.EDMInt64(key.TimeLastUsed),
+			"Revoked":      key.Revoked,
+			"TimeRevoked":  aztables.EDMInt64(key.TimeRevoked),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("APIKeys").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// GetLoginThrottle implements IDatabase
+func (d *TableStorageDatabase) GetLoginThrottle(ctx context.Context, kind models.ThrottleKind, key string) (bool, *models.LoginThrottle) {
+	entities := listEntities(ctx, d.Client.NewClient("LoginThrottles"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s' and RowKey eq '%s'", kind, key)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	throttle := createLoginThrottle(&entities[0])
+	return true, &throttle
+}
+
+// UpsertLoginThrottle implements IDatabase
+func (d *TableStorageDatabase) UpsertLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: string(throttle.Kind),
+			RowKey:       throttle.Key,
+		},
+		Properties: map[string]interface{}{
+			"FailedAttempts":  throttle.FailedAttempts,
+			"TimeLastFailure": aztables.EDMInt64(throttle.TimeLastFailure),
+			"TimeLockedUntil": aztables.EDMInt64(throttle.TimeLockedUntil),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, upsertErr := d.Client.NewClient("LoginThrottles").UpsertEntity(ctx, marshalled, nil)
+	if upsertErr != nil {
+		Error.Println(upsertErr)
+		return false
+	}
+
+	return true
+}
+
+// SaveLoginThrottle implements IDatabase. It fails if the throttle has been added or
+// changed since it was read
+func (d *TableStorageDatabase) SaveLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: string(throttle.Kind),
+			RowKey:       throttle.Key,
+		},
+		Properties: map[string]interface{}{
+			"FailedAttempts":  throttle.FailedAttempts,
+			"TimeLastFailure": aztables.EDMInt64(throttle.TimeLastFailure),
+			"TimeLockedUntil": aztables.EDMInt64(throttle.TimeLockedUntil),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	client := d.Client.NewClient("LoginThrottles")
+
+	// a throttle that wasn't read yet can only be added if nobody else has added it
+	if len(throttle.ETag) <= 0 {
+		response, addErr := client.AddEntity(ctx, marshalled, nil)
+		if addErr != nil {
+			Error.Println(addErr)
+			return false
+		}
+
+		throttle.ETag = string(response.ETag)
+		return true
+	}
+
+	response, updateErr := client.UpdateEntity(ctx, marshalled, &aztables.UpdateEntityOptions{
+		IfMatch:    to.Ptr(azcore.ETag(throttle.ETag)),
+		UpdateMode: aztables.UpdateModeMerge,
+	})
+
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	throttle.ETag = string(response.ETag)
+	return true
+}
+
+// GetMergeJobs implements IDatabase
+func (d *TableStorageDatabase) GetMergeJobs(ctx context.Context) (bool, []models.MergeJob) {
+	jobs := list(ctx, d.Client, "MergeJobs", createMergeJob, nil)
+	return true, jobs
+}
+
+// GetMergeJob implements IDatabase
+func (d *TableStorageDatabase) GetMergeJob(ctx context.Context, jobId string) (bool, *models.MergeJob) {
+	entities := listEntities(ctx, d.Client.NewClient("MergeJobs"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", jobId)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	job := createMergeJob(&entities[0])
+	return true, &job
+}
+
+// AddMergeJob implements IDatabase
+func (d *TableStorageDatabase) AddMergeJob(ctx context.Context, newJob *models.MergeJob) bool {
+	newJob.ID = uuid.NewString()
+	newJob.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "MergeJobs",
+			RowKey:       newJob.ID,
+		},
+		Properties: map[string]interface{}{
+			"FromUsername":      newJob.FromUsername,
+			"ToUsername":        newJob.ToUsername,
+			"GroupID":           newJob.GroupID,
+			"CreatedBy":         newJob.CreatedBy,
+			"TimeCreated":       aztables.EDMInt64(newJob.TimeCreated),
+			"TimeCompleted":     aztables.EDMInt64(newJob.TimeCompleted),
+			"Status":            string(newJob.Status),
+			"Phase":             string(newJob.Phase),
+			"LastError":         newJob.LastError,
+			"ResultsMerged":     newJob.ResultsMerged,
+			"ApprovalsMerged":   newJob.ApprovalsMerged,
+			"MembershipsMerged": newJob.MembershipsMerged,
+			"InvitationsMerged": newJob.InvitationsMerged,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("MergeJobs").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateMergeJob implements IDatabase
+func (d *TableStorageDatabase) UpdateMergeJob(ctx context.Context, job *models.MergeJob) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "MergeJobs",
+			RowKey:       job.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeCompleted":     aztables.EDMInt64(job.TimeCompleted),
+			"Status":            string(job.Status),
+			"Phase":             string(job.Phase),
+			"LastError":         job.LastError,
+			"ResultsMerged":     job.ResultsMerged,
+			"ApprovalsMerged":   job.ApprovalsMerged,
+			"MembershipsMerged": job.MembershipsMerged,
+			"InvitationsMerged": job.InvitationsMerged,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, updateErr := d.Client.NewClient("MergeJobs").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
+	}
+
+	return true
+}
+
+// GetAuditEntries implements IDatabase
+func (d *TableStorageDatabase) GetAuditEntries(ctx context.Context) (bool, []models.AuditEntry) {
+	entries := list(ctx, d.Client, "AuditLog", createAuditEntry, nil)
+	return true, entries
+}
+
+// AddAuditEntry implements IDatabase
+func (d *TableStorageDatabase) AddAuditEntry(ctx context.Context, newEntry *models.AuditEntry) bool {
+	newEntry.ID = uuid.NewString()
+	newEntry.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: "AuditLog",
+			RowKey:       newEntry.ID,
+		},
+		Properties: map[string]interface{}{
+			"TimeCreated":    aztables.EDMInt64(newEntry.TimeCreated),
+			"Type":           string(newEntry.Type),
+			"Username":       newEntry.Username,
+			"TargetUsername": newEntry.TargetUsername,
+			"IPAddress":      newEntry.IPAddress,
+			"Details":        newEntry.Details,
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("AuditLog").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// GetExternalIdentity implements IDatabase
+func (d *TableStorageDatabase) GetExternalIdentity(ctx context.Context, issuer string, subject string) (bool, *models.ExternalIdentity) {
+	entities := listEntities(ctx, d.Client.NewClient("ExternalIdentities"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("Issuer eq '%s' and Subject eq '%s'", issuer, subject)),
+	})
+
+	if len(entities) != 1 {
+		return false, nil
+	}
+
+	identity := createExternalIdentity(&entities[0])
+	return true, &identity
+}
+
+// GetExternalIdentities implements IDatabase
+func (d *TableStorageDatabase) GetExternalIdentities(ctx context.Context, username string) (bool, []models.ExternalIdentity) {
+	identities := list(ctx, d.Client, "ExternalIdentities", createExternalIdentity, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq '%s'", username)),
+	})
+
+	return true, identities
+}
+
+// AddExternalIdentity implements IDatabase
+func (d *TableStorageDatabase) AddExternalIdentity(ctx context.Context, newIdentity *models.ExternalIdentity) bool {
+	newIdentity.ID = uuid.NewString()
+	newIdentity.TimeCreated = time.Now().UTC().Unix()
+
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: newIdentity.Username,
+			RowKey:       newIdentity.ID,
+		},
+		Properties: map[string]interface{}{
+			"Username":      newIdentity.Username,
+			"Issuer":        newIdentity.Issuer,
+			"Subject":       newIdentity.Subject,
+			"Email":         newIdentity.Email,
+			"TimeCreated":   aztables.EDMInt64(newIdentity.TimeCreated),
+			"TimeLastLogin": aztables.EDMInt64(newIdentity.TimeLastLogin),
+		},
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	_, addErr := d.Client.NewClient("ExternalIdentities").AddEntity(ctx, marshalled, nil)
+	if addErr != nil {
+		Error.Println(addErr)
+		return false
+	}
+
+	return true
+}
+
+// UpdateExternalIdentity implements IDatabase
+func (d *TableStorageDatabase) UpdateExternalIdentity(ctx context.Context, identity *models.ExternalIdentity) bool {
+	entity := aztables.EDMEntity{
+		Entity: aztables.Entity{
+			PartitionKey: identity.Username,
+			RowKey:       identity.ID,
+		},
+		Properties: map[string]interface{}{
+			"Email":         identity.Email,
+			"TimeLastLogin": aztables.EDMInt64(identity.TimeLastLogin),
+		},
+	}
 
-		marshalled, err := json.Marshal(entity)
-		if err != nil {
-			Error.Println(err)
-			continue
-		}
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
 
-		_, updateErr := client.UpdateEntity(ctx, marshalled, nil)
-		if updateErr != nil {
-			Error.Println(updateErr)
-		} else {
-			updateCount++
-		}
+	_, updateErr := d.Client.NewClient("ExternalIdentities").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
+		return false
 	}
 
-	return true, int64(updateCount)
+	return true
 }
 
-// GetUser implements IDatabase
-func (d *TableStorageDatabase) GetUser(ctx context.Context, username string) (bool, *models.User) {
-	result := d.findUser(ctx, username)
-	if result == nil {
-		return false, nil
+// DeleteExternalIdentity implements IDatabase
+func (d *TableStorageDatabase) DeleteExternalIdentity(ctx context.Context, username string, identityId string) bool {
+	_, err := d.Client.NewClient("ExternalIdentities").DeleteEntity(ctx, username, identityId, nil)
+	if err != nil {
+		Error.Println(err)
+		return false
 	}
 
-	user := createUser(result)
-	return true, &user
+	return true
 }
 
-// GetUserByEmail implements IDatabase
-func (d *TableStorageDatabase) GetUserByEmail(ctx context.Context, email string) (bool, *models.User) {
-	result := d.findUserByEmail(ctx, email)
-	if result == nil {
+// GetLoginStateByHash implements IDatabase
+func (d *TableStorageDatabase) GetLoginStateByHash(ctx context.Context, stateHash string) (bool, *models.LoginState) {
+	entities := listEntities(ctx, d.Client.NewClient("LoginStates"), &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("PartitionKey eq 'LoginStates' and StateHash eq '%s'", stateHash)),
+	})
+
+	if len(entities) != 1 {
 		return false, nil
 	}
 
-	user := createUser(result)
-	return true, &user
+	state := createLoginState(&entities[0])
+	return true, &state
 }
 
-func (d *TableStorageDatabase) AddUser(ctx context.Context, newUser *models.User) bool {
+// AddLoginState implements IDatabase
+func (d *TableStorageDatabase) AddLoginState(ctx context.Context, newState *models.LoginState) bool {
+	newState.ID = uuid.NewString()
+	newState.TimeCreated = time.Now().UTC().Unix()
+
 	entity := aztables.EDMEntity{
 		Entity: aztables.Entity{
-			PartitionKey: "Users",
-			RowKey:       newUser.ID,
+			PartitionKey: "LoginStates",
+			RowKey:       newState.ID,
 		},
 		Properties: map[string]interface{}{
-			"Username":    newUser.Username,
-			"TimeCreated": aztables.EDMInt64(newUser.TimeCreated),
-			"Email":       newUser.Email,
-			"Password":    newUser.Password,
-			"Permissions": strings.Join(newUser.Permissions, ";"),
+			"StateHash":    newState.StateHash,
+			"Nonce":        newState.Nonce,
+			"CodeVerifier": newState.CodeVerifier,
+			"LinkUsername": newState.LinkUsername,
+			"TimeCreated":  aztables.EDMInt64(newState.TimeCreated),
+			"TimeExpires":  aztables.EDMInt64(newState.TimeExpires),
+			"Used":         newState.Used,
 		},
 	}
 
@@ -796,7 +2765,7 @@ func (d *TableStorageDatabase) AddUser(ctx context.Context, newUser *models.User
 		return false
 	}
 
-	_, addErr := d.Client.NewClient("Users").AddEntity(ctx, marshalled, nil)
+	_, addErr := d.Client.NewClient("LoginStates").AddEntity(ctx, marshalled, nil)
 	if addErr != nil {
 		Error.Println(addErr)
 		return false
@@ -805,27 +2774,15 @@ func (d *TableStorageDatabase) AddUser(ctx context.Context, newUser *models.User
 	return true
 }
 
-// UserExists implements IDatabase
-func (d *TableStorageDatabase) UserExists(ctx context.Context, username string) bool {
-	result := d.findUser(ctx, username)
-	return result != nil
-}
-
-// UserExistsByEmail implements IDatabase
-func (d *TableStorageDatabase) UserExistsByEmail(ctx context.Context, email string) bool {
-	result := d.findUserByEmail(ctx, email)
-	return result != nil
-}
-
-// UpdateUser implements IDatabase
-func (d *TableStorageDatabase) UpdateUser(ctx context.Context, user *models.User) bool {
+// UpdateLoginState implements IDatabase
+func (d *TableStorageDatabase) UpdateLoginState(ctx context.Context, state *models.LoginState) bool {
 	entity := aztables.EDMEntity{
 		Entity: aztables.Entity{
-			PartitionKey: "Users",
-			RowKey:       user.ID,
+			PartitionKey: "LoginStates",
+			RowKey:       state.ID,
 		},
 		Properties: map[string]interface{}{
-			"Password": user.Password,
+			"Used": state.Used,
 		},
 	}
 
@@ -835,9 +2792,9 @@ func (d *TableStorageDatabase) UpdateUser(ctx context.Context, user *models.User
 		return false
 	}
 
-	_, addErr := d.Client.NewClient("Users").UpdateEntity(ctx, marshalled, nil)
-	if addErr != nil {
-		Error.Println(addErr)
+	_, updateErr := d.Client.NewClient("LoginStates").UpdateEntity(ctx, marshalled, nil)
+	if updateErr != nil {
+		Error.Println(updateErr)
 		return false
 	}
 
@@ -939,11 +2896,11 @@ func (d *TableStorageDatabase) findGroupInvitation(ctx context.Context, id strin
 	return nil
 }
 
-func (d *TableStorageDatabase) findPlayer(ctx context.Context, username string) *aztables.EDMEntity {
-	client := d.Client.NewClient("Players")
+func (d *TableStorageDatabase) findNotification(ctx context.Context, id string) *aztables.EDMEntity {
+	client := d.Client.NewClient("Notifications")
 
 	entities := listEntities(ctx, client, &aztables.ListEntitiesOptions{
-		Filter: to.Ptr(fmt.Sprintf("Username eq '%s'", username)),
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", id)),
 	})
 
 	if len(entities) == 1 {
@@ -995,6 +2952,34 @@ func (d *TableStorageDatabase) findUserByEmail(ctx context.Context, email string
 	return nil
 }
 
+func (d *TableStorageDatabase) findWebhook(ctx context.Context, id string) *aztables.EDMEntity {
+	client := d.Client.NewClient("Webhooks")
+
+	entities := listEntities(ctx, client, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", id)),
+	})
+
+	if len(entities) == 1 {
+		return &entities[0]
+	}
+
+	return nil
+}
+
+func (d *TableStorageDatabase) findWebhookDelivery(ctx context.Context, id string) *aztables.EDMEntity {
+	client := d.Client.NewClient("WebhookDeliveries")
+
+	entities := listEntities(ctx, client, &aztables.ListEntitiesOptions{
+		Filter: to.Ptr(fmt.Sprintf("RowKey eq '%s'", id)),
+	})
+
+	if len(entities) == 1 {
+		return &entities[0]
+	}
+
+	return nil
+}
+
 func list[T interface{}](ctx context.Context, client *aztables.ServiceClient, tableName string, convert func(*aztables.EDMEntity) T, options *aztables.ListEntitiesOptions) []T {
 	entities := listEntities(ctx, client.NewClient(tableName), options)
 	data := []T{}
@@ -1030,6 +3015,29 @@ func listEntities(ctx context.Context, client *aztables.Client, options *aztable
 	return entities
 }
 
+// deletes every entity matching the given options, returning whether they were all
+// deleted and how many were
+func deleteEntities(ctx context.Context, client *aztables.Client, options *aztables.ListEntitiesOptions) (bool, int64) {
+	entities := listEntities(ctx, client, options)
+
+	success := true
+
+	deleteCount := 0
+	for i := 0; i < len(entities); i++ {
+		entity := entities[i]
+
+		_, err := client.DeleteEntity(ctx, entity.PartitionKey, entity.RowKey, nil)
+		if err != nil {
+			Error.Println(err)
+			success = false
+		} else {
+			deleteCount++
+		}
+	}
+
+	return success, int64(deleteCount)
+}
+
 func unmarshal(bytes []byte) *aztables.EDMEntity {
 	var entity aztables.EDMEntity
 
@@ -1042,6 +3050,19 @@ func unmarshal(bytes []byte) *aztables.EDMEntity {
 	return &entity
 }
 
+func createActivity(entity *aztables.EDMEntity) models.Activity {
+	return models.Activity{
+		ID:             entity.RowKey,
+		GroupID:        propString(entity, "GroupID"),
+		TimeCreated:    propInt64(entity, "TimeCreated"),
+		Type:           models.ActivityType(propString(entity, "Type")),
+		Username:       propString(entity, "Username"),
+		TargetUsername: propString(entity, "TargetUsername"),
+		ResultID:       propString(entity, "ResultID"),
+		GameID:         propString(entity, "GameID"),
+	}
+}
+
 func createApproval(entity *aztables.EDMEntity) models.Approval {
 	return models.Approval{
 		ID:             entity.RowKey,
@@ -1052,6 +3073,16 @@ func createApproval(entity *aztables.EDMEntity) models.Approval {
 	}
 }
 
+func createAttachment(entity *aztables.EDMEntity) models.Attachment {
+	return models.Attachment{
+		ID:          entity.RowKey,
+		ResultID:    propString(entity, "ResultID"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		UploadedBy:  propString(entity, "UploadedBy"),
+		BlobName:    propString(entity, "BlobName"),
+	}
+}
+
 func createGame(entity *aztables.EDMEntity) models.Game {
 	return models.Game{
 		ID:          entity.RowKey,
@@ -1064,6 +3095,8 @@ func createGame(entity *aztables.EDMEntity) models.Game {
 		WinMethod:   propString(entity, "WinMethod"),
 		ImageLink:   propString(entity, "ImageLink"),
 		Links:       createLinks(entity),
+
+		UploadedImage: optionalPropString(entity, "UploadedImage", ""),
 	}
 }
 
@@ -1087,6 +3120,10 @@ func createGroup(entity *aztables.EDMEntity) models.Group {
 		ProfilePicture: propString(entity, "ProfilePicture"),
 		Visibility:     models.GroupVisibilityName(propString(entity, "Visibility")),
 		CreatedBy:      propString(entity, "CreatedBy"),
+		Archived:       optionalPropBool(entity, "Archived", false),
+		TimeArchived:   optionalPropInt64(entity, "TimeArchived", 0),
+
+		UploadedPicture: optionalPropString(entity, "UploadedPicture", ""),
 	}
 }
 
@@ -1120,6 +3157,38 @@ func createLinkType(entity *aztables.EDMEntity) models.LinkType {
 	}
 }
 
+func createNotification(entity *aztables.EDMEntity) models.Notification {
+	return models.Notification{
+		ID:           entity.RowKey,
+		Username:     propString(entity, "Username"),
+		TimeCreated:  propInt64(entity, "TimeCreated"),
+		Type:         models.NotificationType(propString(entity, "Type")),
+		FromUsername: propString(entity, "FromUsername"),
+		GroupID:      propString(entity, "GroupID"),
+		ResultID:     propString(entity, "ResultID"),
+		InvitationID: propString(entity, "InvitationID"),
+		Read:         propBool(entity, "Read"),
+		TimeRead:     propInt64(entity, "TimeRead"),
+	}
+}
+
+func createOutboxMessage(entity *aztables.EDMEntity) models.OutboxMessage {
+	return models.OutboxMessage{
+		ID:              entity.RowKey,
+		TimeCreated:     propInt64(entity, "TimeCreated"),
+		Type:            propString(entity, "Type"),
+		To:              propString(entity, "To"),
+		Subject:         propString(entity, "Subject"),
+		TextBody:        propString(entity, "TextBody"),
+		HTMLBody:        propString(entity, "HTMLBody"),
+		Status:          models.DeliveryStatus(propString(entity, "Status")),
+		Attempts:        propInt(entity, "Attempts"),
+		TimeNextAttempt: propInt64(entity, "TimeNextAttempt"),
+		TimeSent:        propInt64(entity, "TimeSent"),
+		LastError:       propString(entity, "LastError"),
+	}
+}
+
 func createPlayer(entity *aztables.EDMEntity) models.Player {
 	return models.Player{
 		ID:             entity.RowKey,
@@ -1130,6 +3199,16 @@ func createPlayer(entity *aztables.EDMEntity) models.Player {
 	}
 }
 
+func createReaction(entity *aztables.EDMEntity) models.Reaction {
+	return models.Reaction{
+		ID:          entity.RowKey,
+		ResultID:    propString(entity, "ResultID"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		Username:    propString(entity, "Username"),
+		Emoji:       propString(entity, "Emoji"),
+	}
+}
+
 func createResult(entity *aztables.EDMEntity) models.Result {
 	return models.Result{
 		ID:               entity.RowKey,
@@ -1141,6 +3220,7 @@ func createResult(entity *aztables.EDMEntity) models.Result {
 		CooperativeScore: propInt(entity, "CooperativeScore"),
 		CooperativeWin:   propBool(entity, "CooperativeWin"),
 		Scores:           createScores(entity),
+		TimeLastReminded: optionalPropInt64(entity, "TimeLastReminded", 0),
 	}
 }
 
@@ -1162,7 +3242,238 @@ func createUser(entity *aztables.EDMEntity) models.User {
 		TimeCreated: propInt64(entity, "TimeCreated"),
 		Email:       propString(entity, "Email"),
 		Password:    propString(entity, "Password"),
-		Permissions: strings.Split(propString(entity, "Permissions"), ";"),
+		Permissions: splitNonEmpty(propString(entity, "Permissions")),
+		Roles:       splitNonEmpty(optionalPropString(entity, "Roles", "")),
+		Disabled:    optionalPropBool(entity, "Disabled", false),
+
+		DisplayName:     optionalPropString(entity, "DisplayName", ""),
+		ProfilePicture:  optionalPropString(entity, "ProfilePicture", ""),
+		UploadedPicture: optionalPropString(entity, "UploadedPicture", ""),
+
+		// users from before email verification existed are trusted
+		EmailVerified: optionalPropBool(entity, "EmailVerified", true),
+
+		// users from before this existed are assumed to know their password
+		HasPassword: optionalPropBool(entity, "HasPassword", true),
+
+		TwoFactor: models.TwoFactorSettings{
+			Enabled:       optionalPropBool(entity, "TwoFactorEnabled", false),
+			Secret:        optionalPropString(entity, "TwoFactorSecret", ""),
+			LastStep:      optionalPropInt64(entity, "TwoFactorLastStep", 0),
+			RecoveryCodes: splitNonEmpty(optionalPropString(entity, "TwoFactorRecoveryCodes", "")),
+		},
+
+		// users from before email preferences existed get the defaults
+		EmailPreferences: models.EmailPreferences{
+			Invitations:       optionalPropBool(entity, "EmailInvitations", true),
+			ApprovalReminders: optionalPropBool(entity, "EmailApprovalReminders", true),
+		},
+
+		FriendRestrictions: models.FriendRestrictions{
+			InvitationsFromFriendsOnly: optionalPropBool(entity, "InvitationsFromFriendsOnly", false),
+			ResultsFromFriendsOnly:     optionalPropBool(entity, "ResultsFromFriendsOnly", false),
+		},
+
+		// users from before privacy settings existed get the defaults
+		Privacy: models.PrivacySettings{
+			ProfileVisibility:        models.ProfileVisibility(optionalPropString(entity, "ProfileVisibility", string(models.VisibleToEveryone))),
+			ResultsNeedApproval:      optionalPropBool(entity, "ResultsNeedApproval", false),
+			ShowInPublicLeaderboards: optionalPropBool(entity, "ShowInPublicLeaderboards", true),
+		},
+	}
+}
+
+func createMergeJob(entity *aztables.EDMEntity) models.MergeJob {
+	return models.MergeJob{
+		ID:                entity.RowKey,
+		FromUsername:      propString(entity, "FromUsername"),
+		ToUsername:        propString(entity, "ToUsername"),
+		GroupID:           propString(entity, "GroupID"),
+		CreatedBy:         propString(entity, "CreatedBy"),
+		TimeCreated:       propInt64(entity, "TimeCreated"),
+		TimeCompleted:     propInt64(entity, "TimeCompleted"),
+		Status:            models.MergeStatus(propString(entity, "Status")),
+		Phase:             models.MergePhase(propString(entity, "Phase")),
+		LastError:         propString(entity, "LastError"),
+		ResultsMerged:     propInt(entity, "ResultsMerged"),
+		ApprovalsMerged:   propInt(entity, "ApprovalsMerged"),
+		MembershipsMerged: propInt(entity, "MembershipsMerged"),
+		InvitationsMerged: propInt(entity, "InvitationsMerged"),
+	}
+}
+
+func createComment(entity *aztables.EDMEntity) models.Comment {
+	return models.Comment{
+		ID:          entity.RowKey,
+		ResultID:    propString(entity, "ResultID"),
+		ParentID:    propString(entity, "ParentID"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		Username:    propString(entity, "Username"),
+		Text:        propString(entity, "Text"),
+		TimeEdited:  propInt64(entity, "TimeEdited"),
+		Deleted:     propBool(entity, "Deleted"),
+		DeletedBy:   propString(entity, "DeletedBy"),
+	}
+}
+
+func createFriendship(entity *aztables.EDMEntity) models.Friendship {
+	return models.Friendship{
+		ID:             entity.RowKey,
+		Username:       propString(entity, "Username"),
+		FriendUsername: propString(entity, "FriendUsername"),
+		TimeCreated:    propInt64(entity, "TimeCreated"),
+		Status:         models.FriendshipStatus(propString(entity, "Status")),
+		TimeAccepted:   propInt64(entity, "TimeAccepted"),
+	}
+}
+
+func createGuestPlayer(entity *aztables.EDMEntity) models.GuestPlayer {
+	return models.GuestPlayer{
+		ID:          entity.RowKey,
+		GroupID:     propString(entity, "GroupID"),
+		Username:    propString(entity, "Username"),
+		DisplayName: propString(entity, "DisplayName"),
+		CreatedBy:   propString(entity, "CreatedBy"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		ClaimedBy:   propString(entity, "ClaimedBy"),
+		TimeClaimed: propInt64(entity, "TimeClaimed"),
+	}
+}
+
+func createAuditEntry(entity *aztables.EDMEntity) models.AuditEntry {
+	return models.AuditEntry{
+		ID:             entity.RowKey,
+		TimeCreated:    propInt64(entity, "TimeCreated"),
+		Type:           models.AuditType(propString(entity, "Type")),
+		Username:       propString(entity, "Username"),
+		TargetUsername: propString(entity, "TargetUsername"),
+		IPAddress:      propString(entity, "IPAddress"),
+		Details:        propString(entity, "Details"),
+	}
+}
+
+func createExternalIdentity(entity *aztables.EDMEntity) models.ExternalIdentity {
+	return models.ExternalIdentity{
+		ID:            entity.RowKey,
+		Username:      propString(entity, "Username"),
+		Issuer:        propString(entity, "Issuer"),
+		Subject:       propString(entity, "Subject"),
+		Email:         propString(entity, "Email"),
+		TimeCreated:   propInt64(entity, "TimeCreated"),
+		TimeLastLogin: propInt64(entity, "TimeLastLogin"),
+	}
+}
+
+func createLoginState(entity *aztables.EDMEntity) models.LoginState {
+	return models.LoginState{
+		ID:           entity.RowKey,
+		StateHash:    propString(entity, "StateHash"),
+		Nonce:        propString(entity, "Nonce"),
+		CodeVerifier: propString(entity, "CodeVerifier"),
+		LinkUsername: propString(entity, "LinkUsername"),
+		TimeCreated:  propInt64(entity, "TimeCreated"),
+		TimeExpires:  propInt64(entity, "TimeExpires"),
+		Used:         propBool(entity, "Used"),
+	}
+}
+
+func createLoginThrottle(entity *aztables.EDMEntity) models.LoginThrottle {
+	return models.LoginThrottle{
+		Kind:            models.ThrottleKind(entity.PartitionKey),
+		Key:             entity.RowKey,
+		FailedAttempts:  propInt(entity, "FailedAttempts"),
+		TimeLastFailure: propInt64(entity, "TimeLastFailure"),
+		TimeLockedUntil: propInt64(entity, "TimeLockedUntil"),
+		ETag:            entity.ETag,
+	}
+}
+
+func createAPIKey(entity *aztables.EDMEntity) models.APIKey {
+	scopes := []models.APIKeyScope{}
+	for _, s := range strings.Split(propString(entity, "Scopes"), ";") {
+		if len(s) > 0 {
+			scopes = append(scopes, models.APIKeyScope(s))
+		}
+	}
+
+	return models.APIKey{
+		ID:           entity.RowKey,
+		Username:     propString(entity, "Username"),
+		Name:         propString(entity, "Name"),
+		Prefix:       propString(entity, "Prefix"),
+		KeyHash:      propString(entity, "KeyHash"),
+		Scopes:       scopes,
+		GroupID:      propString(entity, "GroupID"),
+		TimeCreated:  propInt64(entity, "TimeCreated"),
+		TimeLastUsed: propInt64(entity, "TimeLastUsed"),
+		Revoked:      propBool(entity, "Revoked"),
+		TimeRevoked:  propInt64(entity, "TimeRevoked"),
+	}
+}
+
+func createSession(entity *aztables.EDMEntity) models.Session {
+	return models.Session{
+		ID:           entity.RowKey,
+		Username:     propString(entity, "Username"),
+		TimeCreated:  propInt64(entity, "TimeCreated"),
+		TimeLastUsed: propInt64(entity, "TimeLastUsed"),
+		TimeExpires:  propInt64(entity, "TimeExpires"),
+		UserAgent:    propString(entity, "UserAgent"),
+		IPAddress:    propString(entity, "IPAddress"),
+		Revoked:      propBool(entity, "Revoked"),
+		TimeRevoked:  propInt64(entity, "TimeRevoked"),
+	}
+}
+
+func createUserToken(entity *aztables.EDMEntity) models.UserToken {
+	return models.UserToken{
+		ID:          entity.RowKey,
+		Username:    propString(entity, "Username"),
+		Purpose:     models.TokenPurpose(propString(entity, "Purpose")),
+		TokenHash:   propString(entity, "TokenHash"),
+		Data:        propString(entity, "Data"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		TimeExpires: propInt64(entity, "TimeExpires"),
+		Used:        propBool(entity, "Used"),
+		TimeUsed:    propInt64(entity, "TimeUsed"),
+		ETag:        entity.ETag,
+	}
+}
+
+func createWebhook(entity *aztables.EDMEntity) models.Webhook {
+	events := []models.WebhookEventType{}
+	for _, e := range strings.Split(propString(entity, "Events"), ";") {
+		if len(e) > 0 {
+			events = append(events, models.WebhookEventType(e))
+		}
+	}
+
+	return models.Webhook{
+		ID:          entity.RowKey,
+		GroupID:     propString(entity, "GroupID"),
+		TimeCreated: propInt64(entity, "TimeCreated"),
+		CreatedBy:   propString(entity, "CreatedBy"),
+		URL:         propString(entity, "URL"),
+		Secret:      propString(entity, "Secret"),
+		Events:      events,
+	}
+}
+
+func createWebhookDelivery(entity *aztables.EDMEntity) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:              entity.RowKey,
+		WebhookID:       propString(entity, "WebhookID"),
+		GroupID:         propString(entity, "GroupID"),
+		TimeCreated:     propInt64(entity, "TimeCreated"),
+		EventType:       models.WebhookEventType(propString(entity, "EventType")),
+		Payload:         propString(entity, "Payload"),
+		Status:          models.DeliveryStatus(propString(entity, "Status")),
+		Attempts:        propInt(entity, "Attempts"),
+		TimeLastAttempt: propInt64(entity, "TimeLastAttempt"),
+		TimeNextAttempt: optionalPropInt64(entity, "TimeNextAttempt", 0),
+		ResponseStatus:  propInt(entity, "ResponseStatus"),
+		LastError:       propString(entity, "LastError"),
+		ReplayOf:        propString(entity, "ReplayOf"),
 	}
 }
 
@@ -1190,3 +3501,39 @@ func propInt(entity *aztables.EDMEntity, name string) int {
 func propInt64(entity *aztables.EDMEntity, name string) int64 {
 	return int64(entity.Properties[name].(aztables.EDMInt64))
 }
+
+// the optionalProp functions are for columns that were added after the table was
+// first populated, so older entities may not have them
+
+func optionalPropBool(entity *aztables.EDMEntity, name string, defaultValue bool) bool {
+	if value, ok := entity.Properties[name].(bool); ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+func optionalPropInt64(entity *aztables.EDMEntity, name string, defaultValue int64) int64 {
+	if value, ok := entity.Properties[name].(aztables.EDMInt64); ok {
+		return int64(value)
+	}
+
+	return defaultValue
+}
+
+func optionalPropString(entity *aztables.EDMEntity, name string, defaultValue string) string {
+	if value, ok := entity.Properties[name].(string); ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// splits a ";"-joined column, where an empty string means an empty list
+func splitNonEmpty(value string) []string {
+	if len(value) <= 0 {
+		return []string{}
+	}
+
+	return strings.Split(value, ";")
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetComments(ctx context.Context, resultId string) (bool, []models.Comment) {
+	return d.findComments(ctx, bson.D{{"resultId", resultId}})
+}
+
+func (d *MongoDatabase) GetCommentsByUser(ctx context.Context, username string) (bool, []models.Comment) {
+	return d.findComments(ctx, bson.D{{"username", username}})
+}
+
+func (d *MongoDatabase) findComments(ctx context.Context, filter bson.D) (bool, []models.Comment) {
+	cursor, err := d.Database.Collection("Comments").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var comments []models.Comment
+
+	err = cursor.All(ctx, &comments)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, comments
+}
+
+func (d *MongoDatabase) GetComment(ctx context.Context, resultId string, commentId string) (bool, *models.Comment) {
+	filter := bson.D{{"resultId", resultId}, {"id", commentId}}
+	result := d.Database.Collection("Comments").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var comment models.Comment
+
+	if err := result.Decode(&comment); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &comment
+}
+
+func (d *MongoDatabase) AddComment(ctx context.Context, newComment *models.Comment) bool {
+	newComment.ID = uuid.NewString()
+	newComment.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Comments").InsertOne(ctx, newComment)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateComment(ctx context.Context, comment *models.Comment) bool {
+	filter := bson.D{{"id", comment.ID}}
+	_, err := d.Database.Collection("Comments").ReplaceOne(ctx, filter, comment)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteComments(ctx context.Context, resultId string) (bool, int64) {
+	filter := bson.D{{"resultId", resultId}}
+	deleteResult, err := d.Database.Collection("Comments").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
+
+func (d *MongoDatabase) GetReactions(ctx context.Context, resultId string) (bool, []models.Reaction) {
+	return d.findReactions(ctx, bson.D{{"resultId", resultId}})
+}
+
+func (d *MongoDatabase) GetReactionsByUser(ctx context.Context, username string) (bool, []models.Reaction) {
+	return d.findReactions(ctx, bson.D{{"username", username}})
+}
+
+func (d *MongoDatabase) findReactions(ctx context.Context, filter bson.D) (bool, []models.Reaction) {
+	cursor, err := d.Database.Collection("Reactions").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var reactions []models.Reaction
+
+	err = cursor.All(ctx, &reactions)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, reactions
+}
+
+func (d *MongoDatabase) AddReaction(ctx context.Context, newReaction *models.Reaction) bool {
+	newReaction.ID = uuid.NewString()
+	newReaction.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Reactions").InsertOne(ctx, newReaction)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteReaction(ctx context.Context, resultId string, reactionId string) bool {
+	filter := bson.D{{"resultId", resultId}, {"id", reactionId}}
+	_, err := d.Database.Collection("Reactions").DeleteOne(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteReactions(ctx context.Context, resultId string) (bool, int64) {
+	filter := bson.D{{"resultId", resultId}}
+	deleteResult, err := d.Database.Collection("Reactions").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
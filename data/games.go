@@ -69,6 +69,18 @@ func (d *MongoDatabase) AddGame(ctx context.Context, newGame *models.Game) bool
 	return true
 }
 
+func (d *MongoDatabase) UpdateGame(ctx context.Context, game *models.Game) bool {
+	filter := bson.D{{"id", game.ID}}
+	_, err := d.Database.Collection("Games").ReplaceOne(ctx, filter, game)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
 func (d *MongoDatabase) DeleteGame(ctx context.Context, id string) bool {
 	filter := bson.D{{"id", id}}
 	_, err := d.Database.Collection("Games").DeleteOne(ctx, filter)
@@ -125,6 +125,18 @@ func (d *MongoDatabase) AddGroup(ctx context.Context, newGroup *models.Group) bo
 	return true
 }
 
+func (d *MongoDatabase) UpdateGroup(ctx context.Context, group *models.Group) bool {
+	filter := bson.D{{"id", group.ID}}
+	_, err := d.Database.Collection("Groups").ReplaceOne(ctx, filter, group)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
 func (d *MongoDatabase) DeleteGroup(ctx context.Context, id string) bool {
 	filter := bson.D{{"id", id}}
 	_, err := d.Database.Collection("Groups").DeleteOne(ctx, filter)
@@ -136,3 +148,128 @@ func (d *MongoDatabase) DeleteGroup(ctx context.Context, id string) bool {
 
 	return true
 }
+
+func (d *MongoDatabase) GetGroupMemberships(ctx context.Context, username string) (bool, []models.GroupMembership) {
+	filter := bson.D{{"username", username}}
+
+	cursor, err := d.Database.Collection("GroupMemberships").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var memberships []models.GroupMembership
+
+	err = cursor.All(ctx, &memberships)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, memberships
+}
+
+func (d *MongoDatabase) IsInGroup(ctx context.Context, groupId string, username string) bool {
+	filter := bson.D{{"groupId", groupId}, {"username", username}}
+	result := d.Database.Collection("GroupMemberships").FindOne(ctx, filter)
+	return result.Err() == nil
+}
+
+func (d *MongoDatabase) UpdateGroupMembership(ctx context.Context, membership *models.GroupMembership) bool {
+	filter := bson.D{{"id", membership.ID}}
+	_, err := d.Database.Collection("GroupMemberships").ReplaceOne(ctx, filter, membership)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteGroupMembership(ctx context.Context, groupId string, username string) bool {
+	filter := bson.D{{"groupId", groupId}, {"username", username}}
+	deleteResult, err := d.Database.Collection("GroupMemberships").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return deleteResult.DeletedCount > 0
+}
+
+func (d *MongoDatabase) GetGroupInvitationsInvolving(ctx context.Context, username string) (bool, []models.GroupInvitation) {
+	filter := bson.D{
+		{
+			"$or", bson.A{
+				bson.D{{"username", username}},
+				bson.D{{"inviterUsername", username}},
+			},
+		},
+	}
+
+	cursor, err := d.Database.Collection("GroupInvitations").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var invitations []models.GroupInvitation
+
+	err = cursor.All(ctx, &invitations)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, invitations
+}
+
+func (d *MongoDatabase) UpdateGroupInvitation(ctx context.Context, invitation *models.GroupInvitation) bool {
+	filter := bson.D{{"id", invitation.ID}}
+	_, err := d.Database.Collection("GroupInvitations").ReplaceOne(ctx, filter, invitation)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteGroupInvitation(ctx context.Context, invitationId string) bool {
+	filter := bson.D{{"id", invitationId}}
+	deleteResult, err := d.Database.Collection("GroupInvitations").DeleteOne(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return deleteResult.DeletedCount > 0
+}
+
+func (d *MongoDatabase) DeleteGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	filter := bson.D{{"groupId", groupId}}
+	deleteResult, err := d.Database.Collection("GroupMemberships").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
+
+func (d *MongoDatabase) DeleteGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	filter := bson.D{{"groupId", groupId}}
+	deleteResult, err := d.Database.Collection("GroupInvitations").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
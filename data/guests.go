@@ -0,0 +1,19 @@
+package data
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) DeleteGuestPlayersForGroup(ctx context.Context, groupId string) (bool, int64) {
+	filter := bson.D{{"groupId", groupId}}
+	deleteResult, err := d.Database.Collection("GuestPlayers").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
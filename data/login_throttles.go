@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoLoginThrottle is how a throttle is stored in MongoDB. The ID is derived from the kind
+// and key so that the same throttle can't be added twice, and the version is bumped on
+// every write so that SaveLoginThrottle can tell whether the throttle has changed
+type mongoLoginThrottle struct {
+	ID                   string `bson:"_id"`
+	Version              int64  `bson:"version"`
+	models.LoginThrottle `bson:",inline"`
+}
+
+func loginThrottleId(kind models.ThrottleKind, key string) string {
+	return string(kind) + ":" + key
+}
+
+func (d *MongoDatabase) GetLoginThrottle(ctx context.Context, kind models.ThrottleKind, key string) (bool, *models.LoginThrottle) {
+	filter := bson.D{{"_id", loginThrottleId(kind, key)}}
+	result := d.Database.Collection("LoginThrottles").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		if err != mongo.ErrNoDocuments {
+			Error.Println(err)
+		}
+
+		return false, nil
+	}
+
+	var stored mongoLoginThrottle
+
+	if err := result.Decode(&stored); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	throttle := stored.LoginThrottle
+	throttle.ETag = strconv.FormatInt(stored.Version, 10)
+
+	return true, &throttle
+}
+
+func (d *MongoDatabase) UpsertLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool {
+	filter := bson.D{{"_id", loginThrottleId(throttle.Kind, throttle.Key)}}
+	update := bson.D{
+		{"$set", throttle},
+		{"$inc", bson.D{{"version", 1}}},
+	}
+
+	_, err := d.Database.Collection("LoginThrottles").UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) SaveLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool {
+	collection := d.Database.Collection("LoginThrottles")
+
+	// a throttle that wasn't read yet can only be added if nobody else has added it
+	if len(throttle.ETag) <= 0 {
+		stored := mongoLoginThrottle{
+			ID:            loginThrottleId(throttle.Kind, throttle.Key),
+			Version:       1,
+			LoginThrottle: *throttle,
+		}
+
+		_, err := collection.InsertOne(ctx, stored)
+
+		if err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				Error.Println(err)
+			}
+
+			return false
+		}
+
+		throttle.ETag = strconv.FormatInt(stored.Version, 10)
+		return true
+	}
+
+	version, err := strconv.ParseInt(throttle.ETag, 10, 64)
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	filter := bson.D{{"_id", loginThrottleId(throttle.Kind, throttle.Key)}, {"version", version}}
+	update := bson.D{
+		{"$set", throttle},
+		{"$inc", bson.D{{"version", 1}}},
+	}
+
+	updateResult, err := collection.UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	if updateResult.MatchedCount <= 0 {
+		return false
+	}
+
+	throttle.ETag = strconv.FormatInt(version+1, 10)
+	return true
+}
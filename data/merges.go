@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetMergeJobs(ctx context.Context) (bool, []models.MergeJob) {
+	cursor, err := d.Database.Collection("MergeJobs").Find(ctx, bson.D{})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var jobs []models.MergeJob
+
+	err = cursor.All(ctx, &jobs)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, jobs
+}
+
+func (d *MongoDatabase) GetMergeJob(ctx context.Context, jobId string) (bool, *models.MergeJob) {
+	filter := bson.D{{"id", jobId}}
+	result := d.Database.Collection("MergeJobs").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var job models.MergeJob
+
+	if err := result.Decode(&job); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &job
+}
+
+func (d *MongoDatabase) AddMergeJob(ctx context.Context, newJob *models.MergeJob) bool {
+	newJob.ID = uuid.NewString()
+	newJob.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("MergeJobs").InsertOne(ctx, newJob)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateMergeJob(ctx context.Context, job *models.MergeJob) bool {
+	filter := bson.D{{"id", job.ID}}
+	_, err := d.Database.Collection("MergeJobs").ReplaceOne(ctx, filter, job)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
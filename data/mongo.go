@@ -13,128 +13,163 @@ type MongoDatabase struct {
 	Database *mongo.Database
 }
 
-// GetResultsForGroupAndGame implements IDatabase
-func (*MongoDatabase) GetResultsForGroupAndGame(ctx context.Context, groupId string, gameId string) (bool, []models.Result) {
+// GetExternalIdentity implements IDatabase
+func (*MongoDatabase) GetExternalIdentity(ctx context.Context, issuer string, subject string) (bool, *models.ExternalIdentity) {
 	panic("unimplemented")
 }
 
-// UpdateUser implements IDatabase
-func (*MongoDatabase) UpdateUser(ctx context.Context, user *models.User) bool {
+// GetExternalIdentities implements IDatabase
+func (*MongoDatabase) GetExternalIdentities(ctx context.Context, username string) (bool, []models.ExternalIdentity) {
 	panic("unimplemented")
 }
 
-// UpdatePlayer implements IDatabase
-func (*MongoDatabase) UpdatePlayer(ctx context.Context, player *models.Player) bool {
+// AddExternalIdentity implements IDatabase
+func (*MongoDatabase) AddExternalIdentity(ctx context.Context, newIdentity *models.ExternalIdentity) bool {
 	panic("unimplemented")
 }
 
-// IsInvitedToGroup implements IDatabase
-func (*MongoDatabase) IsInvitedToGroup(ctx context.Context, groupId string, username string) bool {
+// UpdateExternalIdentity implements IDatabase
+func (*MongoDatabase) UpdateExternalIdentity(ctx context.Context, identity *models.ExternalIdentity) bool {
 	panic("unimplemented")
 }
 
-// UpdateGroupInvitation implements IDatabase
-func (*MongoDatabase) UpdateGroupInvitation(ctx context.Context, newGroupInvitation *models.GroupInvitation) bool {
+// DeleteExternalIdentity implements IDatabase
+func (*MongoDatabase) DeleteExternalIdentity(ctx context.Context, username string, identityId string) bool {
 	panic("unimplemented")
 }
 
-// GetGroupInvitation implements IDatabase
-func (*MongoDatabase) GetGroupInvitation(ctx context.Context, invitationId string) (bool, *models.GroupInvitation) {
+// GetLoginStateByHash implements IDatabase
+func (*MongoDatabase) GetLoginStateByHash(ctx context.Context, stateHash string) (bool, *models.LoginState) {
 	panic("unimplemented")
 }
 
-// AddGroupInvitation implements IDatabase
-func (*MongoDatabase) AddGroupInvitation(ctx context.Context, newGroupInvitation *models.GroupInvitation) bool {
+// AddLoginState implements IDatabase
+func (*MongoDatabase) AddLoginState(ctx context.Context, newState *models.LoginState) bool {
 	panic("unimplemented")
 }
 
-// GetGroupInvitations implements IDatabase
-func (*MongoDatabase) GetGroupInvitations(ctx context.Context, username string) (bool, []models.GroupInvitation) {
+// UpdateLoginState implements IDatabase
+func (*MongoDatabase) UpdateLoginState(ctx context.Context, state *models.LoginState) bool {
 	panic("unimplemented")
 }
 
-// GetGroupInvitationsForGroup implements IDatabase
-func (*MongoDatabase) GetGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, []models.GroupInvitation) {
+// GetFriendships implements IDatabase
+func (*MongoDatabase) GetFriendships(ctx context.Context, username string) (bool, []models.Friendship) {
 	panic("unimplemented")
 }
 
-// GetResultsForGroup implements IDatabase
-func (*MongoDatabase) GetResultsForGroup(ctx context.Context, groupId string) (bool, []models.Result) {
+// GetFriendship implements IDatabase
+func (*MongoDatabase) GetFriendship(ctx context.Context, friendshipId string) (bool, *models.Friendship) {
 	panic("unimplemented")
 }
 
-// GetGroupMembershipsForGroup implements IDatabase
-func (*MongoDatabase) GetGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, []models.GroupMembership) {
+// AddFriendship implements IDatabase
+func (*MongoDatabase) AddFriendship(ctx context.Context, newFriendship *models.Friendship) bool {
 	panic("unimplemented")
 }
 
-// GetPlayersInGroup implements IDatabase
-func (*MongoDatabase) GetPlayersInGroup(ctx context.Context, groupId string) (bool, []models.Player) {
+// UpdateFriendship implements IDatabase
+func (*MongoDatabase) UpdateFriendship(ctx context.Context, friendship *models.Friendship) bool {
 	panic("unimplemented")
 }
 
-// IsInGroup implements IDatabase
-func (*MongoDatabase) IsInGroup(ctx context.Context, groupId string, username string) bool {
+// DeleteFriendship implements IDatabase
+func (*MongoDatabase) DeleteFriendship(ctx context.Context, friendshipId string) bool {
 	panic("unimplemented")
 }
 
-// AddGroupMembership implements IDatabase
-func (*MongoDatabase) AddGroupMembership(ctx context.Context, newGroupMembership *models.GroupMembership) bool {
+// GetGuestPlayers implements IDatabase
+func (*MongoDatabase) GetGuestPlayers(ctx context.Context, groupId string) (bool, []models.GuestPlayer) {
 	panic("unimplemented")
 }
 
-// GetGroupMemberships implements IDatabase
-func (*MongoDatabase) GetGroupMemberships(ctx context.Context, username string) (bool, []models.GroupMembership) {
+// GetGuestPlayer implements IDatabase
+func (*MongoDatabase) GetGuestPlayer(ctx context.Context, username string) (bool, *models.GuestPlayer) {
 	panic("unimplemented")
 }
 
-// UserExistsByEmail implements IDatabase
-func (*MongoDatabase) UserExistsByEmail(ctx context.Context, email string) bool {
+// AddGuestPlayer implements IDatabase
+func (*MongoDatabase) AddGuestPlayer(ctx context.Context, newGuest *models.GuestPlayer) bool {
 	panic("unimplemented")
 }
 
-// GetResult implements IDatabase
-func (*MongoDatabase) GetResult(ctx context.Context, resultId string) (bool, *models.Result) {
+// UpdateGuestPlayer implements IDatabase
+func (*MongoDatabase) UpdateGuestPlayer(ctx context.Context, guest *models.GuestPlayer) bool {
 	panic("unimplemented")
 }
 
-// ResultExists implements IDatabase
-func (*MongoDatabase) ResultExists(ctx context.Context, resultId string) bool {
+// GetAPIKeys implements IDatabase
+func (*MongoDatabase) GetAPIKeys(ctx context.Context, username string) (bool, []models.APIKey) {
 	panic("unimplemented")
 }
 
-// GetApprovals implements IDatabase
-func (*MongoDatabase) GetApprovals(ctx context.Context, resultId string) (bool, []models.Approval) {
+// GetAPIKeyByHash implements IDatabase
+func (*MongoDatabase) GetAPIKeyByHash(ctx context.Context, keyHash string) (bool, *models.APIKey) {
 	panic("unimplemented")
 }
 
-// AddApproval implements IDatabase
-func (*MongoDatabase) AddApproval(ctx context.Context, newApproval *models.Approval) bool {
+// AddAPIKey implements IDatabase
+func (*MongoDatabase) AddAPIKey(ctx context.Context, newKey *models.APIKey) bool {
+	panic("unimplemented")
+}
+
+// UpdateAPIKey implements IDatabase
+func (*MongoDatabase) UpdateAPIKey(ctx context.Context, key *models.APIKey) bool {
 	panic("unimplemented")
 }
 
-// GetResultsWithPlayer implements IDatabase
-func (*MongoDatabase) GetResultsWithPlayer(ctx context.Context, username string) (bool, []models.Result) {
+// GetResultsForGroupAndGame implements IDatabase
+func (*MongoDatabase) GetResultsForGroupAndGame(ctx context.Context, groupId string, gameId string) (bool, []models.Result) {
 	panic("unimplemented")
 }
 
-// UserExists implements IDatabase
-func (*MongoDatabase) UserExists(ctx context.Context, email string) bool {
+// IsInvitedToGroup implements IDatabase
+func (*MongoDatabase) IsInvitedToGroup(ctx context.Context, groupId string, username string) bool {
 	panic("unimplemented")
 }
 
-// GetUser implements IDatabase
-func (*MongoDatabase) GetUser(ctx context.Context, username string) (bool, *models.User) {
+// GetGroupInvitation implements IDatabase
+func (*MongoDatabase) GetGroupInvitation(ctx context.Context, invitationId string) (bool, *models.GroupInvitation) {
 	panic("unimplemented")
 }
 
-// GetUserByEmail implements IDatabase
-func (*MongoDatabase) GetUserByEmail(ctx context.Context, email string) (bool, *models.User) {
+// AddGroupInvitation implements IDatabase
+func (*MongoDatabase) AddGroupInvitation(ctx context.Context, newGroupInvitation *models.GroupInvitation) bool {
 	panic("unimplemented")
 }
 
-// AddUser implements IDatabase
-func (*MongoDatabase) AddUser(ctx context.Context, newUser *models.User) bool {
+// GetGroupInvitations implements IDatabase
+func (*MongoDatabase) GetGroupInvitations(ctx context.Context, username string) (bool, []models.GroupInvitation) {
+	panic("unimplemented")
+}
+
+// GetGroupInvitationsForGroup implements IDatabase
+func (*MongoDatabase) GetGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, []models.GroupInvitation) {
+	panic("unimplemented")
+}
+
+// GetGroupMembershipsForGroup implements IDatabase
+func (*MongoDatabase) GetGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, []models.GroupMembership) {
+	panic("unimplemented")
+}
+
+// AddGroupMembership implements IDatabase
+func (*MongoDatabase) AddGroupMembership(ctx context.Context, newGroupMembership *models.GroupMembership) bool {
+	panic("unimplemented")
+}
+
+// ResultExists implements IDatabase
+func (*MongoDatabase) ResultExists(ctx context.Context, resultId string) bool {
+	panic("unimplemented")
+}
+
+// GetApprovals implements IDatabase
+func (*MongoDatabase) GetApprovals(ctx context.Context, resultId string) (bool, []models.Approval) {
+	panic("unimplemented")
+}
+
+// AddApproval implements IDatabase
+func (*MongoDatabase) AddApproval(ctx context.Context, newApproval *models.Approval) bool {
 	panic("unimplemented")
 }
 
@@ -161,7 +196,7 @@ func (d *MongoDatabase) GetSummary(ctx context.Context) (bool, *Summary) {
 		return false, nil
 	}
 
-	playerCount, err := d.Database.Collection("Players").CountDocuments(ctx, bson.D{})
+	playerCount, err := d.Database.Collection("Users").CountDocuments(ctx, bson.D{})
 	if err != nil {
 		Error.Println(err)
 		return false, nil
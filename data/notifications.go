@@ -0,0 +1,84 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetNotifications(ctx context.Context, username string) (bool, []models.Notification) {
+	cursor, err := d.Database.Collection("Notifications").Find(ctx, bson.D{{"username", username}})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var notifications []models.Notification
+
+	err = cursor.All(ctx, &notifications)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, notifications
+}
+
+func (d *MongoDatabase) GetNotification(ctx context.Context, notificationId string) (bool, *models.Notification) {
+	filter := bson.D{{"id", notificationId}}
+	result := d.Database.Collection("Notifications").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var notification models.Notification
+
+	if err := result.Decode(&notification); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &notification
+}
+
+func (d *MongoDatabase) AddNotification(ctx context.Context, newNotification *models.Notification) bool {
+	newNotification.ID = uuid.NewString()
+	newNotification.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Notifications").InsertOne(ctx, newNotification)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateNotification(ctx context.Context, notification *models.Notification) bool {
+	filter := bson.D{{"id", notification.ID}}
+	_, err := d.Database.Collection("Notifications").ReplaceOne(ctx, filter, notification)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteNotifications(ctx context.Context, username string) (bool, int64) {
+	filter := bson.D{{"username", username}}
+	deleteResult, err := d.Database.Collection("Notifications").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
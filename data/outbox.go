@@ -0,0 +1,55 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetPendingOutboxMessages(ctx context.Context) (bool, []models.OutboxMessage) {
+	filter := bson.D{{"status", models.DeliveryPending}}
+	cursor, err := d.Database.Collection("Outbox").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var messages []models.OutboxMessage
+
+	err = cursor.All(ctx, &messages)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, messages
+}
+
+func (d *MongoDatabase) AddOutboxMessage(ctx context.Context, newMessage *models.OutboxMessage) bool {
+	newMessage.ID = uuid.NewString()
+	newMessage.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Outbox").InsertOne(ctx, newMessage)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateOutboxMessage(ctx context.Context, message *models.OutboxMessage) bool {
+	filter := bson.D{{"id", message.ID}}
+	_, err := d.Database.Collection("Outbox").ReplaceOne(ctx, filter, message)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
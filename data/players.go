@@ -3,84 +3,103 @@ package data
 import (
 	"context"
 	"phrasmotica/bore-score-api/models"
-	"time"
 
-	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// the collection that players were kept in before they were merged into users
 func (d *MongoDatabase) players() *mongo.Collection {
 	return d.Database.Collection("Players")
 }
 
 func (d *MongoDatabase) GetAllPlayers(ctx context.Context) (bool, []models.Player) {
-	cursor, err := d.players().Find(ctx, bson.D{})
-	if err != nil {
-		Error.Println(err)
+	success, users := d.GetAllUsers(ctx)
+	if !success {
 		return false, nil
 	}
 
-	var players []models.Player
-
-	err = cursor.All(ctx, &players)
-	if err != nil {
-		Error.Println(err)
-		return false, nil
+	players := []models.Player{}
+	for _, u := range users {
+		players = append(players, u.ToPlayer())
 	}
 
 	return true, players
 }
 
-func (d *MongoDatabase) GetPlayer(ctx context.Context, username string) (bool, *models.Player) {
-	result := d.findPlayer(ctx, username)
-	if err := result.Err(); err != nil {
-		Error.Println(err)
-		return false, nil
-	}
-
-	var player models.Player
+// GetPlayersInGroup implements IDatabase
+func (*MongoDatabase) GetPlayersInGroup(ctx context.Context, groupId string) (bool, []models.Player) {
+	panic("unimplemented")
+}
 
-	if err := result.Decode(&player); err != nil {
-		Error.Println(err)
+func (d *MongoDatabase) GetPlayer(ctx context.Context, username string) (bool, *models.Player) {
+	success, user := d.GetUser(ctx, username)
+	if !success {
 		return false, nil
 	}
 
+	player := user.ToPlayer()
 	return true, &player
 }
 
 func (d *MongoDatabase) PlayerExists(ctx context.Context, username string) bool {
-	result := d.findPlayer(ctx, username)
-	return result.Err() == nil
+	return d.UserExists(ctx, username)
 }
 
-func (d *MongoDatabase) findPlayer(ctx context.Context, username string) *mongo.SingleResult {
-	filter := bson.D{{"username", username}}
-	return d.players().FindOne(ctx, filter)
-}
-
-func (d *MongoDatabase) AddPlayer(ctx context.Context, newPlayer *models.Player) bool {
-	newPlayer.ID = uuid.NewString()
-	newPlayer.TimeCreated = time.Now().UTC().Unix()
-
-	_, err := d.players().InsertOne(ctx, newPlayer)
-
+// MigratePlayers moves each document in the old Players collection into the user with
+// the same username, creating a user without an email address or password for players
+// that never had an account, and then deletes the document
+func (d *MongoDatabase) MigratePlayers(ctx context.Context) (bool, int64) {
+	cursor, err := d.players().Find(ctx, bson.D{})
 	if err != nil {
 		Error.Println(err)
-		return false
+		return false, 0
 	}
 
-	return true
-}
-
-func (d *MongoDatabase) DeletePlayer(ctx context.Context, username string) bool {
-	filter := bson.D{{"username", username}}
-	_, err := d.players().DeleteOne(ctx, filter)
+	var players []models.Player
 
+	err = cursor.All(ctx, &players)
 	if err != nil {
 		Error.Println(err)
-		return false
+		return false, 0
+	}
+
+	var migratedCount int64
+
+	for _, p := range players {
+		if success, user := d.GetUser(ctx, p.Username); success {
+			user.DisplayName = p.DisplayName
+			user.ProfilePicture = p.ProfilePicture
+
+			if success := d.UpdateUser(ctx, user); !success {
+				return false, migratedCount
+			}
+		} else {
+			newUser := models.User{
+				ID:             p.ID,
+				Username:       p.Username,
+				TimeCreated:    p.TimeCreated,
+				Permissions:    []string{},
+				DisplayName:    p.DisplayName,
+				ProfilePicture: p.ProfilePicture,
+
+				EmailPreferences: models.DefaultEmailPreferences(),
+				Privacy:          models.DefaultPrivacySettings(),
+			}
+
+			if success := d.AddUser(ctx, &newUser); !success {
+				return false, migratedCount
+			}
+		}
+
+		filter := bson.D{{"id", p.ID}}
+		if _, err := d.players().DeleteOne(ctx, filter); err != nil {
+			Error.Println(err)
+			return false, migratedCount
+		}
+
+		migratedCount++
 	}
 
-	return true
+	return true, migratedCount
 }
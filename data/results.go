@@ -27,6 +27,24 @@ func (d *MongoDatabase) GetAllResults(ctx context.Context) (bool, []models.Resul
 	return true, results
 }
 
+func (d *MongoDatabase) GetResultsForGroup(ctx context.Context, groupId string) (bool, []models.Result) {
+	cursor, err := d.Database.Collection("Results").Find(ctx, bson.D{{"groupId", groupId}})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var results []models.Result
+
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, results
+}
+
 func (d *MongoDatabase) AddResult(ctx context.Context, newResult *models.Result) bool {
 	newResult.ID = uuid.NewString()
 	newResult.TimeCreated = time.Now().UTC().Unix()
@@ -53,7 +71,23 @@ func (d *MongoDatabase) DeleteResultsWithGame(ctx context.Context, gameId string
 	return true, deleteResult.DeletedCount
 }
 
+func (d *MongoDatabase) DeleteResultsForGroup(ctx context.Context, groupId string) (bool, int64) {
+	filter := bson.D{{"groupId", groupId}}
+	deleteResult, err := d.Database.Collection("Results").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
+
 func (d *MongoDatabase) ScrubResultsWithPlayer(ctx context.Context, username string) (bool, int64) {
+	return d.RenamePlayerInResults(ctx, username, "")
+}
+
+func (d *MongoDatabase) RenamePlayerInResults(ctx context.Context, oldUsername string, newUsername string) (bool, int64) {
 	// filters to results where the given player took part
 	filter := bson.D{
 		{
@@ -61,7 +95,7 @@ func (d *MongoDatabase) ScrubResultsWithPlayer(ctx context.Context, username str
 				{
 					"$elemMatch", bson.D{
 						{
-							"username", username,
+							"username", oldUsername,
 						},
 					},
 				},
@@ -69,12 +103,12 @@ func (d *MongoDatabase) ScrubResultsWithPlayer(ctx context.Context, username str
 		},
 	}
 
-	// updates by setting the username field of the player's score object to an empty string
+	// updates by setting the username field of the player's score object to the new username
 	update := bson.D{
 		{
 			"$set", bson.D{
 				{
-					"scores.$.username", "",
+					"scores.$.username", newUsername,
 				},
 			},
 		},
@@ -89,3 +123,89 @@ func (d *MongoDatabase) ScrubResultsWithPlayer(ctx context.Context, username str
 
 	return true, result.ModifiedCount
 }
+
+func (d *MongoDatabase) GetResult(ctx context.Context, resultId string) (bool, *models.Result) {
+	filter := bson.D{{"id", resultId}}
+	result := d.Database.Collection("Results").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var r models.Result
+
+	if err := result.Decode(&r); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &r
+}
+
+func (d *MongoDatabase) GetResultsWithPlayer(ctx context.Context, username string) (bool, []models.Result) {
+	filter := bson.D{{"scores.username", username}}
+
+	cursor, err := d.Database.Collection("Results").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var results []models.Result
+
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, results
+}
+
+func (d *MongoDatabase) GetResultsWithGame(ctx context.Context, gameId string) (bool, []models.Result) {
+	filter := bson.D{{"gameId", gameId}}
+
+	cursor, err := d.Database.Collection("Results").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var results []models.Result
+
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, results
+}
+
+func (d *MongoDatabase) UpdateResultScores(ctx context.Context, result *models.Result) bool {
+	filter := bson.D{{"id", result.ID}}
+	update := bson.D{{"$set", bson.D{{"scores", result.Scores}}}}
+
+	_, err := d.Database.Collection("Results").UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateResultReminder(ctx context.Context, result *models.Result) bool {
+	filter := bson.D{{"id", result.ID}}
+	update := bson.D{{"$set", bson.D{{"timeLastReminded", result.TimeLastReminded}}}}
+
+	_, err := d.Database.Collection("Results").UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetSessions(ctx context.Context, username string) (bool, []models.Session) {
+	cursor, err := d.Database.Collection("Sessions").Find(ctx, bson.D{{"username", username}})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var sessions []models.Session
+
+	err = cursor.All(ctx, &sessions)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, sessions
+}
+
+func (d *MongoDatabase) GetSession(ctx context.Context, username string, sessionId string) (bool, *models.Session) {
+	filter := bson.D{{"username", username}, {"id", sessionId}}
+	result := d.Database.Collection("Sessions").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var session models.Session
+
+	if err := result.Decode(&session); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &session
+}
+
+func (d *MongoDatabase) AddSession(ctx context.Context, newSession *models.Session) bool {
+	newSession.ID = uuid.NewString()
+	newSession.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Sessions").InsertOne(ctx, newSession)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateSession(ctx context.Context, session *models.Session) bool {
+	filter := bson.D{{"id", session.ID}}
+	_, err := d.Database.Collection("Sessions").ReplaceOne(ctx, filter, session)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
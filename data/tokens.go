@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetUserTokenByHash(ctx context.Context, tokenHash string) (bool, *models.UserToken) {
+	filter := bson.D{{"tokenHash", tokenHash}}
+	result := d.Database.Collection("UserTokens").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var token models.UserToken
+
+	if err := result.Decode(&token); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &token
+}
+
+func (d *MongoDatabase) GetUserTokens(ctx context.Context, username string, purpose models.TokenPurpose) (bool, []models.UserToken) {
+	filter := bson.D{{"username", username}, {"purpose", purpose}}
+	cursor, err := d.Database.Collection("UserTokens").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var tokens []models.UserToken
+
+	err = cursor.All(ctx, &tokens)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, tokens
+}
+
+func (d *MongoDatabase) AddUserToken(ctx context.Context, newToken *models.UserToken) bool {
+	newToken.ID = uuid.NewString()
+	newToken.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("UserTokens").InsertOne(ctx, newToken)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateUserToken(ctx context.Context, token *models.UserToken) bool {
+	filter := bson.D{{"id", token.ID}}
+	_, err := d.Database.Collection("UserTokens").ReplaceOne(ctx, filter, token)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UseUserToken(ctx context.Context, token *models.UserToken) bool {
+	token.Used = true
+	token.TimeUsed = time.Now().UTC().Unix()
+
+	// only matches the token if it hasn't been used yet, so that it can only be used once
+	filter := bson.D{{"id", token.ID}, {"used", false}}
+	update := bson.D{{"$set", bson.D{{"used", token.Used}, {"timeUsed", token.TimeUsed}}}}
+
+	updateResult, err := d.Database.Collection("UserTokens").UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return updateResult.MatchedCount > 0
+}
+
+func (d *MongoDatabase) DeleteUserTokens(ctx context.Context, username string) (bool, int64) {
+	filter := bson.D{{"username", username}}
+	deleteResult, err := d.Database.Collection("UserTokens").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
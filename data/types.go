@@ -13,13 +13,40 @@ type Summary struct {
 }
 
 type IDatabase interface {
+	AddActivity(ctx context.Context, newActivity *models.Activity) bool
+	GetActivityForGroup(ctx context.Context, groupId string) (bool, []models.Activity)
+	ScrubActivityWithUser(ctx context.Context, username string) (bool, int64)
+	DeleteActivityForGroup(ctx context.Context, groupId string) (bool, int64)
+
 	AddApproval(ctx context.Context, newApproval *models.Approval) bool
 	GetApprovals(ctx context.Context, resultId string) (bool, []models.Approval)
+	GetApprovalsByUser(ctx context.Context, username string) (bool, []models.Approval)
+	UpdateApproval(ctx context.Context, approval *models.Approval) bool
+	DeleteApprovals(ctx context.Context, resultId string) (bool, int64)
+
+	GetAttachments(ctx context.Context, resultId string) (bool, []models.Attachment)
+	GetAttachment(ctx context.Context, resultId string, attachmentId string) (bool, *models.Attachment)
+	AddAttachment(ctx context.Context, newAttachment *models.Attachment) bool
+	DeleteAttachment(ctx context.Context, resultId string, attachmentId string) bool
+
+	GetComments(ctx context.Context, resultId string) (bool, []models.Comment)
+	GetCommentsByUser(ctx context.Context, username string) (bool, []models.Comment)
+	GetComment(ctx context.Context, resultId string, commentId string) (bool, *models.Comment)
+	AddComment(ctx context.Context, newComment *models.Comment) bool
+	UpdateComment(ctx context.Context, comment *models.Comment) bool
+	DeleteComments(ctx context.Context, resultId string) (bool, int64)
+
+	GetReactions(ctx context.Context, resultId string) (bool, []models.Reaction)
+	GetReactionsByUser(ctx context.Context, username string) (bool, []models.Reaction)
+	AddReaction(ctx context.Context, newReaction *models.Reaction) bool
+	DeleteReaction(ctx context.Context, resultId string, reactionId string) bool
+	DeleteReactions(ctx context.Context, resultId string) (bool, int64)
 
 	GetAllGames(ctx context.Context) (bool, []models.Game)
 	GetGame(ctx context.Context, id string) (bool, *models.Game)
 	GameExists(ctx context.Context, id string) bool
 	AddGame(ctx context.Context, newGame *models.Game) bool
+	UpdateGame(ctx context.Context, game *models.Game) bool
 	DeleteGame(ctx context.Context, id string) bool
 
 	GetAllGroups(ctx context.Context) (bool, []models.Group)
@@ -28,46 +55,133 @@ type IDatabase interface {
 	GetGroupByName(ctx context.Context, name string) (bool, *models.Group)
 	GroupExists(ctx context.Context, name string) bool
 	AddGroup(ctx context.Context, newGroup *models.Group) bool
+	UpdateGroup(ctx context.Context, group *models.Group) bool
 	DeleteGroup(ctx context.Context, id string) bool
 
 	GetGroupInvitation(ctx context.Context, invitationId string) (bool, *models.GroupInvitation)
 	GetGroupInvitations(ctx context.Context, username string) (bool, []models.GroupInvitation)
 	GetGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, []models.GroupInvitation)
+	GetGroupInvitationsInvolving(ctx context.Context, username string) (bool, []models.GroupInvitation)
 	IsInvitedToGroup(ctx context.Context, groupId string, username string) bool
 	AddGroupInvitation(ctx context.Context, newGroupInvitation *models.GroupInvitation) bool
 	UpdateGroupInvitation(ctx context.Context, newGroupInvitation *models.GroupInvitation) bool
+	DeleteGroupInvitation(ctx context.Context, invitationId string) bool
+	DeleteGroupInvitationsForGroup(ctx context.Context, groupId string) (bool, int64)
 
 	GetGroupMemberships(ctx context.Context, username string) (bool, []models.GroupMembership)
 	GetGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, []models.GroupMembership)
 	IsInGroup(ctx context.Context, groupId string, username string) bool
 	AddGroupMembership(ctx context.Context, newGroupMembership *models.GroupMembership) bool
+	UpdateGroupMembership(ctx context.Context, membership *models.GroupMembership) bool
+	DeleteGroupMembership(ctx context.Context, groupId string, username string) bool
+	DeleteGroupMembershipsForGroup(ctx context.Context, groupId string) (bool, int64)
 
 	GetAllLinkTypes(ctx context.Context) (bool, []models.LinkType)
 
+	GetNotifications(ctx context.Context, username string) (bool, []models.Notification)
+	GetNotification(ctx context.Context, notificationId string) (bool, *models.Notification)
+	AddNotification(ctx context.Context, newNotification *models.Notification) bool
+	UpdateNotification(ctx context.Context, notification *models.Notification) bool
+	DeleteNotifications(ctx context.Context, username string) (bool, int64)
+
+	GetPendingOutboxMessages(ctx context.Context) (bool, []models.OutboxMessage)
+	AddOutboxMessage(ctx context.Context, newMessage *models.OutboxMessage) bool
+	UpdateOutboxMessage(ctx context.Context, message *models.OutboxMessage) bool
+
 	GetAllPlayers(ctx context.Context) (bool, []models.Player)
 	GetPlayersInGroup(ctx context.Context, groupId string) (bool, []models.Player)
 	GetPlayer(ctx context.Context, username string) (bool, *models.Player)
 	PlayerExists(ctx context.Context, username string) bool
-	AddPlayer(ctx context.Context, newPlayer *models.Player) bool
-	UpdatePlayer(ctx context.Context, player *models.Player) bool
-	DeletePlayer(ctx context.Context, username string) bool
+	MigratePlayers(ctx context.Context) (bool, int64)
+
+	GetGuestPlayers(ctx context.Context, groupId string) (bool, []models.GuestPlayer)
+	GetGuestPlayer(ctx context.Context, username string) (bool, *models.GuestPlayer)
+	AddGuestPlayer(ctx context.Context, newGuest *models.GuestPlayer) bool
+	UpdateGuestPlayer(ctx context.Context, guest *models.GuestPlayer) bool
+	DeleteGuestPlayersForGroup(ctx context.Context, groupId string) (bool, int64)
 
 	GetAllResults(ctx context.Context) (bool, []models.Result)
 	GetResultsWithPlayer(ctx context.Context, username string) (bool, []models.Result)
+	GetResultsWithGame(ctx context.Context, gameId string) (bool, []models.Result)
 	GetResultsForGroup(ctx context.Context, groupId string) (bool, []models.Result)
 	GetResultsForGroupAndGame(ctx context.Context, groupId string, gameId string) (bool, []models.Result)
 	GetResult(ctx context.Context, resultId string) (bool, *models.Result)
 	ResultExists(ctx context.Context, resultId string) bool
 	AddResult(ctx context.Context, newResult *models.Result) bool
+	UpdateResultScores(ctx context.Context, result *models.Result) bool
+	UpdateResultReminder(ctx context.Context, result *models.Result) bool
 	DeleteResultsWithGame(ctx context.Context, gameId string) (bool, int64)
+	DeleteResultsForGroup(ctx context.Context, groupId string) (bool, int64)
 	ScrubResultsWithPlayer(ctx context.Context, username string) (bool, int64)
+	RenamePlayerInResults(ctx context.Context, oldUsername string, newUsername string) (bool, int64)
 
+	GetAllUsers(ctx context.Context) (bool, []models.User)
 	GetUser(ctx context.Context, username string) (bool, *models.User)
 	GetUserByEmail(ctx context.Context, email string) (bool, *models.User)
 	AddUser(ctx context.Context, newUser *models.User) bool
 	UserExists(ctx context.Context, username string) bool
 	UserExistsByEmail(ctx context.Context, email string) bool
 	UpdateUser(ctx context.Context, user *models.User) bool
+	DeleteUser(ctx context.Context, username string) bool
+	ReserveUsername(ctx context.Context, username string) bool
+	IsUsernameReserved(ctx context.Context, username string) bool
+
+	GetWebhooks(ctx context.Context, groupId string) (bool, []models.Webhook)
+	GetWebhook(ctx context.Context, webhookId string) (bool, *models.Webhook)
+	AddWebhook(ctx context.Context, newWebhook *models.Webhook) bool
+	DeleteWebhook(ctx context.Context, webhookId string) bool
+
+	GetWebhookDeliveries(ctx context.Context, webhookId string) (bool, []models.WebhookDelivery)
+	GetPendingWebhookDeliveries(ctx context.Context) (bool, []models.WebhookDelivery)
+	GetWebhookDelivery(ctx context.Context, deliveryId string) (bool, *models.WebhookDelivery)
+	AddWebhookDelivery(ctx context.Context, newDelivery *models.WebhookDelivery) bool
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) bool
+	DeleteWebhookDeliveries(ctx context.Context, webhookId string) (bool, int64)
+
+	GetUserTokenByHash(ctx context.Context, tokenHash string) (bool, *models.UserToken)
+	GetUserTokens(ctx context.Context, username string, purpose models.TokenPurpose) (bool, []models.UserToken)
+	AddUserToken(ctx context.Context, newToken *models.UserToken) bool
+	UpdateUserToken(ctx context.Context, token *models.UserToken) bool
+	UseUserToken(ctx context.Context, token *models.UserToken) bool
+	DeleteUserTokens(ctx context.Context, username string) (bool, int64)
+
+	GetSessions(ctx context.Context, username string) (bool, []models.Session)
+	GetSession(ctx context.Context, username string, sessionId string) (bool, *models.Session)
+	AddSession(ctx context.Context, newSession *models.Session) bool
+	UpdateSession(ctx context.Context, session *models.Session) bool
+
+	GetAPIKeys(ctx context.Context, username string) (bool, []models.APIKey)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (bool, *models.APIKey)
+	AddAPIKey(ctx context.Context, newKey *models.APIKey) bool
+	UpdateAPIKey(ctx context.Context, key *models.APIKey) bool
+
+	GetLoginThrottle(ctx context.Context, kind models.ThrottleKind, key string) (bool, *models.LoginThrottle)
+	UpsertLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool
+	SaveLoginThrottle(ctx context.Context, throttle *models.LoginThrottle) bool
+
+	GetMergeJobs(ctx context.Context) (bool, []models.MergeJob)
+	GetMergeJob(ctx context.Context, jobId string) (bool, *models.MergeJob)
+	AddMergeJob(ctx context.Context, newJob *models.MergeJob) bool
+	UpdateMergeJob(ctx context.Context, job *models.MergeJob) bool
+
+	GetAuditEntries(ctx context.Context) (bool, []models.AuditEntry)
+	AddAuditEntry(ctx context.Context, newEntry *models.AuditEntry) bool
+
+	GetExternalIdentity(ctx context.Context, issuer string, subject string) (bool, *models.ExternalIdentity)
+	GetExternalIdentities(ctx context.Context, username string) (bool, []models.ExternalIdentity)
+	AddExternalIdentity(ctx context.Context, newIdentity *models.ExternalIdentity) bool
+	UpdateExternalIdentity(ctx context.Context, identity *models.ExternalIdentity) bool
+	DeleteExternalIdentity(ctx context.Context, username string, identityId string) bool
+
+	GetLoginStateByHash(ctx context.Context, stateHash string) (bool, *models.LoginState)
+	AddLoginState(ctx context.Context, newState *models.LoginState) bool
+	UpdateLoginState(ctx context.Context, state *models.LoginState) bool
+
+	GetFriendships(ctx context.Context, username string) (bool, []models.Friendship)
+	GetFriendship(ctx context.Context, friendshipId string) (bool, *models.Friendship)
+	AddFriendship(ctx context.Context, newFriendship *models.Friendship) bool
+	UpdateFriendship(ctx context.Context, friendship *models.Friendship) bool
+	DeleteFriendship(ctx context.Context, friendshipId string) bool
 
 	GetAllWinMethods(ctx context.Context) (bool, []models.WinMethod)
 
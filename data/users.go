@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *MongoDatabase) users() *mongo.Collection {
+	return d.Database.Collection("Users")
+}
+
+func (d *MongoDatabase) GetAllUsers(ctx context.Context) (bool, []models.User) {
+	cursor, err := d.users().Find(ctx, bson.D{})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var users []models.User
+
+	for cursor.Next(ctx) {
+		user, err := unmarshalUser(cursor.Current)
+		if err != nil {
+			Error.Println(err)
+			return false, nil
+		}
+
+		users = append(users, *user)
+	}
+
+	if err := cursor.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, users
+}
+
+func (d *MongoDatabase) GetUser(ctx context.Context, username string) (bool, *models.User) {
+	return d.decodeUser(d.findUser(ctx, username))
+}
+
+func (d *MongoDatabase) GetUserByEmail(ctx context.Context, email string) (bool, *models.User) {
+	return d.decodeUser(d.findUserByEmail(ctx, email))
+}
+
+func (d *MongoDatabase) decodeUser(result *mongo.SingleResult) (bool, *models.User) {
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	raw, err := result.DecodeBytes()
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	user, err := unmarshalUser(raw)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, user
+}
+
+// decodes the user, filling in defaults for any settings that didn't exist when it was saved
+func unmarshalUser(raw bson.Raw) (*models.User, error) {
+	var user models.User
+
+	if err := bson.Unmarshal(raw, &user); err != nil {
+		return nil, err
+	}
+
+	// users from before email verification existed are trusted
+	if _, err := raw.LookupErr("emailVerified"); err != nil {
+		user.EmailVerified = true
+	}
+
+	// users from before this existed are assumed to know their password
+	if _, err := raw.LookupErr("hasPassword"); err != nil {
+		user.HasPassword = true
+	}
+
+	// users from before email preferences existed get the defaults
+	if _, err := raw.LookupErr("emailPreferences"); err != nil {
+		user.EmailPreferences = models.DefaultEmailPreferences()
+	}
+
+	// users from before privacy settings existed get the defaults
+	if _, err := raw.LookupErr("privacy"); err != nil {
+		user.Privacy = models.DefaultPrivacySettings()
+	}
+
+	return &user, nil
+}
+
+func (d *MongoDatabase) UserExists(ctx context.Context, username string) bool {
+	result := d.findUser(ctx, username)
+	return result.Err() == nil
+}
+
+func (d *MongoDatabase) UserExistsByEmail(ctx context.Context, email string) bool {
+	result := d.findUserByEmail(ctx, email)
+	return result.Err() == nil
+}
+
+func (d *MongoDatabase) findUser(ctx context.Context, username string) *mongo.SingleResult {
+	filter := bson.D{{"username", username}}
+	return d.users().FindOne(ctx, filter)
+}
+
+func (d *MongoDatabase) findUserByEmail(ctx context.Context, email string) *mongo.SingleResult {
+	filter := bson.D{{"email", email}}
+	return d.users().FindOne(ctx, filter)
+}
+
+func (d *MongoDatabase) AddUser(ctx context.Context, newUser *models.User) bool {
+	_, err := d.users().InsertOne(ctx, newUser)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateUser(ctx context.Context, user *models.User) bool {
+	filter := bson.D{{"id", user.ID}}
+	_, err := d.users().ReplaceOne(ctx, filter, user)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteUser(ctx context.Context, username string) bool {
+	filter := bson.D{{"username", username}}
+	_, err := d.users().DeleteOne(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) ReserveUsername(ctx context.Context, username string) bool {
+	filter := bson.D{{"username", username}}
+	update := bson.D{{"$set", bson.D{{"username", username}, {"timeReserved", time.Now().UTC().Unix()}}}}
+
+	_, err := d.Database.Collection("ReservedUsernames").UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) IsUsernameReserved(ctx context.Context, username string) bool {
+	filter := bson.D{{"username", username}}
+	result := d.Database.Collection("ReservedUsernames").FindOne(ctx, filter)
+	return result.Err() == nil
+}
@@ -0,0 +1,154 @@
+package data
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (d *MongoDatabase) GetWebhooks(ctx context.Context, groupId string) (bool, []models.Webhook) {
+	cursor, err := d.Database.Collection("Webhooks").Find(ctx, bson.D{{"groupId", groupId}})
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var webhooks []models.Webhook
+
+	err = cursor.All(ctx, &webhooks)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, webhooks
+}
+
+func (d *MongoDatabase) GetWebhook(ctx context.Context, webhookId string) (bool, *models.Webhook) {
+	filter := bson.D{{"id", webhookId}}
+	result := d.Database.Collection("Webhooks").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var webhook models.Webhook
+
+	if err := result.Decode(&webhook); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &webhook
+}
+
+func (d *MongoDatabase) AddWebhook(ctx context.Context, newWebhook *models.Webhook) bool {
+	newWebhook.ID = uuid.NewString()
+	newWebhook.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("Webhooks").InsertOne(ctx, newWebhook)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteWebhook(ctx context.Context, webhookId string) bool {
+	filter := bson.D{{"id", webhookId}}
+	_, err := d.Database.Collection("Webhooks").DeleteOne(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) GetWebhookDeliveries(ctx context.Context, webhookId string) (bool, []models.WebhookDelivery) {
+	return d.findWebhookDeliveries(ctx, bson.D{{"webhookId", webhookId}})
+}
+
+func (d *MongoDatabase) GetPendingWebhookDeliveries(ctx context.Context) (bool, []models.WebhookDelivery) {
+	return d.findWebhookDeliveries(ctx, bson.D{{"status", models.DeliveryPending}})
+}
+
+func (d *MongoDatabase) findWebhookDeliveries(ctx context.Context, filter bson.D) (bool, []models.WebhookDelivery) {
+	cursor, err := d.Database.Collection("WebhookDeliveries").Find(ctx, filter)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var deliveries []models.WebhookDelivery
+
+	err = cursor.All(ctx, &deliveries)
+	if err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, deliveries
+}
+
+func (d *MongoDatabase) GetWebhookDelivery(ctx context.Context, deliveryId string) (bool, *models.WebhookDelivery) {
+	filter := bson.D{{"id", deliveryId}}
+	result := d.Database.Collection("WebhookDeliveries").FindOne(ctx, filter)
+	if err := result.Err(); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	var delivery models.WebhookDelivery
+
+	if err := result.Decode(&delivery); err != nil {
+		Error.Println(err)
+		return false, nil
+	}
+
+	return true, &delivery
+}
+
+func (d *MongoDatabase) AddWebhookDelivery(ctx context.Context, newDelivery *models.WebhookDelivery) bool {
+	newDelivery.ID = uuid.NewString()
+	newDelivery.TimeCreated = time.Now().UTC().Unix()
+
+	_, err := d.Database.Collection("WebhookDeliveries").InsertOne(ctx, newDelivery)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	filter := bson.D{{"id", delivery.ID}}
+	_, err := d.Database.Collection("WebhookDeliveries").ReplaceOne(ctx, filter, delivery)
+
+	if err != nil {
+		Error.Println(err)
+		return false
+	}
+
+	return true
+}
+
+func (d *MongoDatabase) DeleteWebhookDeliveries(ctx context.Context, webhookId string) (bool, int64) {
+	filter := bson.D{{"webhookId", webhookId}}
+	deleteResult, err := d.Database.Collection("WebhookDeliveries").DeleteMany(ctx, filter)
+
+	if err != nil {
+		Error.Println(err)
+		return false, 0
+	}
+
+	return true, deleteResult.DeletedCount
+}
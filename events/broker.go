@@ -0,0 +1,91 @@
+package events
+
+import "sync"
+
+type Event struct {
+	Type    EventType   `json:"type"`
+	GroupID string      `json:"groupId"`
+	Data    interface{} `json:"data"`
+}
+
+type EventType string
+
+const (
+	ResultCreated     EventType = "result-created"
+	ApprovalAdded     EventType = "approval-added"
+	MembershipAdded   EventType = "membership-added"
+	MembershipRemoved EventType = "membership-removed"
+	CommentAdded      EventType = "comment-added"
+	ReactionAdded     EventType = "reaction-added"
+)
+
+// how many events a subscriber can fall behind by before further events are dropped for it
+const subscriberBufferSize = 16
+
+// Broker is an in-process pub/sub hub that fans events out to everyone
+// subscribed to the group they belong to
+type Broker struct {
+	mutex       sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: map[string]map[chan Event]struct{}{},
+	}
+}
+
+// Subscribe returns a channel that receives the group's events until Unsubscribe is called
+func (b *Broker) Subscribe(groupId string) chan Event {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[groupId]; !ok {
+		b.subscribers[groupId] = map[chan Event]struct{}{}
+	}
+
+	b.subscribers[groupId][ch] = struct{}{}
+
+	return ch
+}
+
+func (b *Broker) Unsubscribe(groupId string, ch chan Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if subscribers, ok := b.subscribers[groupId]; ok {
+		if _, ok := subscribers[ch]; ok {
+			delete(subscribers, ch)
+			close(ch)
+		}
+
+		if len(subscribers) <= 0 {
+			delete(b.subscribers, groupId)
+		}
+	}
+}
+
+// Publish sends the event to the group's subscribers without blocking. Subscribers
+// whose buffers are full miss the event, so a slow client can't hold up the handler
+// that published it
+func (b *Broker) Publish(event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers[event.GroupID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns how many subscribers the group currently has
+func (b *Broker) SubscriberCount(groupId string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.subscribers[groupId])
+}
@@ -0,0 +1,52 @@
+package events
+
+import "testing"
+
+func TestPublishOnlyReachesGroupSubscribers(t *testing.T) {
+	broker := NewBroker()
+
+	group1 := broker.Subscribe("group1")
+	group2 := broker.Subscribe("group2")
+
+	broker.Publish(Event{Type: ResultCreated, GroupID: "group1"})
+
+	if len(group1) != 1 {
+		t.Errorf("Subscriber to group1 received %d events, expected 1", len(group1))
+	}
+
+	if len(group2) != 0 {
+		t.Errorf("Subscriber to group2 received %d events, expected 0", len(group2))
+	}
+}
+
+func TestPublishDoesNotBlockOnFullSubscriber(t *testing.T) {
+	broker := NewBroker()
+
+	ch := broker.Subscribe("group1")
+
+	for i := 0; i < subscriberBufferSize+5; i++ {
+		broker.Publish(Event{Type: ResultCreated, GroupID: "group1"})
+	}
+
+	if len(ch) != subscriberBufferSize {
+		t.Errorf("Subscriber received %d events, expected %d", len(ch), subscriberBufferSize)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	broker := NewBroker()
+
+	ch := broker.Subscribe("group1")
+	broker.Unsubscribe("group1", ch)
+
+	if _, ok := <-ch; ok {
+		t.Error("Channel was not closed after unsubscribing")
+	}
+
+	if count := broker.SubscriberCount("group1"); count != 0 {
+		t.Errorf("Group has %d subscribers, expected 0", count)
+	}
+
+	// publishing to a group with no subscribers is a no-op
+	broker.Publish(Event{Type: ResultCreated, GroupID: "group1"})
+}
@@ -0,0 +1,135 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+
+	// registers the decoders for the formats that can be uploaded
+	_ "image/gif"
+	_ "image/png"
+)
+
+// the largest number of pixels an uploaded image can have, so that decoding a small
+// file can't use up all of the server's memory
+const MaxPixels = 40_000_000
+
+var ErrUnsupportedType = errors.New("image type is not supported")
+var ErrTooLarge = errors.New("image has too many pixels")
+
+// the content types that can be uploaded, and the file extensions they're stored with
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Sniff returns the content type and file extension of the image, based on its
+// contents rather than whatever the client claimed it was
+func Sniff(data []byte) (string, string, error) {
+	contentType := http.DetectContentType(data)
+
+	extension, ok := extensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", "", err
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return "", "", ErrTooLarge
+	}
+
+	return contentType, extension, nil
+}
+
+// Thumbnail scales the image down so that neither side is longer than maxSize, and
+// encodes it as a JPEG. Transparent areas become white
+func Thumbnail(data []byte, maxSize int) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := fit(src.Bounds().Dx(), src.Bounds().Dy(), maxSize)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	resize(dst, src)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// returns the size of a width x height rectangle scaled down to fit in a maxSize
+// square, keeping its aspect ratio. Rectangles that already fit aren't scaled up
+func fit(width int, height int, maxSize int) (int, int) {
+	if width <= maxSize && height <= maxSize {
+		return width, height
+	}
+
+	if width >= height {
+		return maxSize, max(1, height*maxSize/width)
+	}
+
+	return max(1, width*maxSize/height), maxSize
+}
+
+// draws src over dst, averaging the source pixels that fall in each destination pixel
+func resize(dst *image.RGBA, src image.Image) {
+	sb := src.Bounds()
+	db := dst.Bounds()
+
+	for y := 0; y < db.Dy(); y++ {
+		y0 := sb.Min.Y + y*sb.Dy()/db.Dy()
+		y1 := max(y0+1, sb.Min.Y+(y+1)*sb.Dy()/db.Dy())
+
+		for x := 0; x < db.Dx(); x++ {
+			x0 := sb.Min.X + x*sb.Dx()/db.Dx()
+			x1 := max(x0+1, sb.Min.X+(x+1)*sb.Dx()/db.Dx())
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			// the colours are premultiplied, so blend them over the white background
+			alpha := a / n
+			background := 0xffff - alpha
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + background),
+				G: uint16(g/n + background),
+				B: uint16(b/n + background),
+				A: 0xffff,
+			})
+		}
+	}
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,74 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width int, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 128})
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatalf("Could not encode test image: %s", err)
+	}
+
+	return buffer.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	contentType, extension, err := Sniff(encodePNG(t, 10, 10))
+	if err != nil {
+		t.Fatalf("Could not sniff PNG: %s", err)
+	}
+
+	if contentType != "image/png" || extension != ".png" {
+		t.Errorf("Sniffed type was incorrect! Actual: %s %s", contentType, extension)
+	}
+}
+
+func TestSniffUnsupportedType(t *testing.T) {
+	_, _, err := Sniff([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Sniffing an SVG did not fail with the right error! Actual: %v", err)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		width, height  int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{1000, 500, 256, 128},
+		{300, 900, 85, 256},
+		{100, 50, 100, 50},
+	}
+
+	for _, test := range tests {
+		data, err := Thumbnail(encodePNG(t, test.width, test.height), 256)
+		if err != nil {
+			t.Fatalf("Could not create thumbnail: %s", err)
+		}
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Could not decode thumbnail: %s", err)
+		}
+
+		if format != "jpeg" {
+			t.Errorf("Thumbnail format was incorrect! Actual: %s", format)
+		}
+
+		if config.Width != test.expectedWidth || config.Height != test.expectedHeight {
+			t.Errorf("Thumbnail of %dx%d image had incorrect size! Actual: %dx%d", test.width, test.height, config.Width, config.Height)
+		}
+	}
+}
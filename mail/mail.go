@@ -0,0 +1,137 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewSMTPMailerFromEnv creates an SMTP mailer from the SMTP_* environment variables,
+// or returns false if no SMTP host is configured
+func NewSMTPMailerFromEnv() (*SMTPMailer, bool) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, false
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "25"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "noreply@borescore.local"
+	}
+
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}, true
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message *Message) error {
+	body, err := buildMessage(m.From, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// local sinks such as MailHog don't need credentials
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{message.To}, body)
+}
+
+// builds a multipart/alternative MIME message containing both the text and HTML bodies
+func buildMessage(from string, message *Message, now time.Time) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	headers := []string{
+		"From: " + from,
+		"To: " + message.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", boundary),
+	}
+
+	buffer.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", message.TextBody},
+		{"text/html", message.HTMLBody},
+	}
+
+	for _, part := range parts {
+		buffer.WriteString("--" + boundary + "\r\n")
+		buffer.WriteString("Content-Type: " + part.contentType + "; charset=utf-8\r\n")
+		buffer.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		writer := quotedprintable.NewWriter(&buffer)
+		if _, err := writer.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		buffer.WriteString("\r\n")
+	}
+
+	buffer.WriteString("--" + boundary + "--\r\n")
+
+	return buffer.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	message, err := Render(InvitationMessage, "someone@example.com", map[string]string{
+		"Username":        "someone",
+		"InviterUsername": "<script>",
+		"GroupName":       "Board Game Night",
+		"Link":            "http://localhost:3000/invitations",
+	})
+
+	if err != nil {
+		t.Fatalf("Could not render message: %s", err)
+	}
+
+	if message.Subject != subjects[InvitationMessage] {
+		t.Errorf("Rendered subject was incorrect! Actual: %s", message.Subject)
+	}
+
+	if !strings.Contains(message.TextBody, "Board Game Night") {
+		t.Errorf("Rendered text body is missing the group name: %s", message.TextBody)
+	}
+
+	if strings.Contains(message.HTMLBody, "<script>") {
+		t.Errorf("Rendered HTML body was not escaped: %s", message.HTMLBody)
+	}
+}
+
+func TestRenderUnknownType(t *testing.T) {
+	if _, err := Render("unknown", "someone@example.com", nil); err == nil {
+		t.Error("Rendering an unknown message type did not fail")
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	body, err := buildMessage("noreply@example.com", &Message{
+		To:       "someone@example.com",
+		Subject:  "Hello",
+		TextBody: "text body",
+		HTMLBody: "<p>html body</p>",
+	}, time.Now())
+
+	if err != nil {
+		t.Fatalf("Could not build message: %s", err)
+	}
+
+	for _, expected := range []string{"To: someone@example.com", "multipart/alternative", "text body", "<p>html body</p>"} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Built message is missing %q", expected)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tables := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 1 * time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{10, 1 * time.Hour},
+	}
+
+	for _, table := range tables {
+		actual := retryDelay(table.attempt)
+		if actual != table.expected {
+			t.Errorf("Computed retry delay was incorrect! Actual: %s, expected: %s", actual, table.expected)
+		}
+	}
+}
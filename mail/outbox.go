@@ -0,0 +1,107 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"os"
+	"phrasmotica/bore-score-api/models"
+	"time"
+)
+
+// TODO: put these in a more central place, or inject them as dependencies
+var (
+	Info  *log.Logger = log.New(os.Stdout, "INFO: ", log.LstdFlags|log.Lshortfile)
+	Error *log.Logger = log.New(os.Stdout, "ERROR: ", log.LstdFlags|log.Lshortfile)
+)
+
+const (
+	// MaxAttempts is how many times a message is attempted before it is marked as failed
+	MaxAttempts = 8
+
+	baseRetryDelay = 1 * time.Minute
+	maxRetryDelay  = 1 * time.Hour
+)
+
+// OutboxStore is the part of the database that the outbox needs
+type OutboxStore interface {
+	GetPendingOutboxMessages(ctx context.Context) (bool, []models.OutboxMessage)
+	UpdateOutboxMessage(ctx context.Context, message *models.OutboxMessage) bool
+}
+
+// RunOutbox sends pending outbox messages every interval until the context is cancelled.
+// Messages are only removed from the pending set once they've been sent or have run out
+// of attempts, so they survive restarts
+func RunOutbox(ctx context.Context, store OutboxStore, mailer Mailer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessOutbox(ctx, store, mailer, time.Now().UTC())
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ProcessOutbox attempts to send every pending message that is due
+func ProcessOutbox(ctx context.Context, store OutboxStore, mailer Mailer, now time.Time) {
+	success, messages := store.GetPendingOutboxMessages(ctx)
+	if !success {
+		Error.Println("Could not get pending outbox messages")
+		return
+	}
+
+	for _, m := range messages {
+		if m.TimeNextAttempt > now.Unix() {
+			continue
+		}
+
+		err := mailer.Send(ctx, &Message{
+			To:       m.To,
+			Subject:  m.Subject,
+			TextBody: m.TextBody,
+			HTMLBody: m.HTMLBody,
+		})
+
+		m.Attempts++
+
+		if err == nil {
+			m.Status = models.DeliverySucceeded
+			m.TimeSent = now.Unix()
+			m.LastError = ""
+
+			Info.Printf("Sent %s email %s\n", m.Type, m.ID)
+		} else {
+			Error.Printf("Attempt %d of %s email %s failed: %s\n", m.Attempts, m.Type, m.ID, err)
+
+			m.LastError = err.Error()
+			m.TimeNextAttempt = now.Add(retryDelay(m.Attempts)).Unix()
+
+			if m.Attempts >= MaxAttempts {
+				m.Status = models.DeliveryFailed
+			}
+		}
+
+		if success := store.UpdateOutboxMessage(ctx, &m); !success {
+			Error.Printf("Could not update outbox message %s\n", m.ID)
+		}
+	}
+}
+
+// returns how long to wait after the given (one-indexed) failed attempt before trying again
+func retryDelay(attempt int) time.Duration {
+	delay := baseRetryDelay
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+type MessageType string
+
+const (
+	InvitationMessage        MessageType = "invitation"
+	PasswordResetMessage     MessageType = "password-reset"
+	ApprovalReminderMessage  MessageType = "approval-reminder"
+	EmailVerificationMessage MessageType = "email-verification"
+)
+
+var subjects = map[MessageType]string{
+	InvitationMessage:        "You've been invited to a group on BoreScore",
+	PasswordResetMessage:     "Reset your BoreScore password",
+	ApprovalReminderMessage:  "A BoreScore result is waiting for your approval",
+	EmailVerificationMessage: "Verify your email address for BoreScore",
+}
+
+//go:embed templates
+var templateFiles embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/*.html"))
+)
+
+// Render creates a message of the given type by executing its text and HTML templates
+// with the given data. Each message type has a "<type>.txt" and a "<type>.html" template
+func Render(messageType MessageType, to string, data interface{}) (*Message, error) {
+	subject, ok := subjects[messageType]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %s", messageType)
+	}
+
+	var textBody bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&textBody, string(messageType)+".txt", data); err != nil {
+		return nil, err
+	}
+
+	var htmlBody bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&htmlBody, string(messageType)+".html", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:       to,
+		Subject:  subject,
+		TextBody: textBody.String(),
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"phrasmotica/bore-score-api/auth"
 	docs "phrasmotica/bore-score-api/docs/borescoreapi"
+	"phrasmotica/bore-score-api/models"
 	"phrasmotica/bore-score-api/routes"
 
 	"github.com/gin-gonic/gin"
@@ -32,18 +34,55 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		panic(err)
+	}
+
 	router := gin.Default()
 
 	router.Use(auth.CORSMiddleware())
 
+	auth.SetSessionValidator(routes.IsSessionActive)
+	auth.SetAPIKeyValidator(routes.AuthenticateAPIKey)
+	auth.SetPermissionChecker(routes.UserHasPermission)
+
+	router.GET("/.well-known/jwks.json", routes.GetJWKS)
+
 	docs.SwaggerInfo.BasePath = "/"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	admin := router.Group("/admin", auth.TokenAuth(false))
+	{
+		admin.GET("/audit", auth.CheckPermission(models.ViewAuditLog), routes.GetAuditLog)
+		admin.GET("/permissions", auth.CheckPermission(models.ManageUsers), routes.GetPermissionCatalogue)
+		admin.GET("/users", auth.CheckPermission(models.ManageUsers), routes.AdminGetUsers)
+		admin.DELETE("/users/:username", auth.CheckPermission(models.ManagePlayers), routes.AdminDeleteUser)
+
+		adminUserByUsername := admin.Group("/users/:username", auth.CheckPermission(models.ManageUsers))
+		{
+			adminUserByUsername.GET("", routes.AdminGetUser)
+			adminUserByUsername.GET("/permissions", routes.GetUserPermissions)
+
+			adminUserByUsername.POST("/disable", routes.DisableUser)
+			adminUserByUsername.POST("/enable", routes.EnableUser)
+			adminUserByUsername.POST("/permissions", routes.GrantPermission)
+			adminUserByUsername.POST("/resetPassword", routes.ForcePasswordReset)
+			adminUserByUsername.POST("/roles", routes.GrantRole)
+			adminUserByUsername.POST("/unlock", routes.UnlockUser)
+
+			adminUserByUsername.PUT("/permissions", routes.SetPermissions)
+
+			adminUserByUsername.DELETE("/permissions/:permission", routes.RevokePermission)
+			adminUserByUsername.DELETE("/roles/:role", routes.RevokeRole)
+		}
+	}
+
 	approvals := router.Group("/approvals", auth.TokenAuth(false))
 	{
 		approvals.GET("/:resultId", routes.GetApprovals)
 
 		approvals.POST("", routes.PostApproval)
+		approvals.POST("/:resultId/remind", routes.RemindApprovals)
 	}
 
 	games := router.Group("/games")
@@ -56,28 +95,53 @@ func main() {
 		{
 			gameByName.GET("", routes.GetGame)
 
-			gameByName.DELETE("", auth.TokenAuth(false), auth.CheckPermission("superuser"), routes.DeleteGame)
+			gameByName.POST("/image", auth.TokenAuth(false), auth.CheckPermission(models.ManageGames), routes.UploadGameImage)
+
+			gameByName.DELETE("", auth.TokenAuth(false), auth.CheckPermission(models.ManageGames), routes.DeleteGame)
 		}
 	}
 
 	groups := router.Group("/groups")
 	{
-		groups.GET("", auth.TokenAuth(true), routes.GetGroups)
+		groups.GET("", auth.TokenAuth(true, models.ReadGroupsScope), routes.GetGroups)
 
 		groups.POST("", auth.TokenAuth(false), routes.PostGroup)
 
 		groupById := groups.Group("/:groupId")
 		{
-			groupById.GET("", auth.TokenAuth(true), routes.GetGroup)
+			groupById.GET("", auth.TokenAuth(true, models.ReadGroupsScope), routes.GetGroup)
+			groupById.GET("/activity", auth.TokenAuth(false), routes.GetGroupActivity)
+			groupById.GET("/guests", auth.TokenAuth(false), routes.GetGuestPlayers)
 			groupById.GET("/invitations", auth.TokenAuth(false), routes.GetGroupInvitationsForGroup)
-			groupById.GET("/players", auth.TokenAuth(false), routes.GetPlayersInGroup)
-			groupById.GET("/results", auth.TokenAuth(false), routes.GetResultsForGroup)
+			groupById.GET("/players", auth.TokenAuth(false, models.ReadGroupsScope), routes.GetPlayersInGroup)
+			groupById.GET("/results", auth.TokenAuth(false, models.ReadResultsScope), routes.GetResultsForGroup)
+			groupById.GET("/stream", auth.TokenAuth(false), routes.GetGroupStream)
+
+			groupById.POST("/guests", auth.TokenAuth(false), routes.PostGuestPlayer)
+			groupById.POST("/image", auth.TokenAuth(false), routes.UploadGroupPicture)
+			groupById.POST("/guests/:guestUsername/claimLink", auth.TokenAuth(false), routes.CreateGuestClaimLink)
+
+			groupById.DELETE("", auth.TokenAuth(false), auth.CheckPermission(models.ManageGroups), routes.DeleteGroup)
+
+			groupWebhooks := groupById.Group("/webhooks", auth.TokenAuth(false))
+			{
+				groupWebhooks.GET("", routes.GetWebhooks)
 
-			groupById.DELETE("", auth.TokenAuth(false), auth.CheckPermission("superuser"), routes.DeleteGroup)
+				groupWebhooks.POST("", routes.PostWebhook)
+
+				webhookById := groupWebhooks.Group("/:webhookId")
+				{
+					webhookById.GET("/deliveries", routes.GetWebhookDeliveries)
+
+					webhookById.POST("/deliveries/:deliveryId/replay", routes.ReplayWebhookDelivery)
+
+					webhookById.DELETE("", routes.DeleteWebhook)
+				}
+			}
 
 			groupLeaderboards := groupById.Group("/leaderboard")
 			{
-				groupLeaderboards.GET("/:gameId", auth.TokenAuth(false), routes.GetLeaderboard)
+				groupLeaderboards.GET("/:gameId", auth.TokenAuth(false, models.ReadResultsScope), routes.GetLeaderboard)
 			}
 		}
 	}
@@ -100,37 +164,75 @@ func main() {
 		groupMemberships.GET("/:username", routes.GetGroupMemberships)
 
 		groupMemberships.POST("", routes.AddGroupMembership)
+
+		groupMemberships.DELETE("/:username/:groupId", routes.RemoveGroupMembership)
+	}
+
+	merges := router.Group("/merges", auth.TokenAuth(false))
+	{
+		merges.POST("", routes.PostMerge)
+
+		mergeById := merges.Group("/:mergeId")
+		{
+			mergeById.GET("", routes.GetMerge)
+
+			mergeById.POST("/resume", routes.ResumeMerge)
+		}
 	}
 
+	router.GET("/images/:name", routes.GetImage)
+
 	linkTypes := router.Group("/linkTypes")
 	{
 		linkTypes.GET("", routes.GetLinkTypes)
 	}
 
-	// TODO: move Player columns into User entity
-	players := router.Group("/players")
+	oidc := router.Group("/oidc")
 	{
-		players.GET("", routes.GetPlayers)
-
-		players.POST("", routes.PostPlayer)
+		oidc.POST("/login", auth.TokenAuth(true), routes.OIDCLogin)
+		oidc.POST("/callback", routes.OIDCCallback)
+	}
 
-		playerByUsername := players.Group("/:username")
-		{
-			playerByUsername.GET("", routes.GetPlayer)
+	password := router.Group("/password")
+	{
+		password.POST("/forgot", routes.ForgotPassword)
+		password.POST("/reset", routes.ResetPassword)
+	}
 
-			playerByUsername.PUT("", auth.TokenAuth(false), routes.UpdatePlayer)
+	router.POST("/guests/claim", auth.TokenAuth(false), routes.ClaimGuestPlayer)
 
-			playerByUsername.DELETE("", auth.TokenAuth(false), auth.CheckPermission("superuser"), routes.DeletePlayer)
-		}
+	players := router.Group("/players")
+	{
+		players.GET("", auth.TokenAuth(true), routes.GetPlayers)
+		players.GET("/:username", auth.TokenAuth(true), routes.GetPlayer)
 	}
 
 	router.GET("/summary", routes.GetSummary)
 
 	results := router.Group("/results")
 	{
-		results.GET("", auth.TokenAuth(true), routes.GetResults)
+		results.GET("", auth.TokenAuth(true, models.ReadResultsScope), routes.GetResults)
+
+		results.POST("", auth.TokenAuth(true, models.WriteResultsScope), routes.PostResult)
+
+		resultById := results.Group("/:resultId")
+		{
+			resultById.GET("/attachments", auth.TokenAuth(true, models.ReadResultsScope), routes.GetAttachments)
+			resultById.GET("/attachments/:attachmentId/image", auth.TokenAuth(true, models.ReadResultsScope), routes.GetAttachmentImage)
+			resultById.GET("/attachments/:attachmentId/thumbnail", auth.TokenAuth(true, models.ReadResultsScope), routes.GetAttachmentThumbnail)
+			resultById.GET("/comments", auth.TokenAuth(true, models.ReadResultsScope), routes.GetComments)
+			resultById.GET("/reactions", auth.TokenAuth(true, models.ReadResultsScope), routes.GetReactions)
+
+			resultById.POST("/attachments", auth.TokenAuth(false), routes.PostAttachment)
+			resultById.POST("/comments", auth.TokenAuth(false), routes.PostComment)
+			resultById.POST("/reactions", auth.TokenAuth(false), routes.PostReaction)
+
+			resultById.PUT("/comments/:commentId", auth.TokenAuth(false), routes.EditComment)
 
-		results.POST("", routes.PostResult)
+			resultById.DELETE("/attachments/:attachmentId", auth.TokenAuth(false), routes.DeleteAttachment)
+			resultById.DELETE("/comments/:commentId", auth.TokenAuth(false), routes.DeleteComment)
+			resultById.DELETE("/reactions/:reactionId", auth.TokenAuth(false), routes.DeleteReaction)
+		}
 	}
 
 	winMethods := router.Group("/winMethods")
@@ -141,22 +243,62 @@ func main() {
 	token := router.Group("/token")
 	{
 		token.POST("", routes.GenerateToken)
-		token.POST("/refresh", auth.TokenAuth(false), routes.RefreshToken)
+		token.POST("/2fa", routes.GenerateTwoFactorToken)
+		token.POST("/refresh", routes.RefreshToken)
+		token.POST("/logout", routes.Logout)
 	}
 
 	users := router.Group("/users")
 	{
 		users.POST("", routes.RegisterUser)
+		users.POST("/verifyEmail", routes.VerifyEmail)
 
 		userByUsername := users.Group("/:username")
 		{
 			userByUsername.GET("", auth.TokenAuth(true), routes.GetUser)
+			userByUsername.DELETE("", auth.TokenAuth(false), routes.DeleteOwnAccount)
+			userByUsername.GET("/export", auth.TokenAuth(false), routes.ExportAccount)
+			userByUsername.GET("/feed", auth.TokenAuth(false), routes.GetFriendsFeed)
+			userByUsername.GET("/friends", auth.TokenAuth(false), routes.GetFriends)
+			userByUsername.POST("/friends", auth.TokenAuth(false), routes.SendFriendRequest)
+			userByUsername.POST("/friends/:friendshipId/accept", auth.TokenAuth(false), routes.AcceptFriendRequest)
+			userByUsername.DELETE("/friends/:friendshipId", auth.TokenAuth(false), routes.RemoveFriend)
+			userByUsername.PUT("/friendRestrictions", auth.TokenAuth(false), routes.UpdateFriendRestrictions)
+			userByUsername.GET("/headToHead", auth.TokenAuth(false), routes.GetHeadToHeadSuggestions)
+			userByUsername.GET("/identities", auth.TokenAuth(false), routes.GetExternalIdentities)
+			userByUsername.DELETE("/identities/:identityId", auth.TokenAuth(false), routes.UnlinkExternalIdentity)
 			userByUsername.GET("/invitations", auth.TokenAuth(false), routes.GetGroupInvitationsForUser)
-			userByUsername.GET("/results", auth.TokenAuth(false), routes.GetResultsForUser)
+			userByUsername.GET("/notifications", auth.TokenAuth(false), routes.GetNotifications)
+			userByUsername.GET("/results", auth.TokenAuth(false, models.ReadResultsScope), routes.GetResultsForUser)
+
+			userByUsername.POST("/notifications/read", auth.TokenAuth(false), routes.MarkAllNotificationsRead)
+			userByUsername.POST("/notifications/:notificationId/read", auth.TokenAuth(false), routes.MarkNotificationRead)
 
+			userByUsername.PUT("/email", auth.TokenAuth(false), routes.ChangeEmail)
+			userByUsername.POST("/email/resend", auth.TokenAuth(false), routes.ResendVerificationEmail)
+			userByUsername.PUT("/emailPreferences", auth.TokenAuth(false), routes.UpdateEmailPreferences)
 			userByUsername.PUT("/password", auth.TokenAuth(false), routes.UpdatePassword)
+			userByUsername.PUT("/privacy", auth.TokenAuth(false), routes.UpdatePrivacySettings)
+			userByUsername.PUT("/profile", auth.TokenAuth(false), routes.UpdateProfile)
+			userByUsername.POST("/profilePicture", auth.TokenAuth(false), routes.UploadProfilePicture)
+			userByUsername.GET("/apiKeys", auth.TokenAuth(false), routes.GetAPIKeys)
+			userByUsername.POST("/apiKeys", auth.TokenAuth(false), routes.PostAPIKey)
+			userByUsername.DELETE("/apiKeys/:apiKeyId", auth.TokenAuth(false), routes.RevokeAPIKey)
+			userByUsername.GET("/sessions", auth.TokenAuth(false), routes.GetSessions)
+			userByUsername.DELETE("/sessions/:sessionId", auth.TokenAuth(false), routes.RevokeSession)
+			userByUsername.POST("/twoFactor", auth.TokenAuth(false), routes.EnrolTwoFactor)
+			userByUsername.POST("/twoFactor/confirm", auth.TokenAuth(false), routes.ConfirmTwoFactor)
+			userByUsername.POST("/twoFactor/disable", auth.TokenAuth(false), routes.DisableTwoFactor)
+			userByUsername.POST("/twoFactor/recoveryCodes", auth.TokenAuth(false), routes.RegenerateRecoveryCodes)
 		}
 	}
 
+	routes.StartMail(context.Background())
+	routes.StartBlobStore(context.Background())
+	routes.StartWebhooks(context.Background())
+	routes.MigratePlayers(context.Background())
+	routes.ResumeMergeJobs(context.Background())
+	routes.StartOIDC(context.Background())
+
 	router.Run(":8000")
 }
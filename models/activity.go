@@ -0,0 +1,26 @@
+package models
+
+type Activity struct {
+	ID             string       `json:"id" bson:"id"`
+	GroupID        string       `json:"groupId" bson:"groupId"`
+	TimeCreated    int64        `json:"timeCreated" bson:"timeCreated"`
+	Type           ActivityType `json:"type" bson:"type"`
+	Username       string       `json:"username" bson:"username"`
+	TargetUsername string       `json:"targetUsername" bson:"targetUsername"`
+	ResultID       string       `json:"resultId" bson:"resultId"`
+	GameID         string       `json:"gameId" bson:"gameId"`
+}
+
+type ActivityType string
+
+const (
+	ResultPosted   ActivityType = "result-posted"   // username posted result resultId
+	ResultApproved ActivityType = "result-approved" // username approved result resultId
+	ResultRejected ActivityType = "result-rejected" // username rejected result resultId
+	MemberJoined   ActivityType = "member-joined"   // username joined the group
+	MemberLeft     ActivityType = "member-left"     // targetUsername left the group, or was removed by username
+	InvitationSent ActivityType = "invitation-sent" // username invited targetUsername to the group
+	LeaderChanged  ActivityType = "leader-changed"  // username overtook targetUsername at the top of the leaderboard for gameId
+	GuestClaimed   ActivityType = "guest-claimed"   // username claimed the results of guest player targetUsername
+	CommentPosted  ActivityType = "comment-posted"  // username commented on result resultId, or replied to targetUsername's comment on it
+)
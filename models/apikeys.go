@@ -0,0 +1,38 @@
+package models
+
+import "golang.org/x/exp/slices"
+
+// APIKey lets a script act as a user, with only the scopes that the key was created
+// with. Only a hash of the key is stored
+type APIKey struct {
+	ID           string        `json:"id" bson:"id"`
+	Username     string        `json:"username" bson:"username"`
+	Name         string        `json:"name" bson:"name"`
+	Prefix       string        `json:"prefix" bson:"prefix"`
+	KeyHash      string        `json:"keyHash" bson:"keyHash"`
+	Scopes       []APIKeyScope `json:"scopes" bson:"scopes"`
+	GroupID      string        `json:"groupId" bson:"groupId"`
+	TimeCreated  int64         `json:"timeCreated" bson:"timeCreated"`
+	TimeLastUsed int64         `json:"timeLastUsed" bson:"timeLastUsed"`
+	Revoked      bool          `json:"revoked" bson:"revoked"`
+	TimeRevoked  int64         `json:"timeRevoked" bson:"timeRevoked"`
+}
+
+type APIKeyScope string
+
+const (
+	ReadResultsScope  APIKeyScope = "results:read"
+	WriteResultsScope APIKeyScope = "results:write"
+	ReadGroupsScope   APIKeyScope = "groups:read"
+)
+
+var AllAPIKeyScopes = []APIKeyScope{
+	ReadResultsScope,
+	WriteResultsScope,
+	ReadGroupsScope,
+}
+
+// IsValidAPIKeyScope returns whether the given scope is one that API keys can have
+func IsValidAPIKeyScope(scope APIKeyScope) bool {
+	return slices.Contains(AllAPIKeyScopes, scope)
+}
@@ -0,0 +1,17 @@
+package models
+
+// Attachment is an image uploaded to a result, such as a photo of the final board state
+type Attachment struct {
+	ID          string `json:"id" bson:"id"`
+	ResultID    string `json:"resultId" bson:"resultId"`
+	TimeCreated int64  `json:"timeCreated" bson:"timeCreated"`
+	UploadedBy  string `json:"uploadedBy" bson:"uploadedBy"`
+
+	// the name of the uploaded image. It isn't served publicly, only to users who can see
+	// the result
+	BlobName string `json:"-" bson:"blobName"`
+
+	// where the image and its thumbnail can be fetched from. These aren't stored
+	URL          string `json:"url" bson:"-"`
+	ThumbnailURL string `json:"thumbnailUrl" bson:"-"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// AuditEntry records a security-relevant event, such as an account being locked
+type AuditEntry struct {
+	ID             string    `json:"id" bson:"id"`
+	TimeCreated    int64     `json:"timeCreated" bson:"timeCreated"`
+	Type           AuditType `json:"type" bson:"type"`
+	Username       string    `json:"username" bson:"username"`
+	TargetUsername string    `json:"targetUsername" bson:"targetUsername"`
+	IPAddress      string    `json:"ipAddress" bson:"ipAddress"`
+	Details        string    `json:"details" bson:"details"`
+}
+
+type AuditType string
+
+const (
+	AccountLocked   AuditType = "account-locked"
+	IPAddressLocked AuditType = "ip-locked"
+	AccountUnlocked AuditType = "account-unlocked"
+
+	PermissionGranted AuditType = "permission-granted"
+	PermissionRevoked AuditType = "permission-revoked"
+	RoleGranted       AuditType = "role-granted"
+	RoleRevoked       AuditType = "role-revoked"
+	PermissionsSet    AuditType = "permissions-set"
+
+	UserDisabled        AuditType = "user-disabled"
+	UserEnabled         AuditType = "user-enabled"
+	PasswordResetForced AuditType = "password-reset-forced"
+	UserDeleted         AuditType = "user-deleted"
+
+	IdentitiesMerged AuditType = "identities-merged"
+
+	CommentRemoved AuditType = "comment-removed"
+)
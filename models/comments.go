@@ -0,0 +1,60 @@
+package models
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// the longest comment that can be posted, in characters
+const MaxCommentLength = 2000
+
+// Comment is a message about a result. Replies point at the comment they reply to
+type Comment struct {
+	ID          string `json:"id" bson:"id"`
+	ResultID    string `json:"resultId" bson:"resultId"`
+	ParentID    string `json:"parentId" bson:"parentId"`
+	TimeCreated int64  `json:"timeCreated" bson:"timeCreated"`
+	Username    string `json:"username" bson:"username"`
+	Text        string `json:"text" bson:"text"`
+	TimeEdited  int64  `json:"timeEdited" bson:"timeEdited"`
+
+	// deleted comments keep their place in the thread so that replies to them still make sense
+	Deleted   bool   `json:"deleted" bson:"deleted"`
+	DeletedBy string `json:"deletedBy" bson:"deletedBy"`
+}
+
+// Reaction is an emoji that a user has reacted to a result with
+type Reaction struct {
+	ID          string `json:"id" bson:"id"`
+	ResultID    string `json:"resultId" bson:"resultId"`
+	TimeCreated int64  `json:"timeCreated" bson:"timeCreated"`
+	Username    string `json:"username" bson:"username"`
+	Emoji       string `json:"emoji" bson:"emoji"`
+}
+
+// IsValidEmoji returns whether the string is a single emoji, including ones built from
+// several code points such as flags, skin tones and ZWJ sequences
+func IsValidEmoji(s string) bool {
+	count := utf8.RuneCountInString(s)
+	if count <= 0 || count > 10 {
+		return false
+	}
+
+	hasSymbol := false
+
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		case r == '\u200d', // zero width joiner
+			r == '\ufe0f',                // emoji presentation selector
+			r == '\u20e3',                // combining keycap
+			r >= 0x1f3fb && r <= 0x1f3ff, // skin tone modifiers
+			r >= 0xe0020 && r <= 0xe007f: // tags, for subdivision flags
+		default:
+			return false
+		}
+	}
+
+	return hasSymbol
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestIsValidEmoji(t *testing.T) {
+	tests := []struct {
+		emoji    string
+		expected bool
+	}{
+		{"👍", true},
+		{"👍🏽", true},
+		{"❤️", true},
+		{"🇬🇧", true},
+		{"👨‍👩‍👧", true},
+		{"", false},
+		{"a", false},
+		{"👍 ", false},
+		{"\u200d", false},
+		{"<script>", false},
+	}
+
+	for _, test := range tests {
+		if actual := IsValidEmoji(test.emoji); actual != test.expected {
+			t.Errorf("Wrong result for %q! Expected: %t, actual: %t", test.emoji, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// ExternalIdentity links a user to their account with an OpenID Connect provider
+type ExternalIdentity struct {
+	ID            string `json:"id" bson:"id"`
+	Username      string `json:"username" bson:"username"`
+	Issuer        string `json:"issuer" bson:"issuer"`
+	Subject       string `json:"subject" bson:"subject"`
+	Email         string `json:"email" bson:"email"`
+	TimeCreated   int64  `json:"timeCreated" bson:"timeCreated"`
+	TimeLastLogin int64  `json:"timeLastLogin" bson:"timeLastLogin"`
+}
+
+// LoginState is what the API remembers between sending a user to their OpenID Connect
+// provider and them coming back. Only a hash of the state parameter is stored
+type LoginState struct {
+	ID           string `json:"id" bson:"id"`
+	StateHash    string `json:"stateHash" bson:"stateHash"`
+	Nonce        string `json:"nonce" bson:"nonce"`
+	CodeVerifier string `json:"codeVerifier" bson:"codeVerifier"`
+
+	// set if a logged-in user is linking the external identity to their account
+	LinkUsername string `json:"linkUsername" bson:"linkUsername"`
+
+	TimeCreated int64 `json:"timeCreated" bson:"timeCreated"`
+	TimeExpires int64 `json:"timeExpires" bson:"timeExpires"`
+	Used        bool  `json:"used" bson:"used"`
+}
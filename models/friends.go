@@ -0,0 +1,39 @@
+package models
+
+// Friendship links two users. Username sent the request to FriendUsername, and they
+// become friends once FriendUsername accepts it
+type Friendship struct {
+	ID             string           `json:"id" bson:"id"`
+	Username       string           `json:"username" bson:"username"`
+	FriendUsername string           `json:"friendUsername" bson:"friendUsername"`
+	TimeCreated    int64            `json:"timeCreated" bson:"timeCreated"`
+	Status         FriendshipStatus `json:"status" bson:"status"`
+	TimeAccepted   int64            `json:"timeAccepted" bson:"timeAccepted"`
+}
+
+type FriendshipStatus string
+
+const (
+	FriendRequested FriendshipStatus = "requested"
+	FriendAccepted  FriendshipStatus = "accepted"
+)
+
+// Involves returns whether the user is on either side of the friendship
+func (friendship *Friendship) Involves(username string) bool {
+	return friendship.Username == username || friendship.FriendUsername == username
+}
+
+// Other returns the user on the other side of the friendship from the given user
+func (friendship *Friendship) Other(username string) string {
+	if friendship.Username == username {
+		return friendship.FriendUsername
+	}
+
+	return friendship.Username
+}
+
+// FriendRestrictions limits what users who aren't the user's friends can do to them
+type FriendRestrictions struct {
+	InvitationsFromFriendsOnly bool `json:"invitationsFromFriendsOnly" bson:"invitationsFromFriendsOnly"`
+	ResultsFromFriendsOnly     bool `json:"resultsFromFriendsOnly" bson:"resultsFromFriendsOnly"`
+}
@@ -8,6 +8,11 @@ type Group struct {
 	ProfilePicture string              `json:"profilePicture" bson:"profilePicture"`
 	CreatedBy      string              `json:"createdBy" bson:"createdBy"`
 	Visibility     GroupVisibilityName `json:"visibility" bson:"visibility"`
+	Archived       bool                `json:"archived" bson:"archived"`
+	TimeArchived   int64               `json:"timeArchived" bson:"timeArchived"`
+
+	// the name of the uploaded profile picture, if any
+	UploadedPicture string `json:"-" bson:"uploadedPicture"`
 }
 
 type GroupType struct {
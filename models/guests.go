@@ -0,0 +1,27 @@
+package models
+
+import "strings"
+
+// GuestUsernamePrefix starts the username of every guest player, so that guests can be
+// told apart from users in results
+const GuestUsernamePrefix = "guest:"
+
+// GuestPlayer is someone without an account who has played in a group. They can only
+// take part in results in that group, until they claim their history for an account
+type GuestPlayer struct {
+	ID          string `json:"id" bson:"id"`
+	GroupID     string `json:"groupId" bson:"groupId"`
+	Username    string `json:"username" bson:"username"`
+	DisplayName string `json:"displayName" bson:"displayName"`
+	CreatedBy   string `json:"createdBy" bson:"createdBy"`
+	TimeCreated int64  `json:"timeCreated" bson:"timeCreated"`
+
+	// set once the guest's results have been moved to a user's account
+	ClaimedBy   string `json:"claimedBy" bson:"claimedBy"`
+	TimeClaimed int64  `json:"timeClaimed" bson:"timeClaimed"`
+}
+
+// IsGuestUsername returns whether the username belongs to a guest player
+func IsGuestUsername(username string) bool {
+	return strings.HasPrefix(username, GuestUsernamePrefix)
+}
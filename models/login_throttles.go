@@ -0,0 +1,21 @@
+package models
+
+// LoginThrottle tracks failed logins for an account or an IP address
+type LoginThrottle struct {
+	Kind            ThrottleKind `json:"kind" bson:"kind"`
+	Key             string       `json:"key" bson:"key"`
+	FailedAttempts  int          `json:"failedAttempts" bson:"failedAttempts"`
+	TimeLastFailure int64        `json:"timeLastFailure" bson:"timeLastFailure"`
+	TimeLockedUntil int64        `json:"timeLockedUntil" bson:"timeLockedUntil"`
+
+	// identifies the version of the throttle that was read, so that concurrent failures
+	// aren't lost
+	ETag string `json:"-" bson:"-"`
+}
+
+type ThrottleKind string
+
+const (
+	AccountThrottle   ThrottleKind = "account"
+	IPAddressThrottle ThrottleKind = "ip"
+)
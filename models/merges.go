@@ -0,0 +1,43 @@
+package models
+
+// MergeJob moves everything recorded against one username to another, either everywhere
+// or only within one group. It works through one phase at a time, so that it can be
+// resumed from its current phase if it's interrupted
+type MergeJob struct {
+	ID           string `json:"id" bson:"id"`
+	FromUsername string `json:"fromUsername" bson:"fromUsername"`
+	ToUsername   string `json:"toUsername" bson:"toUsername"`
+
+	// empty if the merge applies everywhere
+	GroupID string `json:"groupId" bson:"groupId"`
+
+	CreatedBy     string      `json:"createdBy" bson:"createdBy"`
+	TimeCreated   int64       `json:"timeCreated" bson:"timeCreated"`
+	TimeCompleted int64       `json:"timeCompleted" bson:"timeCompleted"`
+	Status        MergeStatus `json:"status" bson:"status"`
+	Phase         MergePhase  `json:"phase" bson:"phase"`
+	LastError     string      `json:"lastError" bson:"lastError"`
+
+	ResultsMerged     int `json:"resultsMerged" bson:"resultsMerged"`
+	ApprovalsMerged   int `json:"approvalsMerged" bson:"approvalsMerged"`
+	MembershipsMerged int `json:"membershipsMerged" bson:"membershipsMerged"`
+	InvitationsMerged int `json:"invitationsMerged" bson:"invitationsMerged"`
+}
+
+type MergeStatus string
+
+const (
+	MergeRunning   MergeStatus = "running"
+	MergeCompleted MergeStatus = "completed"
+	MergeFailed    MergeStatus = "failed"
+)
+
+type MergePhase string
+
+const (
+	MergeResults     MergePhase = "results"
+	MergeApprovals   MergePhase = "approvals"
+	MergeMemberships MergePhase = "memberships"
+	MergeInvitations MergePhase = "invitations"
+	MergeDone        MergePhase = "done"
+)
@@ -0,0 +1,26 @@
+package models
+
+type Notification struct {
+	ID           string           `json:"id" bson:"id"`
+	Username     string           `json:"username" bson:"username"`
+	TimeCreated  int64            `json:"timeCreated" bson:"timeCreated"`
+	Type         NotificationType `json:"type" bson:"type"`
+	FromUsername string           `json:"fromUsername" bson:"fromUsername"`
+	GroupID      string           `json:"groupId" bson:"groupId"`
+	ResultID     string           `json:"resultId" bson:"resultId"`
+	InvitationID string           `json:"invitationId" bson:"invitationId"`
+	Read         bool             `json:"read" bson:"read"`
+	TimeRead     int64            `json:"timeRead" bson:"timeRead"`
+}
+
+type NotificationType string
+
+const (
+	InvitationNotification      NotificationType = "invitation"       // fromUsername invited the user to groupId
+	ApprovalRequestNotification NotificationType = "approval-request" // the user was added to resultId and needs to approve it
+	ResultRejectedNotification  NotificationType = "result-rejected"  // fromUsername rejected resultId, which the user is in
+	FriendRequestNotification   NotificationType = "friend-request"   // fromUsername sent the user a friend request
+	FriendAcceptedNotification  NotificationType = "friend-accepted"  // fromUsername accepted the user's friend request
+	CommentNotification         NotificationType = "comment"          // fromUsername commented on resultId, which the user is in
+	CommentReplyNotification    NotificationType = "comment-reply"    // fromUsername replied to the user's comment on resultId
+)
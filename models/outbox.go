@@ -0,0 +1,16 @@
+package models
+
+type OutboxMessage struct {
+	ID              string         `json:"id" bson:"id"`
+	TimeCreated     int64          `json:"timeCreated" bson:"timeCreated"`
+	Type            string         `json:"type" bson:"type"`
+	To              string         `json:"to" bson:"to"`
+	Subject         string         `json:"subject" bson:"subject"`
+	TextBody        string         `json:"textBody" bson:"textBody"`
+	HTMLBody        string         `json:"htmlBody" bson:"htmlBody"`
+	Status          DeliveryStatus `json:"status" bson:"status"`
+	Attempts        int            `json:"attempts" bson:"attempts"`
+	TimeNextAttempt int64          `json:"timeNextAttempt" bson:"timeNextAttempt"`
+	TimeSent        int64          `json:"timeSent" bson:"timeSent"`
+	LastError       string         `json:"lastError" bson:"lastError"`
+}
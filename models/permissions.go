@@ -0,0 +1,110 @@
+package models
+
+import "golang.org/x/exp/slices"
+
+type Permission string
+
+const (
+	// Superuser implies every other permission
+	Superuser Permission = "superuser"
+
+	ManageGames     Permission = "manage-games"
+	ManageGroups    Permission = "manage-groups"
+	ManagePlayers   Permission = "manage-players"
+	ManageUsers     Permission = "manage-users"
+	ModerateResults Permission = "moderate-results"
+	ViewAuditLog    Permission = "view-audit-log"
+)
+
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// AllPermissions is the catalogue of permissions that can be granted to users
+var AllPermissions = []PermissionInfo{
+	{Superuser, "Everything, including granting superuser to others"},
+	{ManageGames, "Add, edit and delete games"},
+	{ManageGroups, "Delete groups"},
+	{ManagePlayers, "Delete accounts, removing them from results"},
+	{ManageUsers, "Unlock, disable and change the permissions of users"},
+	{ModerateResults, "Remove results, comments and photos in any group"},
+	{ViewAuditLog, "See the admin audit log"},
+}
+
+// Role bundles permissions that are usually granted together
+type Role struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions"`
+}
+
+var AllRoles = []Role{
+	{
+		Name:        "admin",
+		Description: "Runs the site, apart from granting superuser",
+		Permissions: []Permission{ManageGames, ManageGroups, ManagePlayers, ManageUsers, ModerateResults, ViewAuditLog},
+	},
+	{
+		Name:        "moderator",
+		Description: "Keeps groups and results tidy",
+		Permissions: []Permission{ManageGroups, ModerateResults},
+	},
+	{
+		Name:        "curator",
+		Description: "Looks after the games catalogue",
+		Permissions: []Permission{ManageGames},
+	},
+}
+
+// IsValidPermission returns whether the permission is in the catalogue
+func IsValidPermission(permission Permission) bool {
+	return slices.ContainsFunc(AllPermissions, func(p PermissionInfo) bool {
+		return p.Name == permission
+	})
+}
+
+// FindRole returns the role with the given name
+func FindRole(name string) (bool, *Role) {
+	index := slices.IndexFunc(AllRoles, func(r Role) bool {
+		return r.Name == name
+	})
+
+	if index < 0 {
+		return false, nil
+	}
+
+	return true, &AllRoles[index]
+}
+
+// EffectivePermissions returns the user's own permissions plus those of their roles
+func (user *User) EffectivePermissions() []Permission {
+	permissions := []Permission{}
+
+	add := func(p Permission) {
+		if !slices.Contains(permissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	for _, p := range user.Permissions {
+		add(Permission(p))
+	}
+
+	for _, name := range user.Roles {
+		if exists, role := FindRole(name); exists {
+			for _, p := range role.Permissions {
+				add(p)
+			}
+		}
+	}
+
+	return permissions
+}
+
+// HasPermission returns whether the user has the permission, directly, through one of
+// their roles, or by being a superuser
+func (user *User) HasPermission(permission Permission) bool {
+	permissions := user.EffectivePermissions()
+	return slices.Contains(permissions, Superuser) || slices.Contains(permissions, permission)
+}
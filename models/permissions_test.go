@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	user := User{
+		Permissions: []string{string(ViewAuditLog)},
+		Roles:       []string{"curator", "unknown"},
+	}
+
+	tests := []struct {
+		permission Permission
+		expected   bool
+	}{
+		{ViewAuditLog, true},
+		{ManageGames, true},
+		{ManageUsers, false},
+	}
+
+	for _, test := range tests {
+		if actual := user.HasPermission(test.permission); actual != test.expected {
+			t.Errorf("Wrong result for %s! Expected: %t, actual: %t", test.permission, test.expected, actual)
+		}
+	}
+
+	superuser := User{
+		Permissions: []string{string(Superuser)},
+	}
+
+	if !superuser.HasPermission(ManageUsers) {
+		t.Error("Superuser does not have every permission")
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+// PrivacySettings controls who can see the user and how their results are counted
+type PrivacySettings struct {
+	ProfileVisibility ProfileVisibility `json:"profileVisibility" bson:"profileVisibility"`
+
+	// if set, results that include the user are hidden from other users and left out
+	// of leaderboards until the user approves them
+	ResultsNeedApproval bool `json:"resultsNeedApproval" bson:"resultsNeedApproval"`
+
+	// whether the user is shown in the leaderboards of public and global groups
+	ShowInPublicLeaderboards bool `json:"showInPublicLeaderboards" bson:"showInPublicLeaderboards"`
+}
+
+type ProfileVisibility string
+
+const (
+	VisibleToEveryone ProfileVisibility = "everyone"
+	VisibleToMembers  ProfileVisibility = "members" // users who share a group with the user
+	VisibleToFriends  ProfileVisibility = "friends"
+)
+
+// DefaultPrivacySettings returns the settings that new users start with
+func DefaultPrivacySettings() PrivacySettings {
+	return PrivacySettings{
+		ProfileVisibility:        VisibleToEveryone,
+		ResultsNeedApproval:      false,
+		ShowInPublicLeaderboards: true,
+	}
+}
+
+func IsValidProfileVisibility(visibility ProfileVisibility) bool {
+	return visibility == VisibleToEveryone || visibility == VisibleToMembers || visibility == VisibleToFriends
+}
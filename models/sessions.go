@@ -0,0 +1,20 @@
+package models
+
+// Session is a login on one device. It lasts as long as its refresh tokens keep
+// being used, or until it is revoked
+type Session struct {
+	ID           string `json:"id" bson:"id"`
+	Username     string `json:"username" bson:"username"`
+	TimeCreated  int64  `json:"timeCreated" bson:"timeCreated"`
+	TimeLastUsed int64  `json:"timeLastUsed" bson:"timeLastUsed"`
+	TimeExpires  int64  `json:"timeExpires" bson:"timeExpires"`
+	UserAgent    string `json:"userAgent" bson:"userAgent"`
+	IPAddress    string `json:"ipAddress" bson:"ipAddress"`
+	Revoked      bool   `json:"revoked" bson:"revoked"`
+	TimeRevoked  int64  `json:"timeRevoked" bson:"timeRevoked"`
+}
+
+// IsActive returns whether the session can still be used at the given time
+func (session *Session) IsActive(now int64) bool {
+	return !session.Revoked && now < session.TimeExpires
+}
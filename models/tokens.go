@@ -0,0 +1,35 @@
+package models
+
+// UserToken is a single-use, time-limited secret tied to a user, such as a
+// password reset token. Only a hash of the secret is stored. Data holds anything
+// specific to the token's purpose, e.g. the email address being verified or the
+// session that a refresh token belongs to
+type UserToken struct {
+	ID          string       `json:"id" bson:"id"`
+	Username    string       `json:"username" bson:"username"`
+	Purpose     TokenPurpose `json:"purpose" bson:"purpose"`
+	TokenHash   string       `json:"tokenHash" bson:"tokenHash"`
+	Data        string       `json:"data" bson:"data"`
+	TimeCreated int64        `json:"timeCreated" bson:"timeCreated"`
+	TimeExpires int64        `json:"timeExpires" bson:"timeExpires"`
+	Used        bool         `json:"used" bson:"used"`
+	TimeUsed    int64        `json:"timeUsed" bson:"timeUsed"`
+
+	// identifies the version of the token that was read, so that it can only be used once
+	ETag string `json:"-" bson:"-"`
+}
+
+type TokenPurpose string
+
+const (
+	PasswordResetToken     TokenPurpose = "password-reset"
+	EmailVerificationToken TokenPurpose = "email-verification"
+	RefreshToken           TokenPurpose = "refresh"
+	TwoFactorChallenge     TokenPurpose = "two-factor-challenge"
+	GuestClaimToken        TokenPurpose = "guest-claim"
+)
+
+// IsUsable returns whether the token can still be consumed at the given time
+func (token *UserToken) IsUsable(now int64) bool {
+	return !token.Used && now < token.TimeExpires
+}
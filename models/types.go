@@ -11,6 +11,9 @@ type Game struct {
 	WinMethod   string `json:"winMethod" bson:"winMethod"`
 	ImageLink   string `json:"imageLink" bson:"imageLink"`
 	Links       []Link `json:"links" bson:"links"`
+
+	// the name of the uploaded image, if any
+	UploadedImage string `json:"-" bson:"uploadedImage"`
 }
 
 type Link struct {
@@ -32,6 +35,7 @@ const (
 	BoardGameGeek   LinkTypeName = "board-game-geek"
 )
 
+// Player is the public view of a user, as returned by the /players endpoints
 type Player struct {
 	ID             string `json:"id" bson:"id"`
 	Username       string `json:"username" bson:"username"`
@@ -44,6 +48,9 @@ type PlayerScore struct {
 	Username string `json:"username" bson:"username"`
 	Score    int    `json:"score" bson:"score"`
 	IsWinner bool   `json:"isWinner" bson:"isWinner"`
+
+	// set instead of the username when posting a result, to add a new guest player
+	GuestName string `json:"guestName,omitempty" bson:"guestName,omitempty"`
 }
 
 type Result struct {
@@ -56,6 +63,9 @@ type Result struct {
 	CooperativeScore int           `json:"cooperativeScore" bson:"cooperativeScore"`
 	CooperativeWin   bool          `json:"cooperativeWin" bson:"cooperativeWin"`
 	Scores           []PlayerScore `json:"scores" bson:"scores"`
+
+	// when the players who haven't approved the result were last reminded to
+	TimeLastReminded int64 `json:"timeLastReminded" bson:"timeLastReminded"`
 }
 
 type WinMethod struct {
@@ -11,6 +11,70 @@ type User struct {
 	Email       string   `json:"email" bson:"email"`
 	Password    string   `json:"password" bson:"password"`
 	Permissions []string `json:"permissions" bson:"permissions"`
+	Roles       []string `json:"roles" bson:"roles"`
+
+	// disabled users can't log in or use their API keys
+	Disabled bool `json:"disabled" bson:"disabled"`
+
+	DisplayName    string `json:"displayName" bson:"displayName"`
+	ProfilePicture string `json:"profilePicture" bson:"profilePicture"`
+
+	// the name of the uploaded profile picture, if any. This is only ever set by the
+	// server, so that deleting it can't delete someone else's upload
+	UploadedPicture string `json:"-" bson:"uploadedPicture"`
+
+	EmailVerified bool `json:"emailVerified" bson:"emailVerified"`
+
+	// users who only log in with an external identity have a random password that
+	// nobody knows, so they can't confirm it
+	HasPassword bool `json:"hasPassword" bson:"hasPassword"`
+
+	TwoFactor TwoFactorSettings `json:"twoFactor" bson:"twoFactor"`
+
+	EmailPreferences EmailPreferences `json:"emailPreferences" bson:"emailPreferences"`
+
+	FriendRestrictions FriendRestrictions `json:"friendRestrictions" bson:"friendRestrictions"`
+
+	Privacy PrivacySettings `json:"privacy" bson:"privacy"`
+}
+
+// controls which optional emails the user receives. Emails that the user asks for,
+// such as password resets, are always sent
+type EmailPreferences struct {
+	Invitations       bool `json:"invitations" bson:"invitations"`
+	ApprovalReminders bool `json:"approvalReminders" bson:"approvalReminders"`
+}
+
+// TwoFactorSettings holds the user's TOTP secret, which is set as soon as they start
+// enrolling but only used once they've confirmed it with a code
+type TwoFactorSettings struct {
+	Enabled bool   `json:"enabled" bson:"enabled"`
+	Secret  string `json:"secret" bson:"secret"`
+
+	// the TOTP period of the last code that was used, so it can't be used again
+	LastStep int64 `json:"lastStep" bson:"lastStep"`
+
+	// hashes of the recovery codes that haven't been used yet
+	RecoveryCodes []string `json:"recoveryCodes" bson:"recoveryCodes"`
+}
+
+// DefaultEmailPreferences returns the preferences that new users start with
+func DefaultEmailPreferences() EmailPreferences {
+	return EmailPreferences{
+		Invitations:       true,
+		ApprovalReminders: true,
+	}
+}
+
+// ToPlayer returns the user's public profile
+func (user *User) ToPlayer() Player {
+	return Player{
+		ID:             user.ID,
+		Username:       user.Username,
+		TimeCreated:    user.TimeCreated,
+		DisplayName:    user.DisplayName,
+		ProfilePicture: user.ProfilePicture,
+	}
 }
 
 func (user *User) HashPassword(password string) error {
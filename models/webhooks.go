@@ -0,0 +1,43 @@
+package models
+
+type Webhook struct {
+	ID          string             `json:"id" bson:"id"`
+	GroupID     string             `json:"groupId" bson:"groupId"`
+	TimeCreated int64              `json:"timeCreated" bson:"timeCreated"`
+	CreatedBy   string             `json:"createdBy" bson:"createdBy"`
+	URL         string             `json:"url" bson:"url"`
+	Secret      string             `json:"secret" bson:"secret"`
+	Events      []WebhookEventType `json:"events" bson:"events"`
+}
+
+type WebhookEventType string
+
+const (
+	WebhookResultCreated  WebhookEventType = "result-created"
+	WebhookResultApproved WebhookEventType = "result-approved"
+	WebhookMemberJoined   WebhookEventType = "member-joined"
+)
+
+type WebhookDelivery struct {
+	ID              string           `json:"id" bson:"id"`
+	WebhookID       string           `json:"webhookId" bson:"webhookId"`
+	GroupID         string           `json:"groupId" bson:"groupId"`
+	TimeCreated     int64            `json:"timeCreated" bson:"timeCreated"`
+	EventType       WebhookEventType `json:"eventType" bson:"eventType"`
+	Payload         string           `json:"payload" bson:"payload"`
+	Status          DeliveryStatus   `json:"status" bson:"status"`
+	Attempts        int              `json:"attempts" bson:"attempts"`
+	TimeLastAttempt int64            `json:"timeLastAttempt" bson:"timeLastAttempt"`
+	TimeNextAttempt int64            `json:"timeNextAttempt" bson:"timeNextAttempt"`
+	ResponseStatus  int              `json:"responseStatus" bson:"responseStatus"`
+	LastError       string           `json:"lastError" bson:"lastError"`
+	ReplayOf        string           `json:"replayOf" bson:"replayOf"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"os"
+	"phrasmotica/bore-score-api/models"
+	"sync"
+)
+
+// TODO: put this in a more central place, or inject it as a dependency
+var (
+	Error *log.Logger = log.New(os.Stdout, "ERROR: ", log.LstdFlags|log.Lshortfile)
+)
+
+// Channel delivers notifications to users outside of the in-app inbox, e.g. by email or push
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, notification *models.Notification) error
+}
+
+// Dispatcher passes notifications on to every registered channel
+type Dispatcher struct {
+	mutex    sync.RWMutex
+	channels []Channel
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		channels: []Channel{},
+	}
+}
+
+func (d *Dispatcher) Register(channel Channel) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.channels = append(d.channels, channel)
+}
+
+// Dispatch delivers the notification through each channel. A channel failing
+// is logged but doesn't stop the others from being tried
+func (d *Dispatcher) Dispatch(ctx context.Context, notification *models.Notification) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	for _, channel := range d.channels {
+		if err := channel.Deliver(ctx, notification); err != nil {
+			Error.Printf("Could not deliver notification %s via %s: %s\n", notification.ID, channel.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// the example from RFC 7636, appendix B
+func TestCodeChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if actual := CodeChallenge(verifier); actual != expected {
+		t.Errorf("Wrong code challenge! Expected: %s, actual: %s", expected, actual)
+	}
+}
+
+type testServer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+// starts a minimal identity provider that issues an ID token with the server's claims
+// for the code "good"
+func newTestServer(t *testing.T) *testServer {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	s := &testServer{key: key}
+	mux := http.NewServeMux()
+	s.Server = httptest.NewServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		if r.PostForm.Get("code") != "good" || r.PostForm.Get("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, s.claims)
+		token.Header["kid"] = "test"
+		signed, _ := token.SignedString(key)
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestProvider(t *testing.T, s *testServer) *Provider {
+	p := &Provider{
+		Issuer:      s.URL,
+		ClientID:    "client",
+		RedirectURL: "http://localhost:3000/callback",
+		Scopes:      []string{"openid"},
+		Client:      s.Client(),
+	}
+
+	if err := p.Discover(context.Background()); err != nil {
+		t.Fatalf("Could not discover provider: %s", err)
+	}
+
+	return p
+}
+
+func TestExchange(t *testing.T) {
+	s := newTestServer(t)
+	p := newTestProvider(t, s)
+
+	authUrl, _ := url.Parse(p.AuthCodeURL("state", "nonce", "challenge"))
+	if authUrl.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("Authorization URL does not use PKCE: %s", authUrl)
+	}
+
+	s.claims = jwt.MapClaims{
+		"iss":   s.URL,
+		"aud":   "client",
+		"sub":   "12345",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce",
+		"email": "someone@example.com",
+	}
+
+	claims, err := p.Exchange(context.Background(), "good", "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("Could not exchange code: %s", err)
+	}
+
+	if claims.Subject != "12345" || claims.Email != "someone@example.com" {
+		t.Errorf("Wrong claims! Actual: %+v", claims)
+	}
+
+	if _, err := p.Exchange(context.Background(), "good", "verifier", "other nonce"); err == nil {
+		t.Error("ID token with the wrong nonce was accepted")
+	}
+
+	s.claims["aud"] = "other client"
+	if _, err := p.Exchange(context.Background(), "good", "verifier", "nonce"); err == nil {
+		t.Error("ID token for another client was accepted")
+	}
+}
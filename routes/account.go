@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how recently users without a password must have logged in to delete their account
+const recentLoginWindow = 10 * time.Minute
+
+// AccountExport is everything the API stores about a user, for them to download
+type AccountExport struct {
+	TimeExported int64                    `json:"timeExported"`
+	Profile      AccountExportProfile     `json:"profile"`
+	Memberships  []models.GroupMembership `json:"memberships"`
+	Invitations  []models.GroupInvitation `json:"invitations"`
+	Approvals    []models.Approval        `json:"approvals"`
+	Results      []models.Result          `json:"results"`
+	Friendships  []models.Friendship      `json:"friendships"`
+	Comments     []models.Comment         `json:"comments"`
+	Reactions    []models.Reaction        `json:"reactions"`
+}
+
+type AccountExportProfile struct {
+	Username           string                    `json:"username"`
+	Email              string                    `json:"email"`
+	DisplayName        string                    `json:"displayName"`
+	ProfilePicture     string                    `json:"profilePicture"`
+	TimeCreated        int64                     `json:"timeCreated"`
+	EmailVerified      bool                      `json:"emailVerified"`
+	EmailPreferences   models.EmailPreferences   `json:"emailPreferences"`
+	FriendRestrictions models.FriendRestrictions `json:"friendRestrictions"`
+	Privacy            models.PrivacySettings    `json:"privacy"`
+	TwoFactorEnabled   bool                      `json:"twoFactorEnabled"`
+	Permissions        []string                  `json:"permissions"`
+	Roles              []string                  `json:"roles"`
+}
+
+// ExportAccount downloads the calling user's profile, along with their group
+// memberships, invitations, approvals, results, friends, comments and reactions, as a JSON file
+func ExportAccount(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, export := createAccountExport(ctx, user)
+	if !success {
+		Error.Printf("Could not export account of user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Exported account of user %s\n", user.Username)
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.json\"", user.Username))
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// DeleteOwnAccount deletes the calling user's account, removing them from all results,
+// groups and invitations. Users with a password must confirm it to do this, and users
+// without one must have logged in recently
+func DeleteOwnAccount(c *gin.Context) {
+	var request PasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if user.HasPassword {
+		if credentialError := user.CheckPassword(request.Password); credentialError != nil {
+			Error.Println("Invalid password")
+			c.AbortWithError(http.StatusUnauthorized, credentialError)
+			return
+		}
+	} else if !hasLoggedInRecently(ctx, c, user.Username) {
+		Error.Printf("User %s must log in again before deleting their account\n", user.Username)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if success := deleteAccount(ctx, user.Username); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	recordAudit(ctx, &models.AuditEntry{
+		Type:           models.UserDeleted,
+		Username:       user.Username,
+		TargetUsername: user.Username,
+		IPAddress:      c.ClientIP(),
+	})
+
+	Info.Printf("User %s deleted their account\n", user.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// returns whether the request was made with a session that the user started recently
+func hasLoggedInRecently(ctx context.Context, c *gin.Context, username string) bool {
+	success, session := db.GetSession(ctx, username, c.GetString("sessionId"))
+	if !success {
+		return false
+	}
+
+	return time.Since(time.Unix(session.TimeCreated, 0)) <= recentLoginWindow
+}
+
+func createAccountExport(ctx context.Context, user *models.User) (bool, *AccountExport) {
+	export := AccountExport{
+		TimeExported: time.Now().UTC().Unix(),
+		Profile: AccountExportProfile{
+			Username:           user.Username,
+			Email:              user.Email,
+			DisplayName:        user.DisplayName,
+			ProfilePicture:     user.ProfilePicture,
+			TimeCreated:        user.TimeCreated,
+			EmailVerified:      user.EmailVerified,
+			EmailPreferences:   user.EmailPreferences,
+			FriendRestrictions: user.FriendRestrictions,
+			Privacy:            user.Privacy,
+			TwoFactorEnabled:   user.TwoFactor.Enabled,
+			Permissions:        user.Permissions,
+			Roles:              user.Roles,
+		},
+	}
+
+	success, memberships := db.GetGroupMemberships(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, invitations := db.GetGroupInvitationsInvolving(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, approvals := db.GetApprovalsByUser(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, results := db.GetResultsWithPlayer(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, friendships := db.GetFriendships(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, comments := db.GetCommentsByUser(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	success, reactions := db.GetReactionsByUser(ctx, user.Username)
+	if !success {
+		return false, nil
+	}
+
+	export.Memberships = memberships
+	export.Invitations = invitations
+	export.Approvals = approvals
+	export.Results = results
+	export.Friendships = friendships
+	export.Comments = comments
+	export.Reactions = reactions
+
+	return true, &export
+}
@@ -0,0 +1,86 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ActivityResponse struct {
+	GroupID    string            `json:"groupId" bson:"groupId"`
+	Page       int               `json:"page" bson:"page"`
+	PageSize   int               `json:"pageSize" bson:"pageSize"`
+	TotalCount int               `json:"totalCount" bson:"totalCount"`
+	Activity   []models.Activity `json:"activity" bson:"activity"`
+}
+
+func GetGroupActivity(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	success, page, pageSize := parsePagination(c)
+	if !success {
+		Error.Println("Invalid pagination parameters")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !canSeeGroup(ctx, group, callingUsername, false) {
+		Error.Printf("User %s cannot see activity for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	success, activity := db.GetActivityForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not get activity for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// newest first
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].TimeCreated > activity[j].TimeCreated
+	})
+
+	response := ActivityResponse{
+		GroupID:    group.ID,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: len(activity),
+		Activity:   paginate(activity, page, pageSize),
+	}
+
+	Info.Printf("Got %d activity records for group %s\n", len(response.Activity), group.ID)
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// adds an activity record to a group's feed, logging any failure
+func recordActivity(ctx context.Context, activity *models.Activity) {
+	if len(activity.GroupID) <= 0 {
+		return
+	}
+
+	activity.ID = uuid.NewString()
+	activity.TimeCreated = time.Now().UTC().Unix()
+
+	if success := db.AddActivity(ctx, activity); !success {
+		Error.Printf("Could not record %s activity for group %s\n", activity.Type, activity.GroupID)
+	}
+}
@@ -0,0 +1,417 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+type AdminUserResponse struct {
+	Username         string   `json:"username"`
+	Email            string   `json:"email"`
+	TimeCreated      int64    `json:"timeCreated"`
+	EmailVerified    bool     `json:"emailVerified"`
+	TwoFactorEnabled bool     `json:"twoFactorEnabled"`
+	Disabled         bool     `json:"disabled"`
+	Permissions      []string `json:"permissions"`
+	Roles            []string `json:"roles"`
+}
+
+type AdminUsersResponse struct {
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"pageSize"`
+	TotalCount int                 `json:"totalCount"`
+	Users      []AdminUserResponse `json:"users"`
+}
+
+type AuditLogResponse struct {
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"pageSize"`
+	TotalCount int                 `json:"totalCount"`
+	Entries    []models.AuditEntry `json:"entries"`
+}
+
+type SetPermissionsRequest struct {
+	Permissions []models.Permission `json:"permissions"`
+	Roles       []string            `json:"roles"`
+}
+
+// AdminGetUsers lists users, optionally only those whose username or email address
+// contains the "search" query parameter
+func AdminGetUsers(c *gin.Context) {
+	success, page, pageSize := parsePagination(c)
+	if !success {
+		Error.Println("Invalid pagination parameters")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, users := db.GetAllUsers(ctx)
+	if !success {
+		Error.Println("Could not get users")
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	search := strings.ToLower(c.Query("search"))
+
+	matches := []AdminUserResponse{}
+	for _, u := range users {
+		if strings.Contains(strings.ToLower(u.Username), search) || strings.Contains(strings.ToLower(u.Email), search) {
+			matches = append(matches, createAdminUserResponse(&u))
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Username < matches[j].Username
+	})
+
+	response := AdminUsersResponse{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: len(matches),
+		Users:      paginate(matches, page, pageSize),
+	}
+
+	Info.Printf("Got %d users\n", len(response.Users))
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// AdminGetUser gets a user, including the details that only admins can see
+func AdminGetUser(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("User %s does not exist\n", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, createAdminUserResponse(user))
+}
+
+// DisableUser stops a user from logging in or using their API keys, and logs them out
+// everywhere
+func DisableUser(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if username == callingUsername {
+		Error.Printf("User %s cannot disable themselves\n", username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, user := getManageableUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if !user.Disabled {
+		user.Disabled = true
+
+		if success := db.UpdateUser(ctx, user); !success {
+			Error.Printf("Could not disable user %s\n", username)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		revokeOtherSessions(ctx, username, "")
+
+		recordAudit(ctx, &models.AuditEntry{
+			Type:           models.UserDisabled,
+			Username:       callingUsername,
+			TargetUsername: username,
+			IPAddress:      c.ClientIP(),
+		})
+
+		Info.Printf("User %s disabled user %s\n", callingUsername, username)
+	}
+
+	c.IndentedJSON(http.StatusOK, createAdminUserResponse(user))
+}
+
+// EnableUser lets a disabled user log in again
+func EnableUser(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	success, user := getManageableUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if user.Disabled {
+		user.Disabled = false
+
+		if success := db.UpdateUser(ctx, user); !success {
+			Error.Printf("Could not enable user %s\n", username)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		recordAudit(ctx, &models.AuditEntry{
+			Type:           models.UserEnabled,
+			Username:       callingUsername,
+			TargetUsername: username,
+			IPAddress:      c.ClientIP(),
+		})
+
+		Info.Printf("User %s enabled user %s\n", callingUsername, username)
+	}
+
+	c.IndentedJSON(http.StatusOK, createAdminUserResponse(user))
+}
+
+// AdminDeleteUser deletes a user's account and removes them from all results
+func AdminDeleteUser(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	success, _ := getManageableUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if success := deleteAccount(ctx, username); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	recordAudit(ctx, &models.AuditEntry{
+		Type:           models.UserDeleted,
+		Username:       callingUsername,
+		TargetUsername: username,
+		IPAddress:      c.ClientIP(),
+	})
+
+	Info.Printf("User %s deleted user %s\n", callingUsername, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// ForcePasswordReset replaces a user's password with a random one, logs them out
+// everywhere and emails them a link to choose a new password
+func ForcePasswordReset(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	success, user := getManageableUser(ctx, c)
+	if !success {
+		return
+	}
+
+	password, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate password: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := user.HashPassword(password); err != nil {
+		Error.Println(err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not reset password of user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	revokeOtherSessions(ctx, username, "")
+
+	// the password has already been changed, so the user can still use the usual
+	// forgotten password flow if this fails
+	sendPasswordResetEmail(ctx, user)
+
+	recordAudit(ctx, &models.AuditEntry{
+		Type:           models.PasswordResetForced,
+		Username:       callingUsername,
+		TargetUsername: username,
+		IPAddress:      c.ClientIP(),
+	})
+
+	Info.Printf("User %s forced a password reset for user %s\n", callingUsername, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// SetPermissions replaces all of a user's permissions and roles
+func SetPermissions(c *gin.Context) {
+	var request SetPermissionsRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if valid, reason := validateSetPermissionsRequest(&request); !valid {
+		Error.Printf("Error validating set permissions request: %s\n", reason)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	permissions := []string{}
+	for _, p := range request.Permissions {
+		permissions = appendIfMissing(permissions, string(p))
+	}
+
+	roles := []string{}
+	for _, r := range request.Roles {
+		roles = appendIfMissing(roles, r)
+	}
+
+	ctx := context.TODO()
+
+	success, user := db.GetUser(ctx, c.Param("username"))
+	if !success {
+		Error.Printf("User %s does not exist\n", c.Param("username"))
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	// only superusers can make or unmake other superusers
+	wasSuperuser := slices.Contains(user.Permissions, string(models.Superuser))
+	isSuperuser := slices.Contains(permissions, string(models.Superuser))
+
+	if wasSuperuser != isSuperuser && !UserHasPermission(c.GetString("username"), models.Superuser) {
+		Error.Printf("User %s cannot change the %s permission\n", c.GetString("username"), models.Superuser)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	details := fmt.Sprintf("permissions: %s; roles: %s", strings.Join(permissions, ","), strings.Join(roles, ","))
+
+	updateUserPermissions(c, models.PermissionsSet, details, func(user *models.User) bool {
+		user.Permissions = permissions
+		user.Roles = roles
+		return true
+	})
+}
+
+// GetAuditLog lists the audit log, newest first. It can be filtered to entries by or
+// about a user with the "username" query parameter, and to one type of entry with the
+// "type" query parameter
+func GetAuditLog(c *gin.Context) {
+	success, page, pageSize := parsePagination(c)
+	if !success {
+		Error.Println("Invalid pagination parameters")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, entries := db.GetAuditEntries(ctx)
+	if !success {
+		Error.Println("Could not get audit log")
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	username := c.Query("username")
+	auditType := models.AuditType(c.Query("type"))
+
+	matches := []models.AuditEntry{}
+	for _, e := range entries {
+		if len(username) > 0 && e.Username != username && e.TargetUsername != username {
+			continue
+		}
+
+		if len(auditType) > 0 && e.Type != auditType {
+			continue
+		}
+
+		matches = append(matches, e)
+	}
+
+	// newest first
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].TimeCreated > matches[j].TimeCreated
+	})
+
+	response := AuditLogResponse{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: len(matches),
+		Entries:    paginate(matches, page, pageSize),
+	}
+
+	Info.Printf("Got %d audit log entries\n", len(response.Entries))
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// gets the user in the username parameter, aborting the request if they don't exist or
+// are a superuser that the calling user can't manage
+func getManageableUser(ctx context.Context, c *gin.Context) (bool, *models.User) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("User %s does not exist\n", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	if user.HasPermission(models.Superuser) && !UserHasPermission(callingUsername, models.Superuser) {
+		Error.Printf("User %s cannot manage superuser %s\n", callingUsername, username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return false, nil
+	}
+
+	return true, user
+}
+
+func validateSetPermissionsRequest(request *SetPermissionsRequest) (bool, string) {
+	for _, p := range request.Permissions {
+		if !models.IsValidPermission(p) {
+			return false, fmt.Sprintf("permission %s does not exist", p)
+		}
+	}
+
+	for _, r := range request.Roles {
+		if exists, _ := models.FindRole(r); !exists {
+			return false, fmt.Sprintf("role %s does not exist", r)
+		}
+	}
+
+	return true, ""
+}
+
+func createAdminUserResponse(user *models.User) AdminUserResponse {
+	return AdminUserResponse{
+		Username:         user.Username,
+		Email:            user.Email,
+		TimeCreated:      user.TimeCreated,
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TwoFactor.Enabled,
+		Disabled:         user.Disabled,
+		Permissions:      user.Permissions,
+		Roles:            user.Roles,
+	}
+}
@@ -0,0 +1,271 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how many characters of a key are kept so that the user can recognise it
+const apiKeyPrefixLength = 12
+
+// how often a key's last-used time is updated, so that busy scripts don't cause a
+// write on every request
+const apiKeyLastUsedInterval = time.Minute
+
+type CreateAPIKeyRequest struct {
+	Name    string               `json:"name"`
+	Scopes  []models.APIKeyScope `json:"scopes"`
+	GroupID string               `json:"groupId"`
+}
+
+type APIKeyResponse struct {
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	Prefix       string               `json:"prefix"`
+	Scopes       []models.APIKeyScope `json:"scopes"`
+	GroupID      string               `json:"groupId"`
+	TimeCreated  int64                `json:"timeCreated"`
+	TimeLastUsed int64                `json:"timeLastUsed"`
+
+	// only set when the key is created, since it can't be retrieved afterwards
+	Key string `json:"key,omitempty"`
+}
+
+// GetAPIKeys lists the calling user's API keys that haven't been revoked
+func GetAPIKeys(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot get the API keys of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, keys := db.GetAPIKeys(ctx, username)
+	if !success {
+		Error.Printf("Could not get API keys for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	responses := make([]APIKeyResponse, 0)
+
+	for _, k := range keys {
+		if !k.Revoked {
+			responses = append(responses, createAPIKeyResponse(&k))
+		}
+	}
+
+	Info.Printf("Got %d API keys for user %s\n", len(responses), username)
+
+	c.IndentedJSON(http.StatusOK, responses)
+}
+
+// PostAPIKey creates an API key for the calling user. The key is only returned in this response
+func PostAPIKey(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	var request CreateAPIKeyRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateCreateAPIKeyRequest(&request); !success {
+		Error.Printf("Error validating new API key: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if callingUsername != username {
+		Error.Println("Cannot create API keys for a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if len(request.GroupID) > 0 && !db.IsInGroup(ctx, request.GroupID, username) {
+		Error.Printf("User %s is not in group %s\n", username, request.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	token, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate API key: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	key := auth.APIKeyPrefix + token
+
+	newKey := models.APIKey{
+		Username: username,
+		Name:     request.Name,
+		Prefix:   key[:apiKeyPrefixLength],
+		KeyHash:  auth.HashOpaqueToken(key),
+		Scopes:   request.Scopes,
+		GroupID:  request.GroupID,
+	}
+
+	if success := db.AddAPIKey(ctx, &newKey); !success {
+		Error.Printf("Could not add API key for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Created API key %s for user %s\n", newKey.ID, username)
+
+	response := createAPIKeyResponse(&newKey)
+	response.Key = key
+
+	c.IndentedJSON(http.StatusCreated, response)
+}
+
+// RevokeAPIKey stops one of the calling user's API keys from working
+func RevokeAPIKey(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	apiKeyId := c.Param("apiKeyId")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot revoke the API keys of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, keys := db.GetAPIKeys(ctx, username)
+	if !success {
+		Error.Printf("Could not get API keys for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	var key *models.APIKey
+	for i := range keys {
+		if keys[i].ID == apiKeyId {
+			key = &keys[i]
+		}
+	}
+
+	if key == nil {
+		Error.Printf("API key %s of user %s does not exist\n", apiKeyId, username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if !key.Revoked {
+		key.Revoked = true
+		key.TimeRevoked = time.Now().UTC().Unix()
+
+		if success := db.UpdateAPIKey(ctx, key); !success {
+			Error.Printf("Could not revoke API key %s\n", apiKeyId)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	Info.Printf("Revoked API key %s of user %s\n", apiKeyId, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+func validateCreateAPIKeyRequest(request *CreateAPIKeyRequest) (bool, string) {
+	if len(request.Name) <= 0 {
+		return false, "name is missing"
+	}
+
+	if len(request.Scopes) <= 0 {
+		return false, "scopes are missing"
+	}
+
+	for _, s := range request.Scopes {
+		if !models.IsValidAPIKeyScope(s) {
+			return false, "scope " + string(s) + " is invalid"
+		}
+	}
+
+	return true, ""
+}
+
+// AuthenticateAPIKey returns who the given API key acts as, if it exists and has not
+// been revoked
+func AuthenticateAPIKey(key string) (bool, *auth.APIKeyIdentity) {
+	ctx := context.TODO()
+
+	success, apiKey := db.GetAPIKeyByHash(ctx, auth.HashOpaqueToken(key))
+	if !success || apiKey.Revoked {
+		return false, nil
+	}
+
+	success, user := db.GetUser(ctx, apiKey.Username)
+	if !success || user.Disabled {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	if now.Sub(time.Unix(apiKey.TimeLastUsed, 0)) > apiKeyLastUsedInterval {
+		apiKey.TimeLastUsed = now.Unix()
+
+		if success := db.UpdateAPIKey(ctx, apiKey); !success {
+			Error.Printf("Could not update last-used time of API key %s\n", apiKey.ID)
+		}
+	}
+
+	return true, &auth.APIKeyIdentity{
+		KeyID:    apiKey.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Scopes:   apiKey.Scopes,
+		GroupID:  apiKey.GroupID,
+	}
+}
+
+// returns whether the request can act on the given group. This is only false if the
+// request was made with an API key that is restricted to a different group
+func apiKeyAllowsGroup(c *gin.Context, groupId string) bool {
+	keyGroupId := c.GetString("apiKeyGroupId")
+	return keyGroupId == "" || keyGroupId == groupId
+}
+
+// removes results in groups that the request can't act on
+func restrictToAPIKeyGroup(c *gin.Context, results []models.Result) []models.Result {
+	if c.GetString("apiKeyGroupId") == "" {
+		return results
+	}
+
+	restricted := []models.Result{}
+
+	for _, r := range results {
+		if apiKeyAllowsGroup(c, r.GroupID) {
+			restricted = append(restricted, r)
+		}
+	}
+
+	return restricted
+}
+
+func createAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:           key.ID,
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		Scopes:       key.Scopes,
+		GroupID:      key.GroupID,
+		TimeCreated:  key.TimeCreated,
+		TimeLastUsed: key.TimeLastUsed,
+	}
+}
@@ -2,13 +2,20 @@ package routes
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"phrasmotica/bore-score-api/events"
 	"phrasmotica/bore-score-api/models"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/exp/slices"
 )
 
+// how long players have to wait before sending more approval reminders for a result
+const approvalReminderCooldown = 24 * time.Hour
+
 func GetApprovals(c *gin.Context) {
 	resultId := c.Param("resultId")
 
@@ -63,6 +70,12 @@ func PostApproval(c *gin.Context) {
 		return
 	}
 
+	if isGroupArchived(ctx, result.GroupID) {
+		Error.Printf("Group %s is archived\n", result.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	isInResult := slices.ContainsFunc(result.Scores, func(s models.PlayerScore) bool {
 		return newApproval.Username == s.Username
 	})
@@ -73,6 +86,9 @@ func PostApproval(c *gin.Context) {
 		return
 	}
 
+	previousApprovalStatus := computeOverallApproval(ctx, db, result)
+	wasAwaitingPrivateApproval := isAwaitingPrivateApproval(ctx, result)
+
 	if success := db.AddApproval(ctx, &newApproval); !success {
 		Error.Println("Could not add approval")
 		c.AbortWithStatus(http.StatusServiceUnavailable)
@@ -81,5 +97,129 @@ func PostApproval(c *gin.Context) {
 
 	Info.Printf("Added approval for result %s\n", newApproval.ResultID)
 
+	// the result wasn't announced when it was posted because it was still private, and its
+	// approvals aren't announced until it's been made public
+	if !isAwaitingPrivateApproval(ctx, result) {
+		if len(result.GroupID) > 0 && wasAwaitingPrivateApproval {
+			announceResult(ctx, result)
+		}
+
+		publishGroupEvent(result.GroupID, events.ApprovalAdded, newApproval)
+	}
+
+	if newApproval.ApprovalStatus == models.Rejected {
+		for _, score := range result.Scores {
+			if score.Username != newApproval.Username {
+				notify(ctx, &models.Notification{
+					Username:     score.Username,
+					Type:         models.ResultRejectedNotification,
+					FromUsername: newApproval.Username,
+					GroupID:      result.GroupID,
+					ResultID:     result.ID,
+				})
+			}
+		}
+	}
+
+	// announce the result once everyone in it has approved it
+	if previousApprovalStatus != models.Approved {
+		resultResponse := createResultResponse(ctx, result)
+		if resultResponse.ApprovalStatus == models.Approved {
+			triggerWebhooks(ctx, result.GroupID, models.WebhookResultApproved, resultResponse)
+		}
+	}
+
+	if newApproval.ApprovalStatus != models.Pending {
+		activityType := models.ResultApproved
+		if newApproval.ApprovalStatus == models.Rejected {
+			activityType = models.ResultRejected
+		}
+
+		recordActivity(ctx, &models.Activity{
+			GroupID:  result.GroupID,
+			Type:     activityType,
+			Username: newApproval.Username,
+			ResultID: result.ID,
+			GameID:   result.GameID,
+		})
+	}
+
 	c.IndentedJSON(http.StatusCreated, newApproval)
 }
+
+func RemindApprovals(c *gin.Context) {
+	resultId := c.Param("resultId")
+	callingUsername := c.GetString("username")
+
+	if !mailEnabled {
+		Error.Println("Cannot send approval reminders because emails are disabled")
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, result := db.GetResult(ctx, resultId)
+	if !success {
+		Error.Printf("Result %s does not exist\n", resultId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	isInResult := slices.ContainsFunc(result.Scores, func(s models.PlayerScore) bool {
+		return callingUsername == s.Username
+	})
+
+	if !isInResult {
+		Error.Printf("Player %s does not have a score in result %s\n", callingUsername, result.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	now := time.Now().UTC()
+
+	retryAfter := time.Unix(result.TimeLastReminded, 0).Add(approvalReminderCooldown).Sub(now)
+	if retryAfter > 0 {
+		Error.Printf("Approval reminders for result %s were sent too recently\n", result.ID)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatus(http.StatusTooManyRequests)
+		return
+	}
+
+	success, approvals := db.GetApprovals(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get approvals for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	latestApprovals := computeLatestApprovals(approvals)
+
+	result.TimeLastReminded = now.Unix()
+
+	if success := db.UpdateResultReminder(ctx, result); !success {
+		Error.Printf("Could not record approval reminders for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	remindedCount := 0
+
+	for _, s := range result.Scores {
+		hasResponded := slices.ContainsFunc(latestApprovals, func(a models.Approval) bool {
+			return a.Username == s.Username && a.ApprovalStatus != models.Pending
+		})
+
+		if s.Username == callingUsername || hasResponded {
+			continue
+		}
+
+		if sendApprovalReminderEmail(ctx, s.Username, callingUsername, result) {
+			remindedCount++
+		}
+	}
+
+	Info.Printf("Sent %d approval reminders for result %s\n", remindedCount, result.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,261 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the most images that can be attached to one result
+const maxAttachments = 20
+
+// GetAttachments gets the images attached to a result
+func GetAttachments(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	success, attachments := db.GetAttachments(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get attachments for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	for i := range attachments {
+		setAttachmentURLs(&attachments[i])
+	}
+
+	Info.Printf("Got %d attachments for result %s\n", len(attachments), result.ID)
+
+	c.IndentedJSON(http.StatusOK, attachments)
+}
+
+// GetAttachmentImage serves an image attached to a result
+func GetAttachmentImage(c *gin.Context) {
+	success, attachment := getVisibleAttachment(context.TODO(), c)
+	if !success {
+		return
+	}
+
+	// access to the result can be taken away, so the image can't be cached for long
+	serveImage(c, attachment.BlobName, "private, max-age=3600")
+}
+
+// GetAttachmentThumbnail serves the thumbnail of an image attached to a result
+func GetAttachmentThumbnail(c *gin.Context) {
+	success, attachment := getVisibleAttachment(context.TODO(), c)
+	if !success {
+		return
+	}
+
+	serveImage(c, thumbnailName(attachment.BlobName), "private, max-age=3600")
+}
+
+// PostAttachment attaches an uploaded image to a result. Players in the result, and
+// members of its group, can do this
+func PostAttachment(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !canContributeToResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot attach images to result %s\n", callingUsername, result.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if isGroupArchived(ctx, result.GroupID) {
+		Error.Printf("Group %s is archived\n", result.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, attachments := db.GetAttachments(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get attachments for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if len(attachments) >= maxAttachments {
+		Error.Printf("Result %s already has %d attachments\n", result.ID, len(attachments))
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	success, image := readUploadedImage(c)
+	if !success {
+		return
+	}
+
+	success, name := storeImage(ctx, image, attachmentPrefix)
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	newAttachment := models.Attachment{
+		ResultID:   result.ID,
+		UploadedBy: callingUsername,
+		BlobName:   name,
+	}
+
+	if success := db.AddAttachment(ctx, &newAttachment); !success {
+		Error.Printf("Could not add attachment to result %s\n", result.ID)
+		deleteImage(ctx, name)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Added attachment %s to result %s\n", newAttachment.ID, result.ID)
+
+	setAttachmentURLs(&newAttachment)
+
+	c.IndentedJSON(http.StatusCreated, newAttachment)
+}
+
+// DeleteAttachment removes an image from a result. Only the user who uploaded it, or an
+// admin of the result's group, can do this
+func DeleteAttachment(c *gin.Context) {
+	attachmentId := c.Param("attachmentId")
+
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	success, attachment := db.GetAttachment(ctx, result.ID, attachmentId)
+	if !success {
+		Error.Printf("Attachment %s does not exist\n", attachmentId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if attachment.UploadedBy != callingUsername && !canModerateResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot delete attachment %s\n", callingUsername, attachmentId)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if success := deleteAttachment(ctx, attachment); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Deleted attachment %s from result %s\n", attachmentId, result.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// gets the result in the resultId parameter, aborting the request if the caller can't see it
+// or their API key is limited to another group
+func getVisibleResult(ctx context.Context, c *gin.Context) (bool, *models.Result) {
+	resultId := c.Param("resultId")
+
+	success, result := db.GetResult(ctx, resultId)
+	if !success || !apiKeyAllowsGroup(c, result.GroupID) || !canSeeResult(ctx, *result, c.GetString("username")) {
+		Error.Printf("Result %s does not exist\n", resultId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	return true, result
+}
+
+// gets the attachment in the attachmentId parameter on the result in the resultId
+// parameter, aborting the request if the caller can't see it
+func getVisibleAttachment(ctx context.Context, c *gin.Context) (bool, *models.Attachment) {
+	attachmentId := c.Param("attachmentId")
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return false, nil
+	}
+
+	success, attachment := db.GetAttachment(ctx, result.ID, attachmentId)
+	if !success {
+		Error.Printf("Attachment %s does not exist\n", attachmentId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	return true, attachment
+}
+
+// sets the URLs that the attachment's image and thumbnail are served from
+func setAttachmentURLs(attachment *models.Attachment) {
+	path := fmt.Sprintf("/results/%s/attachments/%s", attachment.ResultID, attachment.ID)
+
+	attachment.URL = path + "/image"
+	attachment.ThumbnailURL = path + "/thumbnail"
+}
+
+// returns whether the user played in the result or is a member of its group
+func canContributeToResult(ctx context.Context, result *models.Result, username string) bool {
+	if len(username) <= 0 {
+		return false
+	}
+
+	return hasPlayer(result, username) || (len(result.GroupID) > 0 && db.IsInGroup(ctx, result.GroupID, username))
+}
+
+// returns whether the user is an admin of the result's group, or can moderate results
+// in any group
+func canModerateResult(ctx context.Context, result *models.Result, username string) bool {
+	if UserHasPermission(username, models.ModerateResults) {
+		return true
+	}
+
+	if len(result.GroupID) <= 0 {
+		return false
+	}
+
+	success, group := db.GetGroup(ctx, result.GroupID)
+	return success && isGroupAdmin(group, username)
+}
+
+func deleteAttachment(ctx context.Context, attachment *models.Attachment) bool {
+	if success := db.DeleteAttachment(ctx, attachment.ResultID, attachment.ID); !success {
+		Error.Printf("Could not delete attachment %s\n", attachment.ID)
+		return false
+	}
+
+	deleteImage(ctx, attachment.BlobName)
+
+	return true
+}
+
+// deletes the result's attachments along with their images
+func deleteAttachments(ctx context.Context, resultId string) bool {
+	success, attachments := db.GetAttachments(ctx, resultId)
+	if !success {
+		Error.Printf("Could not get attachments for result %s\n", resultId)
+		return false
+	}
+
+	for _, a := range attachments {
+		if success := deleteAttachment(ctx, &a); !success {
+			return false
+		}
+	}
+
+	Info.Printf("Deleted %d attachments for result %s\n", len(attachments), resultId)
+
+	return true
+}
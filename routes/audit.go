@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"context"
+	"phrasmotica/bore-score-api/models"
+)
+
+// adds an entry to the audit log
+func recordAudit(ctx context.Context, entry *models.AuditEntry) {
+	if success := db.AddAuditEntry(ctx, entry); !success {
+		Error.Printf("Could not add %s audit entry\n", entry.Type)
+	}
+}
@@ -0,0 +1,366 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/events"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+type CommentRequest struct {
+	Text     string `json:"text"`
+	ParentID string `json:"parentId"`
+}
+
+// CommentResponse is a comment along with its replies, oldest first
+type CommentResponse struct {
+	ID          string            `json:"id"`
+	ParentID    string            `json:"parentId"`
+	TimeCreated int64             `json:"timeCreated"`
+	Username    string            `json:"username"`
+	Text        string            `json:"text"`
+	TimeEdited  int64             `json:"timeEdited"`
+	Deleted     bool              `json:"deleted"`
+	DeletedBy   string            `json:"deletedBy"`
+	Replies     []CommentResponse `json:"replies"`
+}
+
+// GetComments gets the comments on a result as threads, oldest first
+func GetComments(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	success, comments := db.GetComments(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get comments for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	threads := buildCommentThreads(comments)
+
+	Info.Printf("Got %d comment threads for result %s\n", len(threads), result.ID)
+
+	c.IndentedJSON(http.StatusOK, threads)
+}
+
+// PostComment adds a comment to a result, or a reply to one of its comments. Players in
+// the result, and members of its group, can do this
+func PostComment(c *gin.Context) {
+	var request CommentRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateComment(request.Text); !success {
+		Error.Printf("Error validating comment: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !canContributeToResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot comment on result %s\n", callingUsername, result.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if isGroupArchived(ctx, result.GroupID) {
+		Error.Printf("Group %s is archived\n", result.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	var parent *models.Comment
+
+	if len(request.ParentID) > 0 {
+		success, parent = db.GetComment(ctx, result.ID, request.ParentID)
+		if !success || parent.Deleted {
+			Error.Printf("Comment %s does not exist on result %s\n", request.ParentID, result.ID)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+	}
+
+	newComment := models.Comment{
+		ResultID: result.ID,
+		ParentID: request.ParentID,
+		Username: callingUsername,
+		Text:     strings.TrimSpace(request.Text),
+	}
+
+	if success := db.AddComment(ctx, &newComment); !success {
+		Error.Printf("Could not add comment to result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Added comment %s to result %s\n", newComment.ID, result.ID)
+
+	activity := models.Activity{
+		GroupID:  result.GroupID,
+		Type:     models.CommentPosted,
+		Username: callingUsername,
+		ResultID: result.ID,
+		GameID:   result.GameID,
+	}
+
+	if parent != nil {
+		activity.TargetUsername = parent.Username
+	}
+
+	recordActivity(ctx, &activity)
+
+	publishGroupEvent(result.GroupID, events.CommentAdded, newComment)
+
+	notifyComment(ctx, result, &newComment, parent)
+
+	c.IndentedJSON(http.StatusCreated, newComment)
+}
+
+// EditComment changes the text of a comment. Only its author can do this
+func EditComment(c *gin.Context) {
+	var request CommentRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateComment(request.Text); !success {
+		Error.Printf("Error validating comment: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, result, comment := getComment(ctx, c)
+	if !success {
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if comment.Username != callingUsername {
+		Error.Printf("User %s cannot edit comment %s by someone else\n", callingUsername, comment.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if isGroupArchived(ctx, result.GroupID) {
+		Error.Printf("Group %s is archived\n", result.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	comment.Text = strings.TrimSpace(request.Text)
+	comment.TimeEdited = time.Now().UTC().Unix()
+
+	if success := db.UpdateComment(ctx, comment); !success {
+		Error.Printf("Could not update comment %s\n", comment.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Edited comment %s on result %s\n", comment.ID, result.ID)
+
+	c.IndentedJSON(http.StatusOK, comment)
+}
+
+// DeleteComment removes the text of a comment, leaving its replies in place. Its author
+// can do this, as can admins of the result's group
+func DeleteComment(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, result, comment := getComment(ctx, c)
+	if !success {
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	isAuthor := comment.Username == callingUsername
+	if !isAuthor && !canModerateResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot delete comment %s\n", callingUsername, comment.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	comment.Text = ""
+	comment.Deleted = true
+	comment.DeletedBy = callingUsername
+
+	if success := db.UpdateComment(ctx, comment); !success {
+		Error.Printf("Could not delete comment %s\n", comment.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if !isAuthor {
+		recordAudit(ctx, &models.AuditEntry{
+			Type:           models.CommentRemoved,
+			Username:       callingUsername,
+			TargetUsername: comment.Username,
+			IPAddress:      c.ClientIP(),
+			Details:        comment.ID,
+		})
+	}
+
+	Info.Printf("Deleted comment %s on result %s\n", comment.ID, result.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+func validateComment(text string) (bool, string) {
+	text = strings.TrimSpace(text)
+
+	if len(text) <= 0 {
+		return false, "comment text is missing"
+	}
+
+	if utf8.RuneCountInString(text) > models.MaxCommentLength {
+		return false, "comment text is too long"
+	}
+
+	return true, ""
+}
+
+// gets the comment in the commentId parameter on the result in the resultId parameter,
+// aborting the request if the caller can't see it or it's been deleted
+func getComment(ctx context.Context, c *gin.Context) (bool, *models.Result, *models.Comment) {
+	commentId := c.Param("commentId")
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return false, nil, nil
+	}
+
+	success, comment := db.GetComment(ctx, result.ID, commentId)
+	if !success || comment.Deleted {
+		Error.Printf("Comment %s does not exist\n", commentId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil, nil
+	}
+
+	return true, result, comment
+}
+
+// notifies the players in the result about a new comment, and the author of the comment
+// it replies to, if any
+func notifyComment(ctx context.Context, result *models.Result, comment *models.Comment, parent *models.Comment) {
+	notified := []string{comment.Username}
+
+	if parent != nil && len(parent.Username) > 0 && parent.Username != comment.Username {
+		notify(ctx, &models.Notification{
+			Username:     parent.Username,
+			Type:         models.CommentReplyNotification,
+			FromUsername: comment.Username,
+			GroupID:      result.GroupID,
+			ResultID:     result.ID,
+		})
+
+		notified = append(notified, parent.Username)
+	}
+
+	for _, s := range result.Scores {
+		if len(s.Username) <= 0 || models.IsGuestUsername(s.Username) || slices.Contains(notified, s.Username) {
+			continue
+		}
+
+		notify(ctx, &models.Notification{
+			Username:     s.Username,
+			Type:         models.CommentNotification,
+			FromUsername: comment.Username,
+			GroupID:      result.GroupID,
+			ResultID:     result.ID,
+		})
+
+		notified = append(notified, s.Username)
+	}
+}
+
+// arranges the comments into threads, oldest first. Deleted comments are only kept if
+// they have replies
+func buildCommentThreads(comments []models.Comment) []CommentResponse {
+	sorted := slices.Clone(comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TimeCreated < sorted[j].TimeCreated
+	})
+
+	children := map[string][]models.Comment{}
+	for _, c := range sorted {
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+
+	var build func(parentId string) []CommentResponse
+	build = func(parentId string) []CommentResponse {
+		threads := []CommentResponse{}
+
+		for _, c := range children[parentId] {
+			replies := build(c.ID)
+
+			if c.Deleted && len(replies) <= 0 {
+				continue
+			}
+
+			threads = append(threads, CommentResponse{
+				ID:          c.ID,
+				ParentID:    c.ParentID,
+				TimeCreated: c.TimeCreated,
+				Username:    c.Username,
+				Text:        c.Text,
+				TimeEdited:  c.TimeEdited,
+				Deleted:     c.Deleted,
+				DeletedBy:   c.DeletedBy,
+				Replies:     replies,
+			})
+		}
+
+		return threads
+	}
+
+	return build("")
+}
+
+// deletes the result's comments and reactions
+func deleteComments(ctx context.Context, resultId string) bool {
+	success, deletedCount := db.DeleteComments(ctx, resultId)
+	if !success {
+		Error.Printf("Could not delete comments for result %s\n", resultId)
+		return false
+	}
+
+	Info.Printf("Deleted %d comments for result %s\n", deletedCount, resultId)
+
+	success, deletedCount = db.DeleteReactions(ctx, resultId)
+	if !success {
+		Error.Printf("Could not delete reactions for result %s\n", resultId)
+		return false
+	}
+
+	Info.Printf("Deleted %d reactions for result %s\n", deletedCount, resultId)
+
+	return true
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"phrasmotica/bore-score-api/models"
+	"testing"
+)
+
+func TestBuildCommentThreads(t *testing.T) {
+	comments := []models.Comment{
+		{ID: "reply", ParentID: "first", TimeCreated: 3, Text: "reply"},
+		{ID: "second", TimeCreated: 2, Deleted: true},
+		{ID: "first", TimeCreated: 1, Deleted: true},
+		{ID: "third", TimeCreated: 4, Text: "third"},
+	}
+
+	threads := buildCommentThreads(comments)
+
+	if len(threads) != 2 {
+		t.Fatalf("Wrong number of threads! Expected: 2, actual: %d", len(threads))
+	}
+
+	// the deleted comment with a reply is kept, the one without is dropped
+	if threads[0].ID != "first" || threads[1].ID != "third" {
+		t.Errorf("Threads were in the wrong order! Actual: %s, %s", threads[0].ID, threads[1].ID)
+	}
+
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].ID != "reply" {
+		t.Errorf("Reply was not threaded under its parent! Actual: %v", threads[0].Replies)
+	}
+
+	if threads[1].Replies == nil {
+		t.Error("Comment without replies had nil replies")
+	}
+}
+
+func TestSummariseReactions(t *testing.T) {
+	reactions := []models.Reaction{
+		{Username: "player1", Emoji: "🎲", TimeCreated: 1},
+		{Username: "player2", Emoji: "👍", TimeCreated: 2},
+		{Username: "player3", Emoji: "👍", TimeCreated: 3},
+		{Username: "player1", Emoji: "🔥", TimeCreated: 4},
+	}
+
+	summaries := summariseReactions(reactions)
+
+	expected := []struct {
+		emoji string
+		count int
+	}{
+		{"👍", 2},
+		{"🎲", 1},
+		{"🔥", 1},
+	}
+
+	if len(summaries) != len(expected) {
+		t.Fatalf("Wrong number of summaries! Expected: %d, actual: %d", len(expected), len(summaries))
+	}
+
+	for i, e := range expected {
+		if summaries[i].Emoji != e.emoji || summaries[i].Count != e.count {
+			t.Errorf("Summary %d was incorrect! Expected: %s x%d, actual: %s x%d", i, e.emoji, e.count, summaries[i].Emoji, summaries[i].Count)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/mail"
+	"phrasmotica/bore-score-api/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how long an email verification link can be used for
+const emailVerificationTokenLifetime = 24 * time.Hour
+
+// whether users must verify their email address before they can log in, or before
+// they can send or receive group invitations
+var (
+	requireVerifiedEmailForLogin       = envFlag("REQUIRE_VERIFIED_EMAIL_FOR_LOGIN")
+	requireVerifiedEmailForInvitations = envFlag("REQUIRE_VERIFIED_EMAIL_FOR_INVITATIONS")
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" bson:"token"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" bson:"email"`
+	Password string `json:"password" bson:"password"`
+}
+
+// VerifyEmail confirms the email address that the given verification token was sent to
+func VerifyEmail(c *gin.Context) {
+	ctx := context.TODO()
+
+	var request VerifyEmailRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Token) <= 0 {
+		Error.Println("Error validating verify email request: token is missing")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, token := consumeUserToken(ctx, request.Token, models.EmailVerificationToken)
+	if !success {
+		Error.Println("Email verification token is invalid or has expired")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	exists, user := db.GetUser(ctx, token.Username)
+	if !exists {
+		Error.Printf("User %s does not exist", token.Username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	// the token is for a new email address, which only replaces the current one now
+	if token.Data != user.Email {
+		if db.UserExistsByEmail(ctx, token.Data) {
+			Error.Printf("Email address for user %s is already in use\n", user.Username)
+			c.AbortWithStatus(http.StatusConflict)
+			return
+		}
+
+		user.Email = token.Data
+	}
+
+	user.EmailVerified = true
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not verify email address for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	invalidateUserTokens(ctx, user.Username, models.EmailVerificationToken)
+
+	Info.Printf("Verified email address for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// ResendVerificationEmail sends a new verification link for the user's current email address
+func ResendVerificationEmail(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot verify the email address of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	exists, user := db.GetUser(ctx, username)
+	if !exists {
+		Error.Printf("User %s does not exist", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if user.EmailVerified {
+		Info.Printf("User %s has already verified their email address\n", username)
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := sendVerificationEmail(ctx, user, user.Email); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// ChangeEmail sends a verification link to a new email address for the user. The
+// user's email address only changes once the new one has been verified
+func ChangeEmail(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	var request ChangeEmailRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateChangeEmailRequest(&request); !success {
+		Error.Printf("Error validating change email request: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if callingUsername != username {
+		Error.Println("Cannot change the email address of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	exists, user := db.GetUser(ctx, username)
+	if !exists {
+		Error.Printf("User %s does not exist", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	credentialError := user.CheckPassword(request.Password)
+	if credentialError != nil {
+		Error.Println("Invalid password")
+		c.AbortWithError(http.StatusUnauthorized, credentialError)
+		return
+	}
+
+	if db.UserExistsByEmail(ctx, request.Email) {
+		Error.Printf("Email address for user %s is already in use\n", username)
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	// only the link for the most recently requested address should work
+	invalidateUserTokens(ctx, username, models.EmailVerificationToken)
+
+	if success := sendVerificationEmail(ctx, user, request.Email); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Requested email address change for user %s\n", username)
+
+	c.IndentedJSON(http.StatusAccepted, nil)
+}
+
+func validateChangeEmailRequest(request *ChangeEmailRequest) (bool, string) {
+	if len(request.Email) <= 0 {
+		return false, "email is missing"
+	}
+
+	if len(request.Password) <= 0 {
+		return false, "password is missing"
+	}
+
+	return true, ""
+}
+
+// issues a verification token for the given email address and emails a link containing it
+func sendVerificationEmail(ctx context.Context, user *models.User, email string) bool {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate email verification token: %s\n", err)
+		return false
+	}
+
+	verificationToken := models.UserToken{
+		Username:    user.Username,
+		Purpose:     models.EmailVerificationToken,
+		TokenHash:   tokenHash,
+		Data:        email,
+		TimeExpires: time.Now().UTC().Add(emailVerificationTokenLifetime).Unix(),
+	}
+
+	if success := db.AddUserToken(ctx, &verificationToken); !success {
+		Error.Printf("Could not add email verification token for user %s\n", user.Username)
+		return false
+	}
+
+	Info.Printf("Issued email verification token %s for user %s\n", verificationToken.ID, user.Username)
+
+	return queueEmail(ctx, mail.EmailVerificationMessage, email, map[string]string{
+		"Username":  user.Username,
+		"Email":     email,
+		"Link":      appLink("/verify-email?token=" + url.QueryEscape(token)),
+		"ExpiresIn": "24 hours",
+	})
+}
+
+// returns whether the given user is allowed to send or receive group invitations
+func canUseInvitations(ctx context.Context, username string) bool {
+	if !requireVerifiedEmailForInvitations {
+		return db.UserExists(ctx, username)
+	}
+
+	success, user := db.GetUser(ctx, username)
+	return success && user.EmailVerified
+}
+
+// returns whether the given environment variable is set to a true value
+func envFlag(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
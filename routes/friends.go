@@ -0,0 +1,492 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+type FriendResponse struct {
+	ID             string                  `json:"id"`
+	Username       string                  `json:"username"`
+	DisplayName    string                  `json:"displayName"`
+	ProfilePicture string                  `json:"profilePicture"`
+	Status         models.FriendshipStatus `json:"status"`
+	TimeCreated    int64                   `json:"timeCreated"`
+	TimeAccepted   int64                   `json:"timeAccepted"`
+
+	// whether the other user sent the friend request
+	Incoming bool `json:"incoming"`
+}
+
+type FriendRequest struct {
+	Username string `json:"username"`
+}
+
+type FriendsFeedResponse struct {
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+	TotalCount int               `json:"totalCount"`
+	Activity   []models.Activity `json:"activity"`
+}
+
+// HeadToHeadSuggestion suggests a friend to play against, and which games to play
+type HeadToHeadSuggestion struct {
+	Username               string `json:"username"`
+	DisplayName            string `json:"displayName"`
+	ResultsTogether        int    `json:"resultsTogether"`
+	TimeLastPlayedTogether int64  `json:"timeLastPlayedTogether"`
+
+	// games that both users have played, but never against each other
+	NewGameIDs []string `json:"newGameIds"`
+}
+
+// GetFriends lists the user's friends, along with the friend requests they've sent and
+// received
+func GetFriends(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, friendships := db.GetFriendships(ctx, user.Username)
+	if !success {
+		Error.Printf("Could not get friends of user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	response := []FriendResponse{}
+	for _, f := range friendships {
+		response = append(response, createFriendResponse(ctx, &f, user.Username))
+	}
+
+	Info.Printf("Got %d friends of user %s\n", len(response), user.Username)
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// SendFriendRequest asks another user to be the user's friend. If the other user has
+// already asked, this accepts their request instead
+func SendFriendRequest(c *gin.Context) {
+	var request FriendRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if request.Username == user.Username {
+		Error.Printf("User %s cannot be friends with themselves\n", user.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !db.UserExists(ctx, request.Username) {
+		Error.Printf("User %s does not exist\n", request.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, existing := findFriendship(ctx, user.Username, request.Username)
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if existing != nil {
+		if existing.Status == models.FriendRequested && existing.FriendUsername == user.Username {
+			acceptFriendship(ctx, c, existing)
+			return
+		}
+
+		Info.Printf("User %s has already sent or received a friend request from user %s\n", user.Username, request.Username)
+		c.IndentedJSON(http.StatusOK, createFriendResponse(ctx, existing, user.Username))
+		return
+	}
+
+	friendship := models.Friendship{
+		Username:       user.Username,
+		FriendUsername: request.Username,
+		Status:         models.FriendRequested,
+	}
+
+	if success := db.AddFriendship(ctx, &friendship); !success {
+		Error.Printf("Could not add friend request from user %s to user %s\n", user.Username, request.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("User %s sent a friend request to user %s\n", user.Username, request.Username)
+
+	notify(ctx, &models.Notification{
+		Username:     request.Username,
+		Type:         models.FriendRequestNotification,
+		FromUsername: user.Username,
+	})
+
+	c.IndentedJSON(http.StatusCreated, createFriendResponse(ctx, &friendship, user.Username))
+}
+
+// AcceptFriendRequest accepts a friend request that was sent to the user
+func AcceptFriendRequest(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, friendship := getFriendship(ctx, c, user.Username)
+	if !success {
+		return
+	}
+
+	if friendship.FriendUsername != user.Username {
+		Error.Printf("User %s cannot accept their own friend request %s\n", user.Username, friendship.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if friendship.Status == models.FriendAccepted {
+		c.IndentedJSON(http.StatusOK, createFriendResponse(ctx, friendship, user.Username))
+		return
+	}
+
+	acceptFriendship(ctx, c, friendship)
+}
+
+// RemoveFriend declines or cancels a friend request, or ends a friendship
+func RemoveFriend(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, friendship := getFriendship(ctx, c, user.Username)
+	if !success {
+		return
+	}
+
+	if success := db.DeleteFriendship(ctx, friendship.ID); !success {
+		Error.Printf("Could not delete friendship %s\n", friendship.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("User %s removed friendship %s with user %s\n", user.Username, friendship.ID, friendship.Other(user.Username))
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// GetFriendsFeed lists what the user's friends have been doing in the groups that the
+// user can see, newest first
+func GetFriendsFeed(c *gin.Context) {
+	success, page, pageSize := parsePagination(c)
+	if !success {
+		Error.Println("Invalid pagination parameters")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, friends := getFriendUsernames(ctx, user.Username)
+	if !success {
+		Error.Printf("Could not get friends of user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// the groups that any of the friends are in
+	groupIds := []string{}
+	for _, f := range friends {
+		if success, memberships := db.GetGroupMemberships(ctx, f); success {
+			for _, m := range memberships {
+				groupIds = appendIfMissing(groupIds, m.GroupID)
+			}
+		}
+	}
+
+	activity := []models.Activity{}
+	for _, groupId := range groupIds {
+		success, group := db.GetGroup(ctx, groupId)
+		if !success || !canSeeGroup(ctx, group, user.Username, false) {
+			continue
+		}
+
+		success, groupActivity := db.GetActivityForGroup(ctx, group.ID)
+		if !success {
+			Error.Printf("Could not get activity for group %s\n", group.ID)
+			continue
+		}
+
+		for _, a := range groupActivity {
+			if slices.Contains(friends, a.Username) {
+				activity = append(activity, a)
+			}
+		}
+	}
+
+	// newest first
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].TimeCreated > activity[j].TimeCreated
+	})
+
+	response := FriendsFeedResponse{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: len(activity),
+		Activity:   paginate(activity, page, pageSize),
+	}
+
+	Info.Printf("Got %d activity records for friends of user %s\n", len(response.Activity), user.Username)
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// GetHeadToHeadSuggestions suggests friends for the user to play against, starting with
+// the ones they've played least recently
+func GetHeadToHeadSuggestions(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, friends := getFriendUsernames(ctx, user.Username)
+	if !success {
+		Error.Printf("Could not get friends of user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	success, results := db.GetResultsWithPlayer(ctx, user.Username)
+	if !success {
+		Error.Printf("Could not get results for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	gameIds := []string{}
+	for _, r := range results {
+		gameIds = appendIfMissing(gameIds, r.GameID)
+	}
+
+	suggestions := []HeadToHeadSuggestion{}
+
+	for _, f := range friends {
+		success, player := db.GetPlayer(ctx, f)
+		if !success {
+			continue
+		}
+
+		success, friendResults := db.GetResultsWithPlayer(ctx, f)
+		if !success {
+			Error.Printf("Could not get results for user %s\n", f)
+			continue
+		}
+
+		suggestion := HeadToHeadSuggestion{
+			Username:    player.Username,
+			DisplayName: player.DisplayName,
+			NewGameIDs:  []string{},
+		}
+
+		playedTogether := []string{}
+		friendGameIds := []string{}
+
+		for _, r := range friendResults {
+			// the user shouldn't learn about results they can't see
+			if !canSeeResult(ctx, r, user.Username) {
+				continue
+			}
+
+			friendGameIds = appendIfMissing(friendGameIds, r.GameID)
+
+			if hasPlayer(&r, user.Username) {
+				suggestion.ResultsTogether++
+				playedTogether = appendIfMissing(playedTogether, r.GameID)
+
+				if r.TimePlayed > suggestion.TimeLastPlayedTogether {
+					suggestion.TimeLastPlayedTogether = r.TimePlayed
+				}
+			}
+		}
+
+		for _, g := range friendGameIds {
+			if slices.Contains(gameIds, g) && !slices.Contains(playedTogether, g) {
+				suggestion.NewGameIDs = append(suggestion.NewGameIDs, g)
+			}
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].TimeLastPlayedTogether < suggestions[j].TimeLastPlayedTogether
+	})
+
+	Info.Printf("Got %d head-to-head suggestions for user %s\n", len(suggestions), user.Username)
+
+	c.IndentedJSON(http.StatusOK, suggestions)
+}
+
+// UpdateFriendRestrictions sets what users who aren't the user's friends can do to them
+func UpdateFriendRestrictions(c *gin.Context) {
+	var restrictions models.FriendRestrictions
+	if err := c.BindJSON(&restrictions); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	user.FriendRestrictions = restrictions
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update friend restrictions for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Updated friend restrictions for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, user.FriendRestrictions)
+}
+
+// returns whether the two users are friends
+func areFriends(ctx context.Context, username string, otherUsername string) bool {
+	success, friendship := findFriendship(ctx, username, otherUsername)
+	return success && friendship != nil && friendship.Status == models.FriendAccepted
+}
+
+// returns whether the calling user can invite the user to a group
+func canInviteToGroup(ctx context.Context, callingUsername string, user *models.User) bool {
+	return !user.FriendRestrictions.InvitationsFromFriendsOnly || areFriends(ctx, callingUsername, user.Username)
+}
+
+// returns whether the calling user can add the user to a result
+func canAddToResult(ctx context.Context, callingUsername string, user *models.User) bool {
+	if callingUsername == user.Username || !user.FriendRestrictions.ResultsFromFriendsOnly {
+		return true
+	}
+
+	return len(callingUsername) > 0 && areFriends(ctx, callingUsername, user.Username)
+}
+
+func getFriendUsernames(ctx context.Context, username string) (bool, []string) {
+	success, friendships := db.GetFriendships(ctx, username)
+	if !success {
+		return false, nil
+	}
+
+	friends := []string{}
+	for _, f := range friendships {
+		if f.Status == models.FriendAccepted {
+			friends = append(friends, f.Other(username))
+		}
+	}
+
+	return true, friends
+}
+
+// finds the friendship or friend request between the two users, in either direction.
+// Returns nil if there isn't one
+func findFriendship(ctx context.Context, username string, otherUsername string) (bool, *models.Friendship) {
+	success, friendships := db.GetFriendships(ctx, username)
+	if !success {
+		Error.Printf("Could not get friends of user %s\n", username)
+		return false, nil
+	}
+
+	for _, f := range friendships {
+		if f.Other(username) == otherUsername {
+			return true, &f
+		}
+	}
+
+	return true, nil
+}
+
+// gets the friendship in the friendshipId parameter, aborting the request if it doesn't
+// exist or doesn't involve the user
+func getFriendship(ctx context.Context, c *gin.Context, username string) (bool, *models.Friendship) {
+	friendshipId := c.Param("friendshipId")
+
+	success, friendship := db.GetFriendship(ctx, friendshipId)
+	if !success || !friendship.Involves(username) {
+		Error.Printf("Friendship %s does not exist for user %s\n", friendshipId, username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	return true, friendship
+}
+
+func acceptFriendship(ctx context.Context, c *gin.Context, friendship *models.Friendship) {
+	friendship.Status = models.FriendAccepted
+	friendship.TimeAccepted = time.Now().UTC().Unix()
+
+	if success := db.UpdateFriendship(ctx, friendship); !success {
+		Error.Printf("Could not accept friend request %s\n", friendship.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("User %s accepted the friend request from user %s\n", friendship.FriendUsername, friendship.Username)
+
+	notify(ctx, &models.Notification{
+		Username:     friendship.Username,
+		Type:         models.FriendAcceptedNotification,
+		FromUsername: friendship.FriendUsername,
+	})
+
+	c.IndentedJSON(http.StatusOK, createFriendResponse(ctx, friendship, friendship.FriendUsername))
+}
+
+func createFriendResponse(ctx context.Context, friendship *models.Friendship, username string) FriendResponse {
+	response := FriendResponse{
+		ID:           friendship.ID,
+		Username:     friendship.Other(username),
+		Status:       friendship.Status,
+		TimeCreated:  friendship.TimeCreated,
+		TimeAccepted: friendship.TimeAccepted,
+		Incoming:     friendship.FriendUsername == username,
+	}
+
+	if success, player := db.GetPlayer(ctx, response.Username); success {
+		response.DisplayName = player.DisplayName
+		response.ProfilePicture = player.ProfilePicture
+	}
+
+	return response
+}
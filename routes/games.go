@@ -125,12 +125,27 @@ func DeleteGame(c *gin.Context) {
 
 	ctx := context.TODO()
 
-	if !db.GameExists(ctx, id) {
+	exists, game := db.GetGame(ctx, id)
+	if !exists {
 		Error.Printf("Game %s does not exist", id)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
+	success, results := db.GetResultsWithGame(ctx, id)
+	if !success {
+		Error.Printf("Could not get results for game %s\n", id)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, r := range results {
+		if success := deleteAttachments(ctx, r.ID) && deleteComments(ctx, r.ID); !success {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+	}
+
 	success, deletedCount := db.DeleteResultsWithGame(ctx, id)
 	if !success {
 		Error.Printf("Could not delete results for game %s\n", id)
@@ -146,6 +161,8 @@ func DeleteGame(c *gin.Context) {
 		return
 	}
 
+	deleteImage(ctx, game.UploadedImage)
+
 	Info.Printf("Deleted game %s\n", id)
 
 	c.IndentedJSON(http.StatusNoContent, nil)
@@ -3,6 +3,7 @@ package routes
 import (
 	"context"
 	"net/http"
+	"phrasmotica/bore-score-api/events"
 	"phrasmotica/bore-score-api/models"
 
 	"github.com/gin-gonic/gin"
@@ -105,16 +106,22 @@ func AddGroupInvitation(c *gin.Context) {
 		return
 	}
 
+	if c.GetString("username") != newGroupInvitation.InviterUsername {
+		Error.Println("Cannot send an invitation on another user's behalf")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	ctx := context.TODO()
 
-	if !db.UserExists(ctx, newGroupInvitation.Username) {
-		Error.Printf("User %s does not exist\n", newGroupInvitation.Username)
+	if !canUseInvitations(ctx, newGroupInvitation.Username) {
+		Error.Printf("User %s does not exist or has not verified their email address\n", newGroupInvitation.Username)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
-	if !db.UserExists(ctx, newGroupInvitation.InviterUsername) {
-		Error.Printf("User %s does not exist\n", newGroupInvitation.InviterUsername)
+	if !canUseInvitations(ctx, newGroupInvitation.InviterUsername) {
+		Error.Printf("User %s does not exist or has not verified their email address\n", newGroupInvitation.InviterUsername)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
@@ -126,12 +133,24 @@ func AddGroupInvitation(c *gin.Context) {
 		return
 	}
 
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if !db.IsInGroup(ctx, newGroupInvitation.GroupID, newGroupInvitation.InviterUsername) {
 		Error.Printf("Inviter %s is not in group %s\n", newGroupInvitation.InviterUsername, newGroupInvitation.GroupID)
 		c.AbortWithStatus(http.StatusForbidden)
 		return
 	}
 
+	if success, invitee := db.GetUser(ctx, newGroupInvitation.Username); !success || !canInviteToGroup(ctx, newGroupInvitation.InviterUsername, invitee) {
+		Error.Printf("User %s only accepts group invitations from friends\n", newGroupInvitation.Username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if db.IsInGroup(ctx, group.ID, newGroupInvitation.Username) {
 		Info.Printf("User %s is already in group %s\n", newGroupInvitation.Username, newGroupInvitation.GroupID)
 		c.IndentedJSON(http.StatusNoContent, nil)
@@ -146,6 +165,21 @@ func AddGroupInvitation(c *gin.Context) {
 
 	Info.Printf("Added invitation to group %s for user %s by inviter %s\n", newGroupInvitation.GroupID, newGroupInvitation.Username, newGroupInvitation.InviterUsername)
 
+	recordActivity(ctx, &models.Activity{
+		GroupID:        newGroupInvitation.GroupID,
+		Type:           models.InvitationSent,
+		Username:       newGroupInvitation.InviterUsername,
+		TargetUsername: newGroupInvitation.Username,
+	})
+
+	notify(ctx, &models.Notification{
+		Username:     newGroupInvitation.Username,
+		Type:         models.InvitationNotification,
+		FromUsername: newGroupInvitation.InviterUsername,
+		GroupID:      newGroupInvitation.GroupID,
+		InvitationID: newGroupInvitation.ID,
+	})
+
 	c.IndentedJSON(http.StatusCreated, newGroupInvitation)
 }
 
@@ -169,6 +203,12 @@ func AcceptGroupInvitation(c *gin.Context) {
 		return
 	}
 
+	if isGroupArchived(ctx, invitation.GroupID) {
+		Error.Printf("Group %s is archived\n", invitation.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if !db.UserExists(ctx, invitation.Username) {
 		Error.Printf("Invited user %s does not exist\n", invitation.Username)
 		c.AbortWithStatus(http.StatusForbidden)
@@ -217,6 +257,15 @@ func AcceptGroupInvitation(c *gin.Context) {
 
 	Info.Printf("Added membership to group %s for user %s from invitation %s\n", newMembership.GroupID, newMembership.Username, newMembership.InvitationID)
 
+	publishGroupEvent(newMembership.GroupID, events.MembershipAdded, newMembership)
+	triggerWebhooks(ctx, newMembership.GroupID, models.WebhookMemberJoined, newMembership)
+
+	recordActivity(ctx, &models.Activity{
+		GroupID:  newMembership.GroupID,
+		Type:     models.MemberJoined,
+		Username: newMembership.Username,
+	})
+
 	c.IndentedJSON(http.StatusNoContent, nil)
 }
 
@@ -3,6 +3,7 @@ package routes
 import (
 	"context"
 	"net/http"
+	"phrasmotica/bore-score-api/events"
 	"phrasmotica/bore-score-api/models"
 
 	"github.com/gin-gonic/gin"
@@ -73,6 +74,12 @@ func AddGroupMembership(c *gin.Context) {
 		return
 	}
 
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if group.Visibility == models.Private {
 		Error.Printf("Group %s is private\n", newGroupMembership.GroupID)
 		c.AbortWithStatus(http.StatusForbidden)
@@ -93,5 +100,71 @@ func AddGroupMembership(c *gin.Context) {
 
 	Info.Printf("Added membership to group %s for user %s\n", newGroupMembership.GroupID, newGroupMembership.Username)
 
+	publishGroupEvent(newGroupMembership.GroupID, events.MembershipAdded, newGroupMembership)
+	triggerWebhooks(ctx, newGroupMembership.GroupID, models.WebhookMemberJoined, newGroupMembership)
+
+	recordActivity(ctx, &models.Activity{
+		GroupID:  newGroupMembership.GroupID,
+		Type:     models.MemberJoined,
+		Username: newGroupMembership.Username,
+	})
+
 	c.IndentedJSON(http.StatusCreated, newGroupMembership)
 }
+
+func RemoveGroupMembership(c *gin.Context) {
+	username := c.Param("username")
+	groupId := c.Param("groupId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	// users can leave a group, or be removed from it by its creator
+	if username != callingUsername && !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot remove user %s from group %s\n", callingUsername, username, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if !db.IsInGroup(ctx, group.ID, username) {
+		Info.Printf("User %s is not in group %s\n", username, group.ID)
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := db.DeleteGroupMembership(ctx, group.ID, username); !success {
+		Error.Printf("Could not remove user %s from group %s\n", username, group.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Removed membership to group %s for user %s\n", group.ID, username)
+
+	publishGroupEvent(group.ID, events.MembershipRemoved, models.GroupMembership{
+		GroupID:  group.ID,
+		Username: username,
+	})
+
+	recordActivity(ctx, &models.Activity{
+		GroupID:        group.ID,
+		Type:           models.MemberLeft,
+		Username:       callingUsername,
+		TargetUsername: username,
+	})
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
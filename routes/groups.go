@@ -3,6 +3,7 @@ package routes
 import (
 	"context"
 	"net/http"
+	"phrasmotica/bore-score-api/events"
 	"phrasmotica/bore-score-api/models"
 	"strconv"
 	"time"
@@ -19,6 +20,8 @@ type GroupResponse struct {
 	ProfilePicture string                     `json:"profilePicture" bson:"profilePicture"`
 	CreatedBy      string                     `json:"createdBy" bson:"createdBy"`
 	Visibility     models.GroupVisibilityName `json:"visibility" bson:"visibility"`
+	Archived       bool                       `json:"archived" bson:"archived"`
+	TimeArchived   int64                      `json:"timeArchived" bson:"timeArchived"`
 	MemberCount    int                        `json:"memberCount" bson:"memberCount"`
 }
 
@@ -47,7 +50,7 @@ func GetGroups(c *gin.Context) {
 	for _, g := range groups {
 		callingUsername := c.GetString("username")
 
-		if canSeeGroup(ctx, &g, callingUsername, true) {
+		if apiKeyAllowsGroup(c, g.ID) && canSeeGroup(ctx, &g, callingUsername, true) {
 			filteredGroups = append(filteredGroups, createGroupResponse(ctx, &g))
 		}
 	}
@@ -70,6 +73,12 @@ func GetGroup(c *gin.Context) {
 		return
 	}
 
+	if !apiKeyAllowsGroup(c, group.ID) {
+		Error.Printf("API key cannot be used for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if group.Visibility == models.Private {
 		callingUsername := c.GetString("username")
 
@@ -138,6 +147,14 @@ func PostGroup(c *gin.Context) {
 		Error.Printf("Could not add membership to group %s for group creator %s\n", newGroup.ID, creatorUsername)
 	} else {
 		Info.Printf("Added membership to group %s for group creator %s\n", newGroup.ID, creatorUsername)
+
+		publishGroupEvent(newGroup.ID, events.MembershipAdded, membership)
+
+		recordActivity(ctx, &models.Activity{
+			GroupID:  newGroup.ID,
+			Type:     models.MemberJoined,
+			Username: creatorUsername,
+		})
 	}
 
 	c.IndentedJSON(http.StatusCreated, newGroup)
@@ -170,7 +187,33 @@ func DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	if success := db.DeleteGroup(ctx, group.ID); !success {
+	// the owner chooses whether to keep the group's history around in a read-only state,
+	// or to remove the group along with everything that belongs to it
+	archive := c.Query("archive") == strconv.Itoa(1)
+
+	if archive {
+		if group.Archived {
+			Info.Printf("Group %s is already archived\n", groupId)
+			c.IndentedJSON(http.StatusNoContent, nil)
+			return
+		}
+
+		group.Archived = true
+		group.TimeArchived = time.Now().UTC().Unix()
+
+		if success := db.UpdateGroup(ctx, group); !success {
+			Error.Printf("Could not archive group %s\n", groupId)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		Info.Printf("Archived group %s\n", groupId)
+
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := deleteGroupCascade(ctx, group); !success {
 		Error.Printf("Could not delete group %s\n", groupId)
 		c.AbortWithStatus(http.StatusServiceUnavailable)
 		return
@@ -181,6 +224,95 @@ func DeleteGroup(c *gin.Context) {
 	c.IndentedJSON(http.StatusNoContent, nil)
 }
 
+// deletes the group along with its results (and their approvals, attachments, comments and reactions), invitations, memberships, guests, activity and webhooks.
+// The group itself is deleted last, so a failed cascade can be retried without leaving orphans
+func deleteGroupCascade(ctx context.Context, group *models.Group) bool {
+	success, results := db.GetResultsForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not get results for group %s\n", group.ID)
+		return false
+	}
+
+	for _, r := range results {
+		success, deletedCount := db.DeleteApprovals(ctx, r.ID)
+		if !success {
+			Error.Printf("Could not delete approvals for result %s\n", r.ID)
+			return false
+		}
+
+		Info.Printf("Deleted %d approvals for result %s\n", deletedCount, r.ID)
+
+		if success := deleteAttachments(ctx, r.ID); !success {
+			return false
+		}
+
+		if success := deleteComments(ctx, r.ID); !success {
+			return false
+		}
+	}
+
+	success, deletedCount := db.DeleteResultsForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not delete results for group %s\n", group.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d results for group %s\n", deletedCount, group.ID)
+
+	success, deletedCount = db.DeleteGroupInvitationsForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not delete invitations for group %s\n", group.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d invitations for group %s\n", deletedCount, group.ID)
+
+	success, deletedCount = db.DeleteGroupMembershipsForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not delete memberships for group %s\n", group.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d memberships for group %s\n", deletedCount, group.ID)
+
+	success, deletedCount = db.DeleteGuestPlayersForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not delete guest players for group %s\n", group.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d guest players for group %s\n", deletedCount, group.ID)
+
+	success, deletedCount = db.DeleteActivityForGroup(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not delete activity for group %s\n", group.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d activity records for group %s\n", deletedCount, group.ID)
+
+	success, groupWebhooks := db.GetWebhooks(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not get webhooks for group %s\n", group.ID)
+		return false
+	}
+
+	for _, w := range groupWebhooks {
+		if success := deleteWebhook(ctx, &w); !success {
+			Error.Printf("Could not delete webhook %s\n", w.ID)
+			return false
+		}
+	}
+
+	if success := db.DeleteGroup(ctx, group.ID); !success {
+		return false
+	}
+
+	deleteImage(ctx, group.UploadedPicture)
+
+	return true
+}
+
 func createGroupResponse(ctx context.Context, group *models.Group) GroupResponse {
 	memberCount := computeMemberCount(ctx, group)
 
@@ -192,6 +324,8 @@ func createGroupResponse(ctx context.Context, group *models.Group) GroupResponse
 		ProfilePicture: group.ProfilePicture,
 		CreatedBy:      group.CreatedBy,
 		Visibility:     group.Visibility,
+		Archived:       group.Archived,
+		TimeArchived:   group.TimeArchived,
 		MemberCount:    memberCount,
 	}
 }
@@ -202,6 +336,22 @@ func canSeeGroup(ctx context.Context, group *models.Group, callingUsername strin
 		(allowInvitees && db.IsInvitedToGroup(ctx, group.ID, callingUsername)))
 }
 
+// returns whether the user can administer the group, e.g. by managing its webhooks
+func isGroupAdmin(group *models.Group, username string) bool {
+	return len(username) > 0 && group.CreatedBy == username
+}
+
+// returns whether the group with the given ID has been archived, in which case
+// it can no longer be changed
+func isGroupArchived(ctx context.Context, groupId string) bool {
+	if len(groupId) <= 0 {
+		return false
+	}
+
+	success, group := db.GetGroup(ctx, groupId)
+	return success && group.Archived
+}
+
 func computeMemberCount(ctx context.Context, group *models.Group) int {
 	success, members := db.GetPlayersInGroup(ctx, group.ID)
 	if !success {
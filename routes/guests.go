@@ -0,0 +1,334 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+// how long a link to claim a guest player can be used for
+const guestClaimTokenLifetime = 7 * 24 * time.Hour
+
+type GuestPlayerResponse struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+	GroupID     string `json:"groupId"`
+	CreatedBy   string `json:"createdBy"`
+	TimeCreated int64  `json:"timeCreated"`
+}
+
+type CreateGuestPlayerRequest struct {
+	DisplayName string `json:"displayName"`
+}
+
+type GuestClaimLinkResponse struct {
+	Token string `json:"token"`
+	Link  string `json:"link"`
+}
+
+type ClaimGuestPlayerRequest struct {
+	Token string `json:"token"`
+}
+
+// GetGuestPlayers lists the group's guest players that haven't been claimed yet
+func GetGuestPlayers(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, group := getGroupForGuests(ctx, c)
+	if !success {
+		return
+	}
+
+	success, guests := db.GetGuestPlayers(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not get guest players for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	response := []GuestPlayerResponse{}
+	for _, g := range guests {
+		if len(g.ClaimedBy) <= 0 {
+			response = append(response, createGuestPlayerResponse(&g))
+		}
+	}
+
+	Info.Printf("Got %d guest players for group %s\n", len(response), group.ID)
+
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// PostGuestPlayer adds a guest player to the group. Guests can also be added while
+// posting a result
+func PostGuestPlayer(c *gin.Context) {
+	var request CreateGuestPlayerRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if len(request.DisplayName) <= 0 {
+		Error.Println("Error validating new guest player: display name is missing")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, group := getGroupForGuests(ctx, c)
+	if !success {
+		return
+	}
+
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, guest := addGuestPlayer(ctx, group.ID, request.DisplayName, c.GetString("username"))
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, createGuestPlayerResponse(guest))
+}
+
+// CreateGuestClaimLink issues a link that the guest can use to move their results to
+// their own account. Any member of the group can share it with them
+func CreateGuestClaimLink(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, group := getGroupForGuests(ctx, c)
+	if !success {
+		return
+	}
+
+	success, guest := db.GetGuestPlayer(ctx, c.Param("guestUsername"))
+	if !success || guest.GroupID != group.ID || len(guest.ClaimedBy) > 0 {
+		Error.Printf("Guest player %s does not exist in group %s\n", c.Param("guestUsername"), group.ID)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	// only the most recent link should work
+	invalidateUserTokens(ctx, guest.Username, models.GuestClaimToken)
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate guest claim token: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	claimToken := models.UserToken{
+		Username:    guest.Username,
+		Purpose:     models.GuestClaimToken,
+		TokenHash:   tokenHash,
+		TimeExpires: time.Now().UTC().Add(guestClaimTokenLifetime).Unix(),
+	}
+
+	if success := db.AddUserToken(ctx, &claimToken); !success {
+		Error.Printf("Could not add claim token for guest player %s\n", guest.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("User %s issued claim token %s for guest player %s\n", c.GetString("username"), claimToken.ID, guest.Username)
+
+	c.IndentedJSON(http.StatusCreated, GuestClaimLinkResponse{
+		Token: token,
+		Link:  appLink("/claim-guest?token=" + url.QueryEscape(token)),
+	})
+}
+
+// ClaimGuestPlayer moves a guest player's results to the calling user's account, and
+// adds them to the guest's group. The user approves those results as they do so
+func ClaimGuestPlayer(c *gin.Context) {
+	var request ClaimGuestPlayerRequest
+	if err := c.BindJSON(&request); err != nil || len(request.Token) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	callingUsername := c.GetString("username")
+
+	success, claimToken := findUsableUserToken(ctx, request.Token, models.GuestClaimToken)
+	if !success {
+		Error.Println("Guest claim token is invalid or has expired")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, guest := db.GetGuestPlayer(ctx, claimToken.Username)
+	if !success || len(guest.ClaimedBy) > 0 {
+		Error.Printf("Guest player %s does not exist or has already been claimed\n", claimToken.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, results := db.GetResultsWithPlayer(ctx, guest.Username)
+	if !success {
+		Error.Printf("Could not get results for guest player %s\n", guest.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// a result can't have the same player twice
+	for _, r := range results {
+		if slices.ContainsFunc(r.Scores, func(s models.PlayerScore) bool { return s.Username == callingUsername }) {
+			Error.Printf("User %s played alongside guest player %s in result %s\n", callingUsername, guest.Username, r.ID)
+			c.AbortWithStatus(http.StatusConflict)
+			return
+		}
+	}
+
+	if !markUserTokenUsed(ctx, claimToken) {
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	success, renamedCount := db.RenamePlayerInResults(ctx, guest.Username, callingUsername)
+	if !success {
+		Error.Printf("Could not move results of guest player %s to user %s\n", guest.Username, callingUsername)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Moved %d results of guest player %s to user %s\n", renamedCount, guest.Username, callingUsername)
+
+	for _, r := range results {
+		approval := models.Approval{
+			ID:             uuid.NewString(),
+			ResultID:       r.ID,
+			TimeCreated:    time.Now().UTC().Unix(),
+			Username:       callingUsername,
+			ApprovalStatus: models.Approved,
+		}
+
+		if success := db.AddApproval(ctx, &approval); !success {
+			Error.Printf("Could not approve result %s for user %s\n", r.ID, callingUsername)
+		}
+	}
+
+	guest.ClaimedBy = callingUsername
+	guest.TimeClaimed = time.Now().UTC().Unix()
+
+	if success := db.UpdateGuestPlayer(ctx, guest); !success {
+		Error.Printf("Could not mark guest player %s as claimed\n", guest.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if !db.IsInGroup(ctx, guest.GroupID, callingUsername) {
+		membership := models.GroupMembership{
+			GroupID:  guest.GroupID,
+			Username: callingUsername,
+		}
+
+		if success := db.AddGroupMembership(ctx, &membership); !success {
+			Error.Printf("Could not add user %s to group %s\n", callingUsername, guest.GroupID)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		recordActivity(ctx, &models.Activity{
+			GroupID:  guest.GroupID,
+			Type:     models.MemberJoined,
+			Username: callingUsername,
+		})
+	}
+
+	recordActivity(ctx, &models.Activity{
+		GroupID:        guest.GroupID,
+		Type:           models.GuestClaimed,
+		Username:       callingUsername,
+		TargetUsername: guest.Username,
+	})
+
+	Info.Printf("User %s claimed guest player %s\n", callingUsername, guest.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// gets the group in the groupId parameter, aborting the request if it doesn't exist or
+// the calling user isn't a member of it
+func getGroupForGuests(ctx context.Context, c *gin.Context) (bool, *models.Group) {
+	groupId := c.Param("groupId")
+	callingUsername := c.GetString("username")
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	if !db.IsInGroup(ctx, group.ID, callingUsername) {
+		Error.Printf("User %s is not in group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return false, nil
+	}
+
+	return true, group
+}
+
+func addGuestPlayer(ctx context.Context, groupId string, displayName string, createdBy string) (bool, *models.GuestPlayer) {
+	guest := models.GuestPlayer{
+		GroupID:     groupId,
+		DisplayName: displayName,
+		CreatedBy:   createdBy,
+	}
+
+	if success := db.AddGuestPlayer(ctx, &guest); !success {
+		Error.Printf("Could not add guest player %s to group %s\n", displayName, groupId)
+		return false, nil
+	}
+
+	Info.Printf("Added guest player %s to group %s\n", guest.Username, groupId)
+
+	return true, &guest
+}
+
+// returns the guest players that appear in the results, keyed by username
+func findGuestPlayers(ctx context.Context, scores []models.PlayerScore) map[string]GuestPlayerResponse {
+	guests := map[string]GuestPlayerResponse{}
+
+	for _, s := range scores {
+		if !models.IsGuestUsername(s.Username) {
+			continue
+		}
+
+		if _, found := guests[s.Username]; found {
+			continue
+		}
+
+		if success, guest := db.GetGuestPlayer(ctx, s.Username); success {
+			guests[s.Username] = createGuestPlayerResponse(guest)
+		}
+	}
+
+	return guests
+}
+
+func createGuestPlayerResponse(guest *models.GuestPlayer) GuestPlayerResponse {
+	return GuestPlayerResponse{
+		Username:    guest.Username,
+		DisplayName: guest.DisplayName,
+		GroupID:     guest.GroupID,
+		CreatedBy:   guest.CreatedBy,
+		TimeCreated: guest.TimeCreated,
+	}
+}
@@ -1,16 +1,34 @@
 package routes
 
-import "phrasmotica/bore-score-api/models"
+import (
+	"phrasmotica/bore-score-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
 
 func hasUniquePlayerScores(result *models.Result) bool {
 	var uniquePlayers []string
 
+	namedScores := 0
+
 	for _, e := range result.Scores {
+		// new guests don't have usernames yet
+		if len(e.GuestName) > 0 {
+			continue
+		}
+
 		// TODO: use slices.Contains(...) to check
 		uniquePlayers = appendIfMissing(uniquePlayers, e.Username)
+		namedScores++
 	}
 
-	return len(uniquePlayers) == len(result.Scores)
+	return len(uniquePlayers) == namedScores
 }
 
 func appendIfMissing(slice []string, s string) []string {
@@ -22,3 +40,34 @@ func appendIfMissing(slice []string, s string) []string {
 
 	return append(slice, s)
 }
+
+// reads the zero-indexed "page" and "pageSize" query parameters, falling back to defaults
+// if they are absent
+func parsePagination(c *gin.Context) (bool, int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "0"))
+	if err != nil || page < 0 {
+		return false, 0, 0
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize <= 0 || pageSize > maxPageSize {
+		return false, 0, 0
+	}
+
+	return true, page, pageSize
+}
+
+// returns the items on the given zero-indexed page
+func paginate[T interface{}](items []T, page int, pageSize int) []T {
+	start := page * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end]
+}
@@ -3,6 +3,8 @@ package routes
 import (
 	"phrasmotica/bore-score-api/models"
 	"testing"
+
+	"golang.org/x/exp/slices"
 )
 
 func TestHasUniquePlayerScores(t *testing.T) {
@@ -55,6 +57,20 @@ func TestHasUniquePlayerScores(t *testing.T) {
 				},
 			},
 		}, false},
+
+		{models.Result{
+			Scores: []models.PlayerScore{
+				{
+					Username: "player1",
+				},
+				{
+					GuestName: "Guest",
+				},
+				{
+					GuestName: "Guest",
+				},
+			},
+		}, true},
 	}
 
 	for _, table := range tables {
@@ -64,3 +80,26 @@ func TestHasUniquePlayerScores(t *testing.T) {
 		}
 	}
 }
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tables := []struct {
+		page     int
+		pageSize int
+		expected []int
+	}{
+		{0, 2, []int{1, 2}},
+		{1, 2, []int{3, 4}},
+		{2, 2, []int{5}},
+		{3, 2, []int{}},
+		{0, 10, []int{1, 2, 3, 4, 5}},
+	}
+
+	for _, table := range tables {
+		actual := paginate(items, table.page, table.pageSize)
+		if !slices.Equal(actual, table.expected) {
+			t.Errorf("Computed page was incorrect! Actual: %v, expected: %v", actual, table.expected)
+		}
+	}
+}
@@ -21,6 +21,10 @@ type Rank struct {
 	Username     string `json:"username" bson:"username"`
 	PointsScored int    `json:"pointsScored" bson:"pointsScored"`
 	PlayedCount  int    `json:"playedCount" bson:"playedCount"`
+
+	// only set for guest players
+	IsGuest     bool   `json:"isGuest,omitempty" bson:"isGuest,omitempty"`
+	DisplayName string `json:"displayName,omitempty" bson:"displayName,omitempty"`
 }
 
 func GetLeaderboard(c *gin.Context) {
@@ -45,6 +49,12 @@ func GetLeaderboard(c *gin.Context) {
 		return
 	}
 
+	if !apiKeyAllowsGroup(c, group.ID) {
+		Error.Printf("API key cannot be used for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	if !db.IsInGroup(ctx, group.ID, callingUsername) {
 		Error.Printf("User %s is not in group %s\n", callingUsername, group.ID)
 		c.AbortWithStatus(http.StatusUnauthorized)
@@ -70,8 +80,15 @@ func computeLeaderboard(ctx context.Context, group *models.Group, game *models.G
 	}
 
 	leaderboard := []Rank{}
+	playedCount := 0
 
 	for _, r := range results {
+		if isAwaitingPrivateApproval(ctx, &r) {
+			continue
+		}
+
+		playedCount++
+
 		for _, s := range r.Scores {
 			idx := slices.IndexFunc(leaderboard, func(k Rank) bool {
 				return k.Username == s.Username
@@ -90,10 +107,63 @@ func computeLeaderboard(ctx context.Context, group *models.Group, game *models.G
 		}
 	}
 
+	visibleLeaderboard := []Rank{}
+	for _, r := range leaderboard {
+		if !isHiddenFromLeaderboard(ctx, group, r.Username) {
+			visibleLeaderboard = append(visibleLeaderboard, r)
+		}
+	}
+
+	leaderboard = visibleLeaderboard
+
+	for i, r := range leaderboard {
+		if models.IsGuestUsername(r.Username) {
+			leaderboard[i].IsGuest = true
+
+			if success, guest := db.GetGuestPlayer(ctx, r.Username); success {
+				leaderboard[i].DisplayName = guest.DisplayName
+			}
+		}
+	}
+
 	return true, &LeaderboardResponse{
 		GroupID:     group.ID,
 		GameID:      game.ID,
-		PlayedCount: len(results),
+		PlayedCount: playedCount,
 		Leaderboard: leaderboard,
 	}
 }
+
+// returns the current leader of the game's leaderboard in the group, if there is one
+func findLeader(ctx context.Context, group *models.Group, game *models.Game) string {
+	success, response := computeLeaderboard(ctx, group, game)
+	if !success {
+		return ""
+	}
+
+	return computeLeader(response.Leaderboard)
+}
+
+// returns the username of the player with the most points in the leaderboard,
+// or an empty string if nobody is outright in the lead
+func computeLeader(leaderboard []Rank) string {
+	leader := ""
+	mostPoints := 0
+	isTied := false
+
+	for _, r := range leaderboard {
+		if leader == "" || r.PointsScored > mostPoints {
+			leader = r.Username
+			mostPoints = r.PointsScored
+			isTied = false
+		} else if r.PointsScored == mostPoints {
+			isTied = true
+		}
+	}
+
+	if isTied {
+		return ""
+	}
+
+	return leader
+}
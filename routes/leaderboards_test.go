@@ -0,0 +1,39 @@
+package routes
+
+import "testing"
+
+func TestComputeLeader(t *testing.T) {
+	tables := []struct {
+		leaderboard []Rank
+		expected    string
+	}{
+		{[]Rank{}, ""},
+
+		{[]Rank{
+			{Username: "player1", PointsScored: 3},
+		}, "player1"},
+
+		{[]Rank{
+			{Username: "player1", PointsScored: 3},
+			{Username: "player2", PointsScored: 5},
+		}, "player2"},
+
+		{[]Rank{
+			{Username: "player1", PointsScored: 5},
+			{Username: "player2", PointsScored: 5},
+		}, ""},
+
+		{[]Rank{
+			{Username: "player1", PointsScored: 5},
+			{Username: "player2", PointsScored: 5},
+			{Username: "player3", PointsScored: 7},
+		}, "player3"},
+	}
+
+	for _, table := range tables {
+		actual := computeLeader(table.leaderboard)
+		if actual != table.expected {
+			t.Errorf("Computed leader was incorrect! Actual: %s, expected: %s", actual, table.expected)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how many times recording a failed login is tried when it conflicts with another one
+const maxThrottleAttempts = 5
+
+var throttlePolicies = map[models.ThrottleKind]*auth.ThrottlePolicy{
+	models.AccountThrottle:   &auth.AccountThrottlePolicy,
+	models.IPAddressThrottle: &auth.IPAddressThrottlePolicy,
+}
+
+// UnlockUser clears a user's failed logins, including any lockout
+func UnlockUser(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if !db.UserExists(ctx, username) {
+		Error.Printf("User %s does not exist\n", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if success := resetLoginThrottle(ctx, models.AccountThrottle, username); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	recordAudit(ctx, &models.AuditEntry{
+		Type:           models.AccountUnlocked,
+		Username:       callingUsername,
+		TargetUsername: username,
+		IPAddress:      c.ClientIP(),
+	})
+
+	Info.Printf("User %s unlocked user %s\n", callingUsername, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// aborts the request if the account or IP address has to wait before trying to log in again
+func checkLoginThrottle(ctx context.Context, c *gin.Context, kind models.ThrottleKind, key string) bool {
+	success, throttle := db.GetLoginThrottle(ctx, kind, key)
+	if !success {
+		return true
+	}
+
+	retryAfter := throttlePolicies[kind].RetryAfter(throttle, time.Now().UTC())
+	if retryAfter <= 0 {
+		return true
+	}
+
+	Error.Printf("Login for %s %s is throttled for another %s\n", kind, key, retryAfter)
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.AbortWithStatus(http.StatusTooManyRequests)
+	return false
+}
+
+// counts a failed login for the account or IP address, and audits it if it caused a lockout.
+// If another failure is recorded at the same time, it's retried so that neither is lost
+func recordLoginFailure(ctx context.Context, c *gin.Context, kind models.ThrottleKind, key string) {
+	policy := throttlePolicies[kind]
+	locked := false
+
+	for attempt := 1; ; attempt++ {
+		success, throttle := db.GetLoginThrottle(ctx, kind, key)
+		if !success {
+			throttle = &models.LoginThrottle{
+				Kind: kind,
+				Key:  key,
+			}
+		}
+
+		locked = policy.RecordFailure(throttle, time.Now().UTC())
+
+		if success := db.SaveLoginThrottle(ctx, throttle); success {
+			break
+		}
+
+		if attempt >= maxThrottleAttempts {
+			Error.Printf("Could not record failed login for %s %s\n", kind, key)
+			return
+		}
+	}
+
+	if !locked {
+		return
+	}
+
+	Error.Printf("Locked %s %s after too many failed logins\n", kind, key)
+
+	entry := models.AuditEntry{
+		IPAddress: c.ClientIP(),
+		Details:   fmt.Sprintf("locked for %s after %d failed logins", policy.LockoutDuration, policy.LockoutThreshold),
+	}
+
+	if kind == models.AccountThrottle {
+		entry.Type = models.AccountLocked
+		entry.TargetUsername = key
+	} else {
+		entry.Type = models.IPAddressLocked
+	}
+
+	recordAudit(ctx, &entry)
+}
+
+func resetLoginThrottle(ctx context.Context, kind models.ThrottleKind, key string) bool {
+	throttle := models.LoginThrottle{
+		Kind: kind,
+		Key:  key,
+	}
+
+	if success := db.UpsertLoginThrottle(ctx, &throttle); !success {
+		Error.Printf("Could not reset failed logins for %s %s\n", kind, key)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,157 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"phrasmotica/bore-score-api/mail"
+	"phrasmotica/bore-score-api/models"
+	"strings"
+	"time"
+)
+
+// how often the outbox is checked for emails to send
+const outboxInterval = 30 * time.Second
+
+var mailEnabled = false
+
+// StartMail starts sending queued emails in the background, if an SMTP server is configured
+func StartMail(ctx context.Context) {
+	mailer, ok := mail.NewSMTPMailerFromEnv()
+	if !ok {
+		Info.Println("No SMTP_HOST environment variable found, emails will not be sent")
+		return
+	}
+
+	mailEnabled = true
+
+	AddNotificationChannel(&emailChannel{})
+
+	go mail.RunOutbox(ctx, db, mailer, outboxInterval)
+
+	Info.Printf("Sending emails via SMTP server %s:%s\n", mailer.Host, mailer.Port)
+}
+
+// renders an email and puts it in the outbox to be sent
+func queueEmail(ctx context.Context, messageType mail.MessageType, to string, data interface{}) bool {
+	if !mailEnabled {
+		Info.Printf("Emails are disabled, not sending %s email\n", messageType)
+		return false
+	}
+
+	message, err := mail.Render(messageType, to, data)
+	if err != nil {
+		Error.Printf("Could not render %s email: %s\n", messageType, err)
+		return false
+	}
+
+	outboxMessage := models.OutboxMessage{
+		Type:            string(messageType),
+		To:              message.To,
+		Subject:         message.Subject,
+		TextBody:        message.TextBody,
+		HTMLBody:        message.HTMLBody,
+		Status:          models.DeliveryPending,
+		TimeNextAttempt: time.Now().UTC().Unix(),
+	}
+
+	if success := db.AddOutboxMessage(ctx, &outboxMessage); !success {
+		Error.Printf("Could not add %s email to the outbox\n", messageType)
+		return false
+	}
+
+	Info.Printf("Queued %s email %s\n", messageType, outboxMessage.ID)
+
+	return true
+}
+
+// returns a link to the given path in the BoreScore web app
+func appLink(path string) string {
+	baseUrl := os.Getenv("APP_BASE_URL")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:3000"
+	}
+
+	return strings.TrimSuffix(baseUrl, "/") + path
+}
+
+func sendInvitationEmail(ctx context.Context, username string, inviterUsername string, groupId string, invitationId string) bool {
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("Could not get user %s\n", username)
+		return false
+	}
+
+	if !user.EmailPreferences.Invitations {
+		Info.Printf("User %s has turned off invitation emails\n", username)
+		return true
+	}
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Could not get group %s\n", groupId)
+		return false
+	}
+
+	return queueEmail(ctx, mail.InvitationMessage, user.Email, map[string]string{
+		"Username":        user.Username,
+		"InviterUsername": inviterUsername,
+		"GroupName":       group.DisplayName,
+		"Link":            appLink("/invitations/" + invitationId),
+	})
+}
+
+func sendApprovalReminderEmail(ctx context.Context, username string, fromUsername string, result *models.Result) bool {
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("Could not get user %s\n", username)
+		return false
+	}
+
+	if !user.EmailPreferences.ApprovalReminders {
+		Info.Printf("User %s has turned off approval reminder emails\n", username)
+		return true
+	}
+
+	gameName := "a game"
+	if success, game := db.GetGame(ctx, result.GameID); success {
+		gameName = game.DisplayName
+	}
+
+	return queueEmail(ctx, mail.ApprovalReminderMessage, user.Email, map[string]string{
+		"Username":     user.Username,
+		"FromUsername": fromUsername,
+		"GameName":     gameName,
+		"Link":         appLink("/results/" + result.ID),
+	})
+}
+
+// delivers notifications by email, for the types of notification that have an email equivalent
+type emailChannel struct{}
+
+func (*emailChannel) Name() string {
+	return "email"
+}
+
+func (*emailChannel) Deliver(ctx context.Context, notification *models.Notification) error {
+	success := true
+
+	switch notification.Type {
+	case models.InvitationNotification:
+		success = sendInvitationEmail(ctx, notification.Username, notification.FromUsername, notification.GroupID, notification.InvitationID)
+
+	case models.ApprovalRequestNotification:
+		var result *models.Result
+
+		success, result = db.GetResult(ctx, notification.ResultID)
+		if success {
+			success = sendApprovalReminderEmail(ctx, notification.Username, notification.FromUsername, result)
+		}
+	}
+
+	if !success {
+		return errors.New("could not queue email")
+	}
+
+	return nil
+}
@@ -0,0 +1,666 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+// how many records a merge job changes before it saves its progress
+const mergeBatchSize = 25
+
+// the phases of a merge job, in the order they run
+var mergePhases = []models.MergePhase{
+	models.MergeResults,
+	models.MergeApprovals,
+	models.MergeMemberships,
+	models.MergeInvitations,
+	models.MergeDone,
+}
+
+// the merge jobs that are running in this process, so that a job can't be resumed
+// while it's still going
+var runningMerges = map[string]bool{}
+var runningMergesLock sync.Mutex
+
+type MergeRequest struct {
+	FromUsername string `json:"fromUsername"`
+	ToUsername   string `json:"toUsername"`
+	GroupID      string `json:"groupId"`
+	DryRun       bool   `json:"dryRun"`
+}
+
+// MergeReport lists the records that a merge would change
+type MergeReport struct {
+	FromUsername       string   `json:"fromUsername"`
+	ToUsername         string   `json:"toUsername"`
+	GroupID            string   `json:"groupId"`
+	ResultIDs          []string `json:"resultIds"`
+	ApprovalIDs        []string `json:"approvalIds"`
+	MembershipGroupIDs []string `json:"membershipGroupIds"`
+	InvitationIDs      []string `json:"invitationIds"`
+
+	// results that both users played in, which can't be merged
+	ConflictingResultIDs []string `json:"conflictingResultIds"`
+}
+
+// PostMerge moves everything recorded against one username to another. Superusers can
+// merge everywhere, and group admins can merge within their group. With dryRun set, it
+// only reports what would change. Otherwise it starts a merge job in the background
+func PostMerge(c *gin.Context) {
+	var request MergeRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	if valid, reason := validateMergeRequest(ctx, &request); !valid {
+		Error.Printf("Error validating merge request: %s\n", reason)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !canMerge(ctx, c, request.GroupID) {
+		return
+	}
+
+	job := models.MergeJob{
+		FromUsername: request.FromUsername,
+		ToUsername:   request.ToUsername,
+		GroupID:      request.GroupID,
+		CreatedBy:    callingUsername,
+		Status:       models.MergeRunning,
+		Phase:        models.MergeResults,
+	}
+
+	success, report := createMergeReport(ctx, &job)
+	if !success {
+		Error.Printf("Could not find records to merge from %s to %s\n", job.FromUsername, job.ToUsername)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if request.DryRun {
+		c.IndentedJSON(http.StatusOK, report)
+		return
+	}
+
+	if len(report.ConflictingResultIDs) > 0 {
+		Error.Printf("Users %s and %s both played in %d results\n", job.FromUsername, job.ToUsername, len(report.ConflictingResultIDs))
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	if success := db.AddMergeJob(ctx, &job); !success {
+		Error.Printf("Could not add merge job from %s to %s\n", job.FromUsername, job.ToUsername)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	details := fmt.Sprintf("merge job %s", job.ID)
+	if len(job.GroupID) > 0 {
+		details += fmt.Sprintf(" in group %s", job.GroupID)
+	}
+
+	recordAudit(ctx, &models.AuditEntry{
+		Type:           models.IdentitiesMerged,
+		Username:       callingUsername,
+		TargetUsername: job.FromUsername,
+		IPAddress:      c.ClientIP(),
+		Details:        details,
+	})
+
+	Info.Printf("User %s started merge job %s from %s to %s\n", callingUsername, job.ID, job.FromUsername, job.ToUsername)
+
+	startMergeJob(job)
+
+	c.IndentedJSON(http.StatusAccepted, job)
+}
+
+// GetMerge gets a merge job, including how far it has got
+func GetMerge(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, job := getMergeJob(ctx, c)
+	if !success {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, job)
+}
+
+// ResumeMerge restarts a merge job that failed or was interrupted, from the phase it
+// had reached
+func ResumeMerge(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, job := getMergeJob(ctx, c)
+	if !success {
+		return
+	}
+
+	if job.Status == models.MergeCompleted {
+		Error.Printf("Merge job %s has already completed\n", job.ID)
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	if isMergeRunning(job.ID) {
+		Error.Printf("Merge job %s is already running\n", job.ID)
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	job.Status = models.MergeRunning
+	job.LastError = ""
+
+	if success := db.UpdateMergeJob(ctx, job); !success {
+		Error.Printf("Could not update merge job %s\n", job.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("User %s resumed merge job %s\n", c.GetString("username"), job.ID)
+
+	startMergeJob(*job)
+
+	c.IndentedJSON(http.StatusAccepted, job)
+}
+
+// ResumeMergeJobs restarts any merge jobs that were running when the server stopped
+func ResumeMergeJobs(ctx context.Context) {
+	success, jobs := db.GetMergeJobs(ctx)
+	if !success {
+		Error.Println("Could not get merge jobs")
+		return
+	}
+
+	for _, j := range jobs {
+		if j.Status == models.MergeRunning {
+			Info.Printf("Resuming merge job %s\n", j.ID)
+			startMergeJob(j)
+		}
+	}
+}
+
+func validateMergeRequest(ctx context.Context, request *MergeRequest) (bool, string) {
+	if len(request.FromUsername) <= 0 {
+		return false, "from username is missing"
+	}
+
+	if len(request.ToUsername) <= 0 {
+		return false, "to username is missing"
+	}
+
+	if request.FromUsername == request.ToUsername {
+		return false, "cannot merge a user into themselves"
+	}
+
+	if !db.UserExists(ctx, request.ToUsername) {
+		return false, fmt.Sprintf("user %s does not exist", request.ToUsername)
+	}
+
+	// a group admin can only merge into someone who's already in their group
+	if len(request.GroupID) > 0 && !db.IsInGroup(ctx, request.GroupID, request.ToUsername) {
+		return false, fmt.Sprintf("user %s is not in group %s", request.ToUsername, request.GroupID)
+	}
+
+	if models.IsGuestUsername(request.FromUsername) {
+		success, guest := db.GetGuestPlayer(ctx, request.FromUsername)
+		if !success {
+			return false, fmt.Sprintf("guest player %s does not exist", request.FromUsername)
+		}
+
+		if len(request.GroupID) > 0 && guest.GroupID != request.GroupID {
+			return false, fmt.Sprintf("guest player %s is not in group %s", request.FromUsername, request.GroupID)
+		}
+	} else if !db.UserExists(ctx, request.FromUsername) {
+		return false, fmt.Sprintf("user %s does not exist", request.FromUsername)
+	}
+
+	return true, ""
+}
+
+// returns whether the calling user can merge identities in the given group, or
+// everywhere if the group ID is empty, aborting the request if not
+func canMerge(ctx context.Context, c *gin.Context, groupId string) bool {
+	callingUsername := c.GetString("username")
+
+	if UserHasPermission(callingUsername, models.Superuser) {
+		return true
+	}
+
+	if len(groupId) <= 0 {
+		Error.Printf("User %s cannot merge identities in all groups\n", callingUsername)
+		c.AbortWithStatus(http.StatusForbidden)
+		return false
+	}
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false
+	}
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot merge identities in group %s\n", callingUsername, groupId)
+		c.AbortWithStatus(http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// gets the merge job in the mergeId parameter, aborting the request if it doesn't exist
+// or the calling user can't see it
+func getMergeJob(ctx context.Context, c *gin.Context) (bool, *models.MergeJob) {
+	mergeId := c.Param("mergeId")
+
+	success, job := db.GetMergeJob(ctx, mergeId)
+	if !success {
+		Error.Printf("Merge job %s does not exist\n", mergeId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	if !canMerge(ctx, c, job.GroupID) {
+		return false, nil
+	}
+
+	return true, job
+}
+
+func createMergeReport(ctx context.Context, job *models.MergeJob) (bool, *MergeReport) {
+	report := MergeReport{
+		FromUsername:         job.FromUsername,
+		ToUsername:           job.ToUsername,
+		GroupID:              job.GroupID,
+		ResultIDs:            []string{},
+		ApprovalIDs:          []string{},
+		MembershipGroupIDs:   []string{},
+		InvitationIDs:        []string{},
+		ConflictingResultIDs: []string{},
+	}
+
+	success, results := findResultsToMerge(ctx, job)
+	if !success {
+		return false, nil
+	}
+
+	for _, r := range results {
+		report.ResultIDs = append(report.ResultIDs, r.ID)
+
+		if hasPlayer(&r, job.ToUsername) {
+			report.ConflictingResultIDs = append(report.ConflictingResultIDs, r.ID)
+		}
+	}
+
+	success, approvals := findApprovalsToMerge(ctx, job)
+	if !success {
+		return false, nil
+	}
+
+	for _, a := range approvals {
+		report.ApprovalIDs = append(report.ApprovalIDs, a.ID)
+	}
+
+	success, memberships := findMembershipsToMerge(ctx, job)
+	if !success {
+		return false, nil
+	}
+
+	for _, m := range memberships {
+		report.MembershipGroupIDs = append(report.MembershipGroupIDs, m.GroupID)
+	}
+
+	success, invitations := findInvitationsToMerge(ctx, job)
+	if !success {
+		return false, nil
+	}
+
+	for _, i := range invitations {
+		report.InvitationIDs = append(report.InvitationIDs, i.ID)
+	}
+
+	return true, &report
+}
+
+func isMergeRunning(jobId string) bool {
+	runningMergesLock.Lock()
+	defer runningMergesLock.Unlock()
+
+	return runningMerges[jobId]
+}
+
+// runs the merge job in the background, unless it's already running
+func startMergeJob(job models.MergeJob) {
+	runningMergesLock.Lock()
+	defer runningMergesLock.Unlock()
+
+	if runningMerges[job.ID] {
+		return
+	}
+
+	runningMerges[job.ID] = true
+
+	go runMergeJob(job)
+}
+
+func runMergeJob(job models.MergeJob) {
+	defer func() {
+		runningMergesLock.Lock()
+		defer runningMergesLock.Unlock()
+
+		delete(runningMerges, job.ID)
+	}()
+
+	ctx := context.Background()
+
+	for job.Phase != models.MergeDone {
+		var err error
+
+		switch job.Phase {
+		case models.MergeResults:
+			err = mergeResults(ctx, &job)
+		case models.MergeApprovals:
+			err = mergeApprovals(ctx, &job)
+		case models.MergeMemberships:
+			err = mergeMemberships(ctx, &job)
+		case models.MergeInvitations:
+			err = mergeInvitations(ctx, &job)
+		default:
+			err = fmt.Errorf("unknown phase %s", job.Phase)
+		}
+
+		if err != nil {
+			Error.Printf("Merge job %s failed in phase %s: %s\n", job.ID, job.Phase, err)
+
+			job.Status = models.MergeFailed
+			job.LastError = err.Error()
+			db.UpdateMergeJob(ctx, &job)
+			return
+		}
+
+		job.Phase = mergePhases[slices.Index(mergePhases, job.Phase)+1]
+
+		if success := db.UpdateMergeJob(ctx, &job); !success {
+			Error.Printf("Could not update merge job %s\n", job.ID)
+		}
+	}
+
+	if models.IsGuestUsername(job.FromUsername) {
+		markGuestMerged(ctx, &job)
+	}
+
+	job.Status = models.MergeCompleted
+	job.TimeCompleted = time.Now().UTC().Unix()
+
+	if success := db.UpdateMergeJob(ctx, &job); !success {
+		Error.Printf("Could not update merge job %s\n", job.ID)
+	}
+
+	Info.Printf("Merge job %s from %s to %s completed\n", job.ID, job.FromUsername, job.ToUsername)
+}
+
+// each phase re-finds the records that still have the old username after every batch,
+// so it can be interrupted at any point and run again
+
+func mergeResults(ctx context.Context, job *models.MergeJob) error {
+	for {
+		success, results := findResultsToMerge(ctx, job)
+		if !success {
+			return errors.New("could not get results")
+		}
+
+		if len(results) <= 0 {
+			return nil
+		}
+
+		for _, r := range nextMergeBatch(results) {
+			if hasPlayer(&r, job.ToUsername) {
+				return fmt.Errorf("user %s already played in result %s", job.ToUsername, r.ID)
+			}
+
+			for i := range r.Scores {
+				if r.Scores[i].Username == job.FromUsername {
+					r.Scores[i].Username = job.ToUsername
+				}
+			}
+
+			if success := db.UpdateResultScores(ctx, &r); !success {
+				return fmt.Errorf("could not update result %s", r.ID)
+			}
+
+			job.ResultsMerged++
+		}
+
+		if success := db.UpdateMergeJob(ctx, job); !success {
+			return errors.New("could not save progress")
+		}
+	}
+}
+
+func mergeApprovals(ctx context.Context, job *models.MergeJob) error {
+	for {
+		success, approvals := findApprovalsToMerge(ctx, job)
+		if !success {
+			return errors.New("could not get approvals")
+		}
+
+		if len(approvals) <= 0 {
+			return nil
+		}
+
+		for _, a := range nextMergeBatch(approvals) {
+			a.Username = job.ToUsername
+
+			if success := db.UpdateApproval(ctx, &a); !success {
+				return fmt.Errorf("could not update approval %s", a.ID)
+			}
+
+			job.ApprovalsMerged++
+		}
+
+		if success := db.UpdateMergeJob(ctx, job); !success {
+			return errors.New("could not save progress")
+		}
+	}
+}
+
+func mergeMemberships(ctx context.Context, job *models.MergeJob) error {
+	for {
+		success, memberships := findMembershipsToMerge(ctx, job)
+		if !success {
+			return errors.New("could not get group memberships")
+		}
+
+		if len(memberships) <= 0 {
+			return nil
+		}
+
+		for _, m := range nextMergeBatch(memberships) {
+			if db.IsInGroup(ctx, m.GroupID, job.ToUsername) {
+				// the user can only be in the group once
+				if success := db.DeleteGroupMembership(ctx, m.GroupID, job.FromUsername); !success {
+					return fmt.Errorf("could not remove %s from group %s", job.FromUsername, m.GroupID)
+				}
+			} else {
+				m.Username = job.ToUsername
+
+				if success := db.UpdateGroupMembership(ctx, &m); !success {
+					return fmt.Errorf("could not update membership %s", m.ID)
+				}
+			}
+
+			job.MembershipsMerged++
+		}
+
+		if success := db.UpdateMergeJob(ctx, job); !success {
+			return errors.New("could not save progress")
+		}
+	}
+}
+
+func mergeInvitations(ctx context.Context, job *models.MergeJob) error {
+	for {
+		success, invitations := findInvitationsToMerge(ctx, job)
+		if !success {
+			return errors.New("could not get group invitations")
+		}
+
+		if len(invitations) <= 0 {
+			return nil
+		}
+
+		for _, i := range nextMergeBatch(invitations) {
+			if i.Username == job.FromUsername {
+				i.Username = job.ToUsername
+			}
+
+			if i.InviterUsername == job.FromUsername {
+				i.InviterUsername = job.ToUsername
+			}
+
+			if success := db.UpdateGroupInvitation(ctx, &i); !success {
+				return fmt.Errorf("could not update invitation %s", i.ID)
+			}
+
+			job.InvitationsMerged++
+		}
+
+		if success := db.UpdateMergeJob(ctx, job); !success {
+			return errors.New("could not save progress")
+		}
+	}
+}
+
+// the merged guest player shouldn't be offered for claiming any more
+func markGuestMerged(ctx context.Context, job *models.MergeJob) {
+	success, guest := db.GetGuestPlayer(ctx, job.FromUsername)
+	if !success || len(guest.ClaimedBy) > 0 {
+		return
+	}
+
+	guest.ClaimedBy = job.ToUsername
+	guest.TimeClaimed = time.Now().UTC().Unix()
+
+	if success := db.UpdateGuestPlayer(ctx, guest); !success {
+		Error.Printf("Could not mark guest player %s as claimed\n", guest.Username)
+	}
+}
+
+func findResultsToMerge(ctx context.Context, job *models.MergeJob) (bool, []models.Result) {
+	success, results := db.GetResultsWithPlayer(ctx, job.FromUsername)
+	if !success {
+		return false, nil
+	}
+
+	filtered := []models.Result{}
+	for _, r := range results {
+		if !isInMergeScope(job, r.GroupID) {
+			continue
+		}
+
+		// a result can be returned once for each of its matching scores
+		if slices.ContainsFunc(filtered, func(f models.Result) bool { return f.ID == r.ID }) {
+			continue
+		}
+
+		filtered = append(filtered, r)
+	}
+
+	return true, filtered
+}
+
+func findApprovalsToMerge(ctx context.Context, job *models.MergeJob) (bool, []models.Approval) {
+	success, approvals := db.GetApprovalsByUser(ctx, job.FromUsername)
+	if !success {
+		return false, nil
+	}
+
+	if len(job.GroupID) <= 0 {
+		return true, approvals
+	}
+
+	filtered := []models.Approval{}
+	for _, a := range approvals {
+		if success, result := db.GetResult(ctx, a.ResultID); success && isInMergeScope(job, result.GroupID) {
+			filtered = append(filtered, a)
+		}
+	}
+
+	return true, filtered
+}
+
+func findMembershipsToMerge(ctx context.Context, job *models.MergeJob) (bool, []models.GroupMembership) {
+	// guests can't be members of groups
+	if models.IsGuestUsername(job.FromUsername) {
+		return true, []models.GroupMembership{}
+	}
+
+	success, memberships := db.GetGroupMemberships(ctx, job.FromUsername)
+	if !success {
+		return false, nil
+	}
+
+	filtered := []models.GroupMembership{}
+	for _, m := range memberships {
+		if isInMergeScope(job, m.GroupID) {
+			filtered = append(filtered, m)
+		}
+	}
+
+	return true, filtered
+}
+
+func findInvitationsToMerge(ctx context.Context, job *models.MergeJob) (bool, []models.GroupInvitation) {
+	// guests can't invite or be invited to groups
+	if models.IsGuestUsername(job.FromUsername) {
+		return true, []models.GroupInvitation{}
+	}
+
+	success, invitations := db.GetGroupInvitationsInvolving(ctx, job.FromUsername)
+	if !success {
+		return false, nil
+	}
+
+	filtered := []models.GroupInvitation{}
+	for _, i := range invitations {
+		if isInMergeScope(job, i.GroupID) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return true, filtered
+}
+
+func isInMergeScope(job *models.MergeJob, groupId string) bool {
+	return len(job.GroupID) <= 0 || job.GroupID == groupId
+}
+
+func hasPlayer(result *models.Result, username string) bool {
+	return slices.ContainsFunc(result.Scores, func(s models.PlayerScore) bool {
+		return s.Username == username
+	})
+}
+
+func nextMergeBatch[T any](items []T) []T {
+	if len(items) > mergeBatchSize {
+		return items[:mergeBatchSize]
+	}
+
+	return items
+}
@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+	"phrasmotica/bore-score-api/notifications"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var notifier = notifications.NewDispatcher()
+
+// AddNotificationChannel registers a channel that notifications are delivered through
+// in addition to the in-app inbox
+func AddNotificationChannel(channel notifications.Channel) {
+	notifier.Register(channel)
+}
+
+func GetNotifications(c *gin.Context) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if username != callingUsername {
+		Error.Println("Cannot get another user's notifications")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	success, page, pageSize := parsePagination(c)
+	if !success {
+		Error.Println("Invalid pagination parameters")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	unreadOnly := c.Query("unread") == strconv.Itoa(1)
+
+	ctx := context.TODO()
+
+	success, userNotifications := db.GetNotifications(ctx, username)
+	if !success {
+		Error.Printf("Could not get notifications for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	filteredNotifications := []models.Notification{}
+
+	for _, n := range userNotifications {
+		if !unreadOnly || !n.Read {
+			filteredNotifications = append(filteredNotifications, n)
+		}
+	}
+
+	// newest first
+	sort.SliceStable(filteredNotifications, func(i, j int) bool {
+		return filteredNotifications[i].TimeCreated > filteredNotifications[j].TimeCreated
+	})
+
+	pagedNotifications := paginate(filteredNotifications, page, pageSize)
+
+	Info.Printf("Got %d notifications for user %s\n", len(pagedNotifications), username)
+
+	c.IndentedJSON(http.StatusOK, pagedNotifications)
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	username := c.Param("username")
+	notificationId := c.Param("notificationId")
+	callingUsername := c.GetString("username")
+
+	if username != callingUsername {
+		Error.Println("Cannot update another user's notifications")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, notification := db.GetNotification(ctx, notificationId)
+	if !success || notification.Username != username {
+		Error.Printf("Notification %s does not exist for user %s\n", notificationId, username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if notification.Read {
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := markRead(ctx, notification); !success {
+		Error.Printf("Could not mark notification %s as read\n", notification.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Marked notification %s as read\n", notification.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if username != callingUsername {
+		Error.Println("Cannot update another user's notifications")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, userNotifications := db.GetNotifications(ctx, username)
+	if !success {
+		Error.Printf("Could not get notifications for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	markedCount := 0
+
+	for _, n := range userNotifications {
+		if n.Read {
+			continue
+		}
+
+		if success := markRead(ctx, &n); !success {
+			Error.Printf("Could not mark notification %s as read\n", n.ID)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		markedCount++
+	}
+
+	Info.Printf("Marked %d notifications as read for user %s\n", markedCount, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+func markRead(ctx context.Context, notification *models.Notification) bool {
+	notification.Read = true
+	notification.TimeRead = time.Now().UTC().Unix()
+
+	return db.UpdateNotification(ctx, notification)
+}
+
+// puts the notification in the user's inbox and passes it on to any other channels
+func notify(ctx context.Context, notification *models.Notification) {
+	if success := db.AddNotification(ctx, notification); !success {
+		Error.Printf("Could not add %s notification for user %s\n", notification.Type, notification.Username)
+		return
+	}
+
+	notifier.Dispatch(ctx, notification)
+}
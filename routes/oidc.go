@@ -0,0 +1,397 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"phrasmotica/bore-score-api/oidc"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// how long the user has to sign in with their provider
+const loginStateLifetime = 10 * time.Minute
+
+var oidcProvider *oidc.Provider
+
+// whether signing in with an unknown external identity creates a new account
+var oidcAllowSignup = envFlag("OIDC_ALLOW_SIGNUP")
+
+var invalidUsernameChars = regexp.MustCompile("[^a-z0-9_-]+")
+
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	State            string `json:"state"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type ExternalIdentityResponse struct {
+	ID            string `json:"id"`
+	Issuer        string `json:"issuer"`
+	Email         string `json:"email"`
+	TimeCreated   int64  `json:"timeCreated"`
+	TimeLastLogin int64  `json:"timeLastLogin"`
+}
+
+// StartOIDC enables signing in with an OpenID Connect provider, if one is configured
+func StartOIDC(ctx context.Context) {
+	provider, ok := oidc.NewProviderFromEnv()
+	if !ok {
+		Info.Println("No OIDC_ISSUER_URL environment variable found, OpenID Connect login is disabled")
+		return
+	}
+
+	oidcProvider = provider
+
+	// the provider might not be up yet, in which case discovery is tried again on first use
+	if err := provider.Discover(ctx); err != nil {
+		Error.Printf("Could not discover OpenID Connect provider %s: %s\n", provider.Issuer, err)
+		return
+	}
+
+	Info.Printf("Using OpenID Connect provider %s\n", provider.Issuer)
+}
+
+// OIDCLogin starts signing in with the OpenID Connect provider, returning the URL to send
+// the user to. If the caller is logged in, the external identity is linked to their account
+func OIDCLogin(c *gin.Context) {
+	ctx := context.TODO()
+
+	if !ensureOIDCProvider(ctx, c) {
+		return
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		Error.Printf("Could not generate login state: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		Error.Printf("Could not generate nonce: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	verifier, challenge, err := oidc.GeneratePKCE()
+	if err != nil {
+		Error.Printf("Could not generate code verifier: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	loginState := models.LoginState{
+		StateHash:    auth.HashOpaqueToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUsername: c.GetString("username"),
+		TimeExpires:  time.Now().UTC().Add(loginStateLifetime).Unix(),
+	}
+
+	if success := db.AddLoginState(ctx, &loginState); !success {
+		Error.Println("Could not add login state")
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Started OpenID Connect login %s\n", loginState.ID)
+
+	c.IndentedJSON(http.StatusOK, OIDCLoginResponse{
+		AuthorizationURL: oidcProvider.AuthCodeURL(state, nonce, challenge),
+		State:            state,
+	})
+}
+
+// OIDCCallback finishes signing in with the OpenID Connect provider, using the code and
+// state that the provider redirected the user back with
+func OIDCCallback(c *gin.Context) {
+	ctx := context.TODO()
+
+	if !ensureOIDCProvider(ctx, c) {
+		return
+	}
+
+	var request OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.Code) <= 0 || len(request.State) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, loginState := db.GetLoginStateByHash(ctx, auth.HashOpaqueToken(request.State))
+	if !success || loginState.Used || time.Now().UTC().Unix() >= loginState.TimeExpires {
+		Error.Println("Login state is invalid or has expired")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	loginState.Used = true
+
+	if success := db.UpdateLoginState(ctx, loginState); !success {
+		Error.Printf("Could not mark login state %s as used\n", loginState.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	claims, err := oidcProvider.Exchange(ctx, request.Code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		Error.Printf("Could not complete OpenID Connect login %s: %s\n", loginState.ID, err)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	status, user := findOrCreateOIDCUser(ctx, claims, loginState.LinkUsername)
+	if status != http.StatusOK {
+		c.AbortWithStatus(status)
+		return
+	}
+
+	if len(loginState.LinkUsername) > 0 {
+		Info.Printf("Linked external identity to user %s\n", user.Username)
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if requireVerifiedEmailForLogin && !user.EmailVerified {
+		Error.Printf("User %s has not verified their email address\n", user.Username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if user.TwoFactor.Enabled {
+		startTwoFactorChallenge(ctx, c, user)
+		return
+	}
+
+	logIn(ctx, c, user)
+}
+
+// GetExternalIdentities lists the external identities linked to the calling user
+func GetExternalIdentities(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot get the external identities of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, identities := db.GetExternalIdentities(ctx, username)
+	if !success {
+		Error.Printf("Could not get external identities for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	responses := make([]ExternalIdentityResponse, 0)
+
+	for _, i := range identities {
+		responses = append(responses, ExternalIdentityResponse{
+			ID:            i.ID,
+			Issuer:        i.Issuer,
+			Email:         i.Email,
+			TimeCreated:   i.TimeCreated,
+			TimeLastLogin: i.TimeLastLogin,
+		})
+	}
+
+	Info.Printf("Got %d external identities for user %s\n", len(responses), username)
+
+	c.IndentedJSON(http.StatusOK, responses)
+}
+
+// UnlinkExternalIdentity stops the calling user signing in with one of their external identities
+func UnlinkExternalIdentity(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	identityId := c.Param("identityId")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot unlink the external identities of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if success := db.DeleteExternalIdentity(ctx, username, identityId); !success {
+		Error.Printf("Could not unlink external identity %s from user %s\n", identityId, username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Unlinked external identity %s from user %s\n", identityId, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// returns the user for the external identity, linking or creating one as needed. The
+// status is http.StatusOK if a user was found
+func findOrCreateOIDCUser(ctx context.Context, claims *oidc.Claims, linkUsername string) (int, *models.User) {
+	now := time.Now().UTC().Unix()
+
+	if exists, identity := db.GetExternalIdentity(ctx, claims.Issuer, claims.Subject); exists {
+		if len(linkUsername) > 0 && identity.Username != linkUsername {
+			Error.Printf("External identity is already linked to a different user than %s\n", linkUsername)
+			return http.StatusConflict, nil
+		}
+
+		identity.Email = claims.Email
+		identity.TimeLastLogin = now
+
+		if success := db.UpdateExternalIdentity(ctx, identity); !success {
+			Error.Printf("Could not update external identity %s\n", identity.ID)
+		}
+
+		success, user := db.GetUser(ctx, identity.Username)
+		if !success {
+			Error.Printf("Could not get user %s\n", identity.Username)
+			return http.StatusServiceUnavailable, nil
+		}
+
+		return http.StatusOK, user
+	}
+
+	var user *models.User
+
+	if len(linkUsername) > 0 {
+		success, linkUser := db.GetUser(ctx, linkUsername)
+		if !success {
+			Error.Printf("Could not get user %s\n", linkUsername)
+			return http.StatusServiceUnavailable, nil
+		}
+
+		user = linkUser
+	} else if success, emailUser := db.GetUserByEmail(ctx, claims.Email); success {
+		// only trust the email address if both sides have verified it
+		if !claims.EmailVerified || !emailUser.EmailVerified {
+			Error.Printf("Email address of external identity matches unverified user %s\n", emailUser.Username)
+			return http.StatusConflict, nil
+		}
+
+		user = emailUser
+	} else if oidcAllowSignup {
+		success, newUser := createOIDCUser(ctx, claims)
+		if !success {
+			return http.StatusServiceUnavailable, nil
+		}
+
+		user = newUser
+	} else {
+		Error.Println("External identity is not linked to a user, and signing up is disabled")
+		return http.StatusForbidden, nil
+	}
+
+	identity := models.ExternalIdentity{
+		Username:      user.Username,
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		TimeLastLogin: now,
+	}
+
+	if success := db.AddExternalIdentity(ctx, &identity); !success {
+		Error.Printf("Could not link external identity to user %s\n", user.Username)
+		return http.StatusServiceUnavailable, nil
+	}
+
+	Info.Printf("Linked external identity %s to user %s\n", identity.ID, user.Username)
+
+	return http.StatusOK, user
+}
+
+// creates an account for a new external identity. The account has a random password,
+// which the user can reset if they want to log in without the provider
+func createOIDCUser(ctx context.Context, claims *oidc.Claims) (bool, *models.User) {
+	password, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate password: %s\n", err)
+		return false, nil
+	}
+
+	newUser := models.User{
+		ID:            uuid.NewString(),
+		Username:      chooseUsername(ctx, claims),
+		TimeCreated:   time.Now().UTC().Unix(),
+		Email:         claims.Email,
+		Permissions:   []string{},
+		EmailVerified: claims.EmailVerified,
+
+		DisplayName:    claims.Name,
+		ProfilePicture: claims.Picture,
+
+		EmailPreferences: models.DefaultEmailPreferences(),
+		Privacy:          models.DefaultPrivacySettings(),
+	}
+
+	if len(newUser.DisplayName) <= 0 {
+		newUser.DisplayName = newUser.Username
+	}
+
+	// nobody knows this password, so the user doesn't count as having one
+	if err := newUser.HashPassword(password); err != nil {
+		Error.Println("Could not hash password")
+		return false, nil
+	}
+
+	if success := db.AddUser(ctx, &newUser); !success {
+		Error.Printf("Could not add user %s\n", newUser.Username)
+		return false, nil
+	}
+
+	Info.Printf("Created new user %s from external identity\n", newUser.Username)
+
+	return true, &newUser
+}
+
+// picks an unused username based on the external identity's preferred username or email
+func chooseUsername(ctx context.Context, claims *oidc.Claims) string {
+	base := claims.PreferredUsername
+	if len(base) <= 0 {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	base = strings.Trim(invalidUsernameChars.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if len(base) <= 0 {
+		base = "player"
+	}
+
+	username := base
+	for i := 2; db.UserExists(ctx, username) || db.IsUsernameReserved(ctx, username); i++ {
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return username
+}
+
+// aborts the request if OpenID Connect login is disabled or the provider can't be reached
+func ensureOIDCProvider(ctx context.Context, c *gin.Context) bool {
+	if oidcProvider == nil {
+		Error.Println("OpenID Connect login is disabled")
+		c.AbortWithStatus(http.StatusNotFound)
+		return false
+	}
+
+	if err := oidcProvider.Discover(ctx); err != nil {
+		Error.Printf("Could not discover OpenID Connect provider %s: %s\n", oidcProvider.Issuer, err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/mail"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how long a password reset link can be used for
+const passwordResetTokenLifetime = time.Hour
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" bson:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" bson:"token"`
+	NewPassword string `json:"newPassword" bson:"newPassword"`
+}
+
+// ForgotPassword emails a password reset link to the user with the given email address.
+// It always succeeds, so that it can't be used to find out which email addresses are
+// registered
+func ForgotPassword(c *gin.Context) {
+	ctx := context.TODO()
+
+	ipAddress := c.ClientIP()
+	if !checkLoginThrottle(ctx, c, models.IPAddressThrottle, ipAddress) {
+		return
+	}
+
+	// every request counts, so that it can't be used to flood inboxes or probe for email addresses
+	recordLoginFailure(ctx, c, models.IPAddressThrottle, ipAddress)
+
+	var request ForgotPasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Email) <= 0 {
+		Error.Println("Error validating forgot password request: email is missing")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, user := db.GetUserByEmail(ctx, request.Email)
+	if !success {
+		Info.Println("Password reset requested for unknown email address")
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := sendPasswordResetEmail(ctx, user); !success {
+		Error.Printf("Could not send password reset email to user %s\n", user.Username)
+	}
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// ResetPassword sets a new password for the user that the given reset token was issued to
+func ResetPassword(c *gin.Context) {
+	ctx := context.TODO()
+
+	var request ResetPasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateResetPasswordRequest(&request); !success {
+		Error.Printf("Error validating reset password request: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, token := consumeUserToken(ctx, request.Token, models.PasswordResetToken)
+	if !success {
+		Error.Println("Password reset token is invalid or has expired")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	exists, user := db.GetUser(ctx, token.Username)
+	if !exists {
+		Error.Printf("User %s does not exist", token.Username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if err := user.HashPassword(request.NewPassword); err != nil {
+		Error.Println("Could not hash password")
+		c.AbortWithError(http.StatusServiceUnavailable, err)
+		return
+	}
+
+	user.HasPassword = true
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update password for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	invalidateUserTokens(ctx, user.Username, models.PasswordResetToken)
+
+	// whoever knew the old password shouldn't stay logged in
+	revokeOtherSessions(ctx, user.Username, "")
+
+	Info.Printf("Reset password for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// issues a password reset token for the user and emails them a link to use it
+func sendPasswordResetEmail(ctx context.Context, user *models.User) bool {
+	// only the most recent link should work
+	invalidateUserTokens(ctx, user.Username, models.PasswordResetToken)
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate password reset token: %s\n", err)
+		return false
+	}
+
+	resetToken := models.UserToken{
+		Username:    user.Username,
+		Purpose:     models.PasswordResetToken,
+		TokenHash:   tokenHash,
+		TimeExpires: time.Now().UTC().Add(passwordResetTokenLifetime).Unix(),
+	}
+
+	if success := db.AddUserToken(ctx, &resetToken); !success {
+		Error.Printf("Could not add password reset token for user %s\n", user.Username)
+		return false
+	}
+
+	queueEmail(ctx, mail.PasswordResetMessage, user.Email, map[string]string{
+		"Username":  user.Username,
+		"Link":      appLink("/reset-password?token=" + url.QueryEscape(token)),
+		"ExpiresIn": "1 hour",
+	})
+
+	Info.Printf("Issued password reset token %s for user %s\n", resetToken.ID, user.Username)
+
+	return true
+}
+
+func validateResetPasswordRequest(request *ResetPasswordRequest) (bool, string) {
+	if len(request.Token) <= 0 {
+		return false, "token is missing"
+	}
+
+	if len(request.NewPassword) <= 0 {
+		return false, "new password is missing"
+	}
+
+	return true, ""
+}
+
+// marks the given token as used, if it exists, has the given purpose and can still be used
+func consumeUserToken(ctx context.Context, token string, purpose models.TokenPurpose) (bool, *models.UserToken) {
+	success, userToken := findUsableUserToken(ctx, token, purpose)
+	if !success {
+		return false, nil
+	}
+
+	if !markUserTokenUsed(ctx, userToken) {
+		return false, nil
+	}
+
+	return true, userToken
+}
+
+// returns the token if it exists, has the given purpose and can still be used
+func findUsableUserToken(ctx context.Context, token string, purpose models.TokenPurpose) (bool, *models.UserToken) {
+	success, userToken := db.GetUserTokenByHash(ctx, auth.HashOpaqueToken(token))
+	if !success || userToken.Purpose != purpose {
+		return false, nil
+	}
+
+	if !userToken.IsUsable(time.Now().UTC().Unix()) {
+		return false, nil
+	}
+
+	return true, userToken
+}
+
+// marks the token as used, failing if it's been changed since it was read so that
+// concurrent requests can't both use it
+func markUserTokenUsed(ctx context.Context, userToken *models.UserToken) bool {
+	if success := db.UseUserToken(ctx, userToken); !success {
+		Error.Printf("Could not mark token %s as used, it may have been used already\n", userToken.ID)
+		return false
+	}
+
+	return true
+}
+
+// expires all of the user's outstanding tokens with the given purpose
+func invalidateUserTokens(ctx context.Context, username string, purpose models.TokenPurpose) {
+	success, tokens := db.GetUserTokens(ctx, username, purpose)
+	if !success {
+		Error.Printf("Could not get %s tokens for user %s\n", purpose, username)
+		return
+	}
+
+	now := time.Now().UTC().Unix()
+
+	for _, t := range tokens {
+		if !t.IsUsable(now) {
+			continue
+		}
+
+		t.TimeExpires = now
+
+		if success := db.UpdateUserToken(ctx, &t); !success {
+			Error.Printf("Could not invalidate token %s\n", t.ID)
+		}
+	}
+}
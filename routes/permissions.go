@@ -0,0 +1,202 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+type PermissionCatalogueResponse struct {
+	Permissions []models.PermissionInfo `json:"permissions"`
+	Roles       []models.Role           `json:"roles"`
+}
+
+type UserPermissionsResponse struct {
+	Username             string              `json:"username"`
+	Permissions          []string            `json:"permissions"`
+	Roles                []string            `json:"roles"`
+	EffectivePermissions []models.Permission `json:"effectivePermissions"`
+}
+
+type GrantPermissionRequest struct {
+	Permission models.Permission `json:"permission"`
+}
+
+type GrantRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// GetPermissionCatalogue lists the permissions and roles that can be granted
+func GetPermissionCatalogue(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, PermissionCatalogueResponse{
+		Permissions: models.AllPermissions,
+		Roles:       models.AllRoles,
+	})
+}
+
+// GetUserPermissions gets a user's permissions and roles
+func GetUserPermissions(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("User %s does not exist\n", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, createUserPermissionsResponse(user))
+}
+
+// GrantPermission gives a user a permission from the catalogue
+func GrantPermission(c *gin.Context) {
+	var request GrantPermissionRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !models.IsValidPermission(request.Permission) {
+		Error.Printf("Permission %s does not exist\n", request.Permission)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	changePermissions(c, request.Permission, models.PermissionGranted, func(user *models.User) bool {
+		if slices.Contains(user.Permissions, string(request.Permission)) {
+			return false
+		}
+
+		user.Permissions = append(user.Permissions, string(request.Permission))
+		return true
+	})
+}
+
+// RevokePermission takes a permission away from a user. It doesn't affect permissions
+// that the user has through their roles
+func RevokePermission(c *gin.Context) {
+	permission := models.Permission(c.Param("permission"))
+
+	changePermissions(c, permission, models.PermissionRevoked, func(user *models.User) bool {
+		index := slices.Index(user.Permissions, string(permission))
+		if index < 0 {
+			return false
+		}
+
+		user.Permissions = slices.Delete(user.Permissions, index, index+1)
+		return true
+	})
+}
+
+// GrantRole gives a user a role
+func GrantRole(c *gin.Context) {
+	var request GrantRoleRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if exists, _ := models.FindRole(request.Role); !exists {
+		Error.Printf("Role %s does not exist\n", request.Role)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	changeRoles(c, request.Role, models.RoleGranted, func(user *models.User) bool {
+		if slices.Contains(user.Roles, request.Role) {
+			return false
+		}
+
+		user.Roles = append(user.Roles, request.Role)
+		return true
+	})
+}
+
+// RevokeRole takes a role away from a user
+func RevokeRole(c *gin.Context) {
+	role := c.Param("role")
+
+	changeRoles(c, role, models.RoleRevoked, func(user *models.User) bool {
+		index := slices.Index(user.Roles, role)
+		if index < 0 {
+			return false
+		}
+
+		user.Roles = slices.Delete(user.Roles, index, index+1)
+		return true
+	})
+}
+
+// UserHasPermission returns whether the user currently has the permission, according to
+// the database rather than their access token
+func UserHasPermission(username string, permission models.Permission) bool {
+	success, user := db.GetUser(context.TODO(), username)
+	return success && !user.Disabled && user.HasPermission(permission)
+}
+
+func changePermissions(c *gin.Context, permission models.Permission, auditType models.AuditType, change func(*models.User) bool) {
+	// only superusers can make or unmake other superusers
+	if permission == models.Superuser && !UserHasPermission(c.GetString("username"), models.Superuser) {
+		Error.Printf("User %s cannot change the %s permission\n", c.GetString("username"), permission)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	updateUserPermissions(c, auditType, string(permission), change)
+}
+
+func changeRoles(c *gin.Context, role string, auditType models.AuditType, change func(*models.User) bool) {
+	updateUserPermissions(c, auditType, role, change)
+}
+
+// applies the change to the user in the username parameter and audits it. The change
+// returns false if there was nothing to do
+func updateUserPermissions(c *gin.Context, auditType models.AuditType, details string, change func(*models.User) bool) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	success, user := db.GetUser(ctx, username)
+	if !success {
+		Error.Printf("User %s does not exist\n", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if change(user) {
+		if success := db.UpdateUser(ctx, user); !success {
+			Error.Printf("Could not update permissions of user %s\n", username)
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		recordAudit(ctx, &models.AuditEntry{
+			Type:           auditType,
+			Username:       callingUsername,
+			TargetUsername: username,
+			IPAddress:      c.ClientIP(),
+			Details:        details,
+		})
+
+		Info.Printf("User %s changed permissions of user %s: %s %s\n", callingUsername, username, auditType, details)
+	}
+
+	c.IndentedJSON(http.StatusOK, createUserPermissionsResponse(user))
+}
+
+func createUserPermissionsResponse(user *models.User) UserPermissionsResponse {
+	return UserPermissionsResponse{
+		Username:             user.Username,
+		Permissions:          user.Permissions,
+		Roles:                user.Roles,
+		EffectivePermissions: user.EffectivePermissions(),
+	}
+}
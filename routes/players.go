@@ -9,13 +9,17 @@ import (
 )
 
 func GetPlayers(c *gin.Context) {
-	success, players := db.GetAllPlayers(context.TODO())
+	ctx := context.TODO()
+
+	success, allPlayers := db.GetAllPlayers(ctx)
 	if !success {
 		Error.Println("Could not get players")
 		c.AbortWithStatus(http.StatusServiceUnavailable)
 		return
 	}
 
+	players := filterVisiblePlayers(ctx, allPlayers, c.GetString("username"))
+
 	Info.Printf("Got %d players\n", len(players))
 
 	c.IndentedJSON(http.StatusOK, players)
@@ -33,6 +37,12 @@ func GetPlayersInGroup(c *gin.Context) {
 		return
 	}
 
+	if !apiKeyAllowsGroup(c, group.ID) {
+		Error.Printf("API key cannot be used for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	callingUsername := c.GetString("username")
 
 	if !canSeeGroup(ctx, group, callingUsername, false) {
@@ -41,13 +51,15 @@ func GetPlayersInGroup(c *gin.Context) {
 		return
 	}
 
-	success, players := db.GetPlayersInGroup(ctx, group.ID)
+	success, groupPlayers := db.GetPlayersInGroup(ctx, group.ID)
 	if !success {
 		Error.Printf("Could not get players in group %s\n", group.ID)
 		c.AbortWithStatus(http.StatusServiceUnavailable)
 		return
 	}
 
+	players := filterVisiblePlayers(ctx, groupPlayers, callingUsername)
+
 	Info.Printf("Got %d players\n", len(players))
 
 	c.IndentedJSON(http.StatusOK, players)
@@ -56,148 +68,33 @@ func GetPlayersInGroup(c *gin.Context) {
 func GetPlayer(c *gin.Context) {
 	username := c.Param("username")
 
-	success, player := db.GetPlayer(context.TODO(), username)
-
-	if !success {
-		Error.Printf("Player %s does not exist\n", username)
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
-
-	Info.Printf("Got player %s\n", username)
-
-	c.IndentedJSON(http.StatusOK, player)
-}
-
-func PostPlayer(c *gin.Context) {
-	var newPlayer models.Player
-
 	ctx := context.TODO()
 
-	if err := c.BindJSON(&newPlayer); err != nil {
-		Error.Println("Invalid body format")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if success, err := validatePlayer(&newPlayer); !success {
-		Error.Printf("Error validating new player %s: %s\n", newPlayer.DisplayName, err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if db.PlayerExists(ctx, newPlayer.Username) {
-		Error.Printf("Player %s already exists", newPlayer.Username)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	success := db.AddPlayer(ctx, &newPlayer)
-	if !success {
-		Error.Printf("Could not add player %s\n", newPlayer.Username)
-		c.AbortWithStatus(http.StatusServiceUnavailable)
-		return
-	}
-
-	Info.Printf("Added player %s\n", newPlayer.Username)
-
-	c.IndentedJSON(http.StatusCreated, newPlayer)
-}
-
-func validatePlayer(player *models.Player) (bool, string) {
-	if len(player.Username) <= 0 {
-		return false, "player username is missing"
-	}
+	success, user := db.GetUser(ctx, username)
 
-	if len(player.DisplayName) <= 0 {
-		return false, "player display name is missing"
-	}
-
-	return true, ""
-}
-
-func UpdatePlayer(c *gin.Context) {
-	username := c.Param("username")
-	callingUsername := c.GetString("username")
-
-	var player models.Player
-
-	ctx := context.TODO()
-
-	if err := c.BindJSON(&player); err != nil {
-		Error.Println("Invalid body format")
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	// TODO: only need profile picture and display name
-	if success, err := validatePlayer(&player); !success {
-		Error.Printf("Error validating player %s: %s\n", player.DisplayName, err)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-
-	if username != player.Username {
-		Error.Println("Update request is for wrong player")
-		c.AbortWithStatus(http.StatusForbidden)
-		return
-	}
-
-	if callingUsername != player.Username {
-		Error.Println("Cannot update a different player")
-		c.AbortWithStatus(http.StatusForbidden)
-		return
-	}
-
-	exists, existingPlayer := db.GetPlayer(ctx, player.Username)
-	if !exists {
-		Error.Printf("Player %s does not exist", player.Username)
+	// players who can't be seen are indistinguishable from ones that don't exist
+	if !success || !canSeeProfile(ctx, c.GetString("username"), user) {
+		Error.Printf("Player %s does not exist\n", username)
 		c.AbortWithStatus(http.StatusNotFound)
 		return
 	}
 
-	// ensure we update the correct entity
-	player.ID = existingPlayer.ID
+	player := user.ToPlayer()
 
-	success := db.UpdatePlayer(ctx, &player)
-	if !success {
-		Error.Printf("Could not update player %s\n", player.Username)
-		c.AbortWithStatus(http.StatusServiceUnavailable)
-		return
-	}
-
-	Info.Printf("Updated player %s\n", player.Username)
+	Info.Printf("Got player %s\n", username)
 
-	c.IndentedJSON(http.StatusNoContent, nil)
+	c.IndentedJSON(http.StatusOK, player)
 }
 
-func DeletePlayer(c *gin.Context) {
-	username := c.Param("username")
+// returns the players whose profiles the calling user can see
+func filterVisiblePlayers(ctx context.Context, players []models.Player, callingUsername string) []models.Player {
+	visiblePlayers := []models.Player{}
 
-	ctx := context.TODO()
-
-	if !db.PlayerExists(ctx, username) {
-		Error.Printf("Player %s does not exist\n", username)
-		c.AbortWithStatus(http.StatusBadRequest)
-		return
+	for _, p := range players {
+		if success, user := db.GetUser(ctx, p.Username); success && canSeeProfile(ctx, callingUsername, user) {
+			visiblePlayers = append(visiblePlayers, p)
+		}
 	}
 
-	success, scrubbedCount := db.ScrubResultsWithPlayer(ctx, username)
-	if !success {
-		Error.Printf("Could not scrub player %s from results\n", username)
-		c.AbortWithStatus(http.StatusServiceUnavailable)
-		return
-	}
-
-	Info.Printf("Scrubbed player %s from %d results\n", username, scrubbedCount)
-
-	if success := db.DeletePlayer(ctx, username); !success {
-		Error.Printf("Could not delete player %s\n", username)
-		c.AbortWithStatus(http.StatusServiceUnavailable)
-		return
-	}
-
-	Info.Printf("Deleted player %s\n", username)
-
-	c.IndentedJSON(http.StatusNoContent, nil)
+	return visiblePlayers
 }
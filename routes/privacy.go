@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+// UpdatePrivacySettings sets who can see the user and how their results are counted
+func UpdatePrivacySettings(c *gin.Context) {
+	var settings models.PrivacySettings
+	if err := c.BindJSON(&settings); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !models.IsValidProfileVisibility(settings.ProfileVisibility) {
+		Error.Printf("Error validating privacy settings: profile visibility %s does not exist\n", settings.ProfileVisibility)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	user.Privacy = settings
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update privacy settings for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Updated privacy settings for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, user.Privacy)
+}
+
+// returns whether the calling user can see the user's profile
+func canSeeProfile(ctx context.Context, callingUsername string, user *models.User) bool {
+	if callingUsername == user.Username {
+		return true
+	}
+
+	switch user.Privacy.ProfileVisibility {
+	case models.VisibleToMembers:
+		return len(callingUsername) > 0 && sharesGroup(ctx, callingUsername, user.Username)
+	case models.VisibleToFriends:
+		return len(callingUsername) > 0 && areFriends(ctx, callingUsername, user.Username)
+	default:
+		return true
+	}
+}
+
+// returns whether the two users are members of at least one of the same groups
+func sharesGroup(ctx context.Context, username string, otherUsername string) bool {
+	success, memberships := db.GetGroupMemberships(ctx, username)
+	if !success {
+		return false
+	}
+
+	for _, m := range memberships {
+		if db.IsInGroup(ctx, m.GroupID, otherUsername) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns whether the result includes a user whose results need their approval, and
+// who hasn't approved it yet
+func isAwaitingPrivateApproval(ctx context.Context, result *models.Result) bool {
+	success, approvals := db.GetApprovals(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get approvals for result %s\n", result.ID)
+		return true
+	}
+
+	latestApprovals := computeLatestApprovals(approvals)
+
+	for _, s := range result.Scores {
+		if models.IsGuestUsername(s.Username) {
+			continue
+		}
+
+		success, user := db.GetUser(ctx, s.Username)
+		if !success || !user.Privacy.ResultsNeedApproval {
+			continue
+		}
+
+		approved := slices.ContainsFunc(latestApprovals, func(a models.Approval) bool {
+			return a.Username == user.Username && a.ApprovalStatus == models.Approved
+		})
+
+		if !approved {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns whether the user should be left out of the group's leaderboards
+func isHiddenFromLeaderboard(ctx context.Context, group *models.Group, username string) bool {
+	if group.Visibility == models.Private || models.IsGuestUsername(username) {
+		return false
+	}
+
+	success, user := db.GetUser(ctx, username)
+	return success && !user.Privacy.ShowInPublicLeaderboards
+}
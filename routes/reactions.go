@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/events"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactionSummary counts the users who reacted to a result with an emoji
+type ReactionSummary struct {
+	Emoji     string            `json:"emoji"`
+	Count     int               `json:"count"`
+	Reactions []models.Reaction `json:"reactions"`
+}
+
+// GetReactions gets the reactions to a result, grouped by emoji with the most popular first
+func GetReactions(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	success, reactions := db.GetReactions(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get reactions for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	summaries := summariseReactions(reactions)
+
+	Info.Printf("Got %d reactions for result %s\n", len(reactions), result.ID)
+
+	c.IndentedJSON(http.StatusOK, summaries)
+}
+
+// PostReaction reacts to a result with an emoji. Players in the result, and members of
+// its group, can do this. Reacting with the same emoji twice does nothing
+func PostReaction(c *gin.Context) {
+	var request ReactionRequest
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if !models.IsValidEmoji(request.Emoji) {
+		Error.Printf("Error validating reaction: %q is not an emoji\n", request.Emoji)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !canContributeToResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot react to result %s\n", callingUsername, result.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if isGroupArchived(ctx, result.GroupID) {
+		Error.Printf("Group %s is archived\n", result.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, reactions := db.GetReactions(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get reactions for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, r := range reactions {
+		if r.Username == callingUsername && r.Emoji == request.Emoji {
+			Info.Printf("User %s has already reacted to result %s with %s\n", callingUsername, result.ID, request.Emoji)
+			c.IndentedJSON(http.StatusOK, r)
+			return
+		}
+	}
+
+	newReaction := models.Reaction{
+		ResultID: result.ID,
+		Username: callingUsername,
+		Emoji:    request.Emoji,
+	}
+
+	if success := db.AddReaction(ctx, &newReaction); !success {
+		Error.Printf("Could not add reaction to result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Added reaction %s to result %s\n", newReaction.ID, result.ID)
+
+	publishGroupEvent(result.GroupID, events.ReactionAdded, newReaction)
+
+	c.IndentedJSON(http.StatusCreated, newReaction)
+}
+
+// DeleteReaction removes a reaction from a result. The user who reacted can do this, as
+// can admins of the result's group
+func DeleteReaction(c *gin.Context) {
+	reactionId := c.Param("reactionId")
+
+	ctx := context.TODO()
+
+	success, result := getVisibleResult(ctx, c)
+	if !success {
+		return
+	}
+
+	success, reactions := db.GetReactions(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get reactions for result %s\n", result.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	var reaction *models.Reaction
+	for i := range reactions {
+		if reactions[i].ID == reactionId {
+			reaction = &reactions[i]
+		}
+	}
+
+	if reaction == nil {
+		Error.Printf("Reaction %s does not exist\n", reactionId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if reaction.Username != callingUsername && !canModerateResult(ctx, result, callingUsername) {
+		Error.Printf("User %s cannot delete reaction %s\n", callingUsername, reactionId)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if success := db.DeleteReaction(ctx, result.ID, reactionId); !success {
+		Error.Printf("Could not delete reaction %s\n", reactionId)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Deleted reaction %s from result %s\n", reactionId, result.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// groups the reactions by emoji, most popular first. Ties go to the emoji that was
+// reacted with first
+func summariseReactions(reactions []models.Reaction) []ReactionSummary {
+	sorted := slices.Clone(reactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].TimeCreated < sorted[j].TimeCreated
+	})
+
+	summaries := []ReactionSummary{}
+	indexes := map[string]int{}
+
+	for _, r := range sorted {
+		i, ok := indexes[r.Emoji]
+		if !ok {
+			i = len(summaries)
+			indexes[r.Emoji] = i
+			summaries = append(summaries, ReactionSummary{
+				Emoji:     r.Emoji,
+				Reactions: []models.Reaction{},
+			})
+		}
+
+		summaries[i].Count++
+		summaries[i].Reactions = append(summaries[i].Reactions, r)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Count > summaries[j].Count
+	})
+
+	return summaries
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"phrasmotica/bore-score-api/data"
+	"phrasmotica/bore-score-api/events"
 	"phrasmotica/bore-score-api/models"
 	"sort"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 )
 
@@ -23,6 +26,10 @@ type ResultResponse struct {
 	CooperativeWin   bool                  `json:"cooperativeWin" bson:"cooperativeWin"`
 	Scores           []models.PlayerScore  `json:"scores" bson:"scores"`
 	ApprovalStatus   models.ApprovalStatus `json:"approvalStatus" bson:"approvalStatus"`
+	Attachments      []models.Attachment   `json:"attachments" bson:"attachments"`
+
+	// the guest players in the scores, keyed by username
+	Guests map[string]GuestPlayerResponse `json:"guests,omitempty" bson:"guests,omitempty"`
 }
 
 func GetResults(c *gin.Context) {
@@ -39,7 +46,7 @@ func GetResults(c *gin.Context) {
 	}
 
 	callingUsername := c.GetString("username")
-	filteredResults := filterResults(ctx, results, callingUsername)
+	filteredResults := filterResults(ctx, restrictToAPIKeyGroup(c, results), callingUsername)
 
 	Info.Printf("Got %d results\n", len(filteredResults))
 
@@ -58,6 +65,12 @@ func GetResultsForGroup(c *gin.Context) {
 		return
 	}
 
+	if !apiKeyAllowsGroup(c, group.ID) {
+		Error.Printf("API key cannot be used for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	callingUsername := c.GetString("username")
 
 	if !canSeeGroup(ctx, group, callingUsername, false) {
@@ -103,7 +116,7 @@ func GetResultsForUser(c *gin.Context) {
 		return
 	}
 
-	filteredResults := filterResults(ctx, results, callingUsername)
+	filteredResults := filterResults(ctx, restrictToAPIKeyGroup(c, results), callingUsername)
 
 	Info.Printf("Got %d results\n", len(filteredResults))
 
@@ -125,15 +138,27 @@ func PostResult(c *gin.Context) {
 		return
 	}
 
+	if !apiKeyAllowsGroup(c, newResult.GroupID) {
+		Error.Printf("API key cannot be used for group %s\n", newResult.GroupID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
 	ctx := context.TODO()
 
-	if !db.GameExists(ctx, newResult.GameID) {
+	success, game := db.GetGame(ctx, newResult.GameID)
+	if !success {
 		Error.Printf("Game %s does not exist\n", newResult.GameID)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
 	for _, score := range newResult.Scores {
+		// guests are checked against the group below, and new guests don't exist yet
+		if models.IsGuestUsername(score.Username) || len(score.GuestName) > 0 {
+			continue
+		}
+
 		if !db.PlayerExists(ctx, score.Username) {
 			Error.Printf("Player %s does not exist\n", score.Username)
 			c.AbortWithStatus(http.StatusBadRequest)
@@ -141,16 +166,38 @@ func PostResult(c *gin.Context) {
 		}
 	}
 
+	callingUsername := c.GetString("username")
+
+	for _, score := range newResult.Scores {
+		if models.IsGuestUsername(score.Username) || len(score.GuestName) > 0 {
+			continue
+		}
+
+		if success, user := db.GetUser(ctx, score.Username); !success || !canAddToResult(ctx, callingUsername, user) {
+			Error.Printf("User %s only accepts results from friends\n", score.Username)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+
+	var group *models.Group
+
 	if len(newResult.GroupID) > 0 {
-		success, group := db.GetGroup(ctx, newResult.GroupID)
+		success, group = db.GetGroup(ctx, newResult.GroupID)
 		if !success {
 			Error.Printf("Group %s does not exist\n", newResult.GroupID)
 			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
 
+		if group.Archived {
+			Error.Printf("Group %s is archived\n", group.ID)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
 		for _, score := range newResult.Scores {
-			if !db.IsInGroup(ctx, group.ID, score.Username) {
+			if !isInGroupOrGuest(ctx, group.ID, &score) {
 				Error.Printf("Player %s is not in group %s\n", score.Username, newResult.GroupID)
 				c.AbortWithStatus(http.StatusForbidden)
 				return
@@ -158,6 +205,30 @@ func PostResult(c *gin.Context) {
 		}
 	}
 
+	previousLeader := ""
+	if group != nil {
+		previousLeader = findLeader(ctx, group, game)
+	}
+
+	for i := range newResult.Scores {
+		score := &newResult.Scores[i]
+		if len(score.GuestName) <= 0 {
+			continue
+		}
+
+		success, guest := addGuestPlayer(ctx, group.ID, score.GuestName, callingUsername)
+		if !success {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		score.Username = guest.Username
+		score.GuestName = ""
+	}
+
+	newResult.ID = uuid.NewString()
+	newResult.TimeCreated = time.Now().UTC().Unix()
+
 	if success := db.AddResult(ctx, &newResult); !success {
 		Error.Println("Could not add result")
 		c.AbortWithStatus(http.StatusServiceUnavailable)
@@ -166,6 +237,45 @@ func PostResult(c *gin.Context) {
 
 	Info.Printf("Added result for game %s\n", newResult.GameID)
 
+	// everyone else in the result needs to approve it, apart from guests who can't
+	for _, score := range newResult.Scores {
+		if score.Username != callingUsername && !models.IsGuestUsername(score.Username) {
+			notify(ctx, &models.Notification{
+				Username:     score.Username,
+				Type:         models.ApprovalRequestNotification,
+				FromUsername: callingUsername,
+				GroupID:      newResult.GroupID,
+				ResultID:     newResult.ID,
+			})
+		}
+	}
+
+	if group != nil {
+		// results that are still private get announced once they've been approved
+		if !isAwaitingPrivateApproval(ctx, &newResult) {
+			announceResult(ctx, &newResult)
+		}
+
+		recordActivity(ctx, &models.Activity{
+			GroupID:  group.ID,
+			Type:     models.ResultPosted,
+			Username: callingUsername,
+			ResultID: newResult.ID,
+			GameID:   game.ID,
+		})
+
+		if leader := findLeader(ctx, group, game); leader != previousLeader && len(leader) > 0 {
+			recordActivity(ctx, &models.Activity{
+				GroupID:        group.ID,
+				Type:           models.LeaderChanged,
+				Username:       leader,
+				TargetUsername: previousLeader,
+				ResultID:       newResult.ID,
+				GameID:         game.ID,
+			})
+		}
+	}
+
 	c.IndentedJSON(http.StatusCreated, newResult)
 }
 
@@ -178,9 +288,38 @@ func validateNewResult(result *models.Result) (bool, string) {
 		return false, "result has duplicated player scores"
 	}
 
+	for _, s := range result.Scores {
+		if len(s.GuestName) > 0 {
+			if len(s.Username) > 0 {
+				return false, "score has both a username and a guest name"
+			}
+
+			if len(result.GroupID) <= 0 {
+				return false, "guest players can only play in groups"
+			}
+		} else if len(s.Username) <= 0 {
+			return false, "score is missing a username"
+		}
+	}
+
 	return true, ""
 }
 
+// returns whether the score is for a member of the group, or one of its guests. New
+// guests are always allowed
+func isInGroupOrGuest(ctx context.Context, groupId string, score *models.PlayerScore) bool {
+	if len(score.GuestName) > 0 {
+		return true
+	}
+
+	if models.IsGuestUsername(score.Username) {
+		success, guest := db.GetGuestPlayer(ctx, score.Username)
+		return success && guest.GroupID == groupId && len(guest.ClaimedBy) <= 0
+	}
+
+	return db.IsInGroup(ctx, groupId, score.Username)
+}
+
 func filterResults(ctx context.Context, results []models.Result, username string) []ResultResponse {
 	filteredResults := []ResultResponse{}
 
@@ -196,6 +335,16 @@ func filterResults(ctx context.Context, results []models.Result, username string
 func createResultResponse(ctx context.Context, result *models.Result) ResultResponse {
 	approvalStatus := computeOverallApproval(ctx, db, result)
 
+	success, attachments := db.GetAttachments(ctx, result.ID)
+	if !success {
+		Error.Printf("Could not get attachments for result %s\n", result.ID)
+		attachments = []models.Attachment{}
+	}
+
+	for i := range attachments {
+		setAttachmentURLs(&attachments[i])
+	}
+
 	return ResultResponse{
 		ID:               result.ID,
 		GameID:           result.GameID,
@@ -207,10 +356,24 @@ func createResultResponse(ctx context.Context, result *models.Result) ResultResp
 		CooperativeWin:   result.CooperativeWin,
 		Scores:           result.Scores,
 		ApprovalStatus:   approvalStatus,
+		Attachments:      attachments,
+		Guests:           findGuestPlayers(ctx, result.Scores),
 	}
 }
 
+// tells the result's group and its webhooks about a new result
+func announceResult(ctx context.Context, result *models.Result) {
+	resultResponse := createResultResponse(ctx, result)
+
+	publishGroupEvent(result.GroupID, events.ResultCreated, resultResponse)
+	triggerWebhooks(ctx, result.GroupID, models.WebhookResultCreated, resultResponse)
+}
+
 func canSeeResult(ctx context.Context, r models.Result, callingUsername string) bool {
+	if !hasPlayer(&r, callingUsername) && isAwaitingPrivateApproval(ctx, &r) {
+		return false
+	}
+
 	if len(r.GroupID) <= 0 {
 		return true
 	}
@@ -229,7 +392,15 @@ func computeOverallApproval(ctx context.Context, db data.IDatabase, result *mode
 
 		latestApprovals := computeLatestApprovals(approvals)
 
-		if len(latestApprovals) == len(result.Scores) {
+		// guests can't approve results, so only users count
+		playerCount := 0
+		for _, s := range result.Scores {
+			if !models.IsGuestUsername(s.Username) {
+				playerCount++
+			}
+		}
+
+		if len(latestApprovals) == playerCount {
 			if all(latestApprovals, isApproved) {
 				approvalStatus = models.Approved
 			} else if all(latestApprovals, isRejected) {
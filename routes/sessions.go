@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how long a session lasts without its refresh token being used
+const refreshTokenLifetime = 30 * 24 * time.Hour
+
+type SessionResponse struct {
+	ID           string `json:"id"`
+	TimeCreated  int64  `json:"timeCreated"`
+	TimeLastUsed int64  `json:"timeLastUsed"`
+	TimeExpires  int64  `json:"timeExpires"`
+	UserAgent    string `json:"userAgent"`
+	IPAddress    string `json:"ipAddress"`
+	Current      bool   `json:"current"`
+}
+
+// GetSessions lists the calling user's active sessions, most recently used first
+func GetSessions(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot get the sessions of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, sessions := db.GetSessions(ctx, username)
+	if !success {
+		Error.Printf("Could not get sessions for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	now := time.Now().UTC().Unix()
+	currentSessionId := c.GetString("sessionId")
+
+	responses := make([]SessionResponse, 0)
+
+	for _, s := range sessions {
+		if !s.IsActive(now) {
+			continue
+		}
+
+		responses = append(responses, SessionResponse{
+			ID:           s.ID,
+			TimeCreated:  s.TimeCreated,
+			TimeLastUsed: s.TimeLastUsed,
+			TimeExpires:  s.TimeExpires,
+			UserAgent:    s.UserAgent,
+			IPAddress:    s.IPAddress,
+			Current:      s.ID == currentSessionId,
+		})
+	}
+
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].TimeLastUsed > responses[j].TimeLastUsed
+	})
+
+	Info.Printf("Got %d sessions for user %s\n", len(responses), username)
+
+	c.IndentedJSON(http.StatusOK, responses)
+}
+
+// RevokeSession logs the calling user out of one of their sessions
+func RevokeSession(c *gin.Context) {
+	ctx := context.TODO()
+
+	username := c.Param("username")
+	sessionId := c.Param("sessionId")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot revoke the sessions of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, session := db.GetSession(ctx, username, sessionId)
+	if !success {
+		Error.Printf("Session %s of user %s does not exist\n", sessionId, username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if success := revokeSession(ctx, session); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Revoked session %s of user %s\n", sessionId, username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// IsSessionActive returns whether the given session exists and has not expired or been
+// revoked. It's used to reject access tokens from sessions that have been logged out
+func IsSessionActive(username string, sessionId string) bool {
+	success, session := db.GetSession(context.TODO(), username, sessionId)
+	return success && session.IsActive(time.Now().UTC().Unix())
+}
+
+// adds the given session, valid for as long as a refresh token
+func startSession(ctx context.Context, session *models.Session) bool {
+	now := time.Now().UTC()
+
+	session.TimeLastUsed = now.Unix()
+	session.TimeExpires = now.Add(refreshTokenLifetime).Unix()
+
+	return db.AddSession(ctx, session)
+}
+
+// creates a new refresh token for the session and extends the session to match it
+func issueRefreshToken(ctx context.Context, session *models.Session) (bool, string) {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate refresh token: %s\n", err)
+		return false, ""
+	}
+
+	now := time.Now().UTC()
+
+	refreshToken := models.UserToken{
+		Username:    session.Username,
+		Purpose:     models.RefreshToken,
+		TokenHash:   tokenHash,
+		Data:        session.ID,
+		TimeExpires: now.Add(refreshTokenLifetime).Unix(),
+	}
+
+	if success := db.AddUserToken(ctx, &refreshToken); !success {
+		Error.Printf("Could not add refresh token for session %s\n", session.ID)
+		return false, ""
+	}
+
+	session.TimeLastUsed = now.Unix()
+	session.TimeExpires = refreshToken.TimeExpires
+
+	if success := db.UpdateSession(ctx, session); !success {
+		Error.Printf("Could not update session %s\n", session.ID)
+		return false, ""
+	}
+
+	return true, token
+}
+
+func revokeSession(ctx context.Context, session *models.Session) bool {
+	if session.Revoked {
+		return true
+	}
+
+	session.Revoked = true
+	session.TimeRevoked = time.Now().UTC().Unix()
+
+	if success := db.UpdateSession(ctx, session); !success {
+		Error.Printf("Could not revoke session %s of user %s\n", session.ID, session.Username)
+		return false
+	}
+
+	return true
+}
+
+// revokes all of the user's sessions apart from the given one, which can be empty
+func revokeOtherSessions(ctx context.Context, username string, keepSessionId string) {
+	success, sessions := db.GetSessions(ctx, username)
+	if !success {
+		Error.Printf("Could not get sessions for user %s\n", username)
+		return
+	}
+
+	now := time.Now().UTC().Unix()
+
+	for _, s := range sessions {
+		if s.ID == keepSessionId || !s.IsActive(now) {
+			continue
+		}
+
+		revokeSession(ctx, &s)
+	}
+}
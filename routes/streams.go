@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"phrasmotica/bore-score-api/events"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how often to send a keep-alive event, so that proxies don't close idle streams
+const streamKeepAliveInterval = 30 * time.Second
+
+var broker = events.NewBroker()
+
+func GetGroupStream(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !db.IsInGroup(ctx, group.ID, callingUsername) {
+		Error.Printf("User %s is not in group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ch := broker.Subscribe(group.ID)
+	defer broker.Unsubscribe(group.ID, ch)
+
+	Info.Printf("User %s subscribed to events for group %s\n", callingUsername, group.ID)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return false
+			}
+
+			c.SSEvent(string(event.Type), event)
+
+			// stop streaming to users as soon as they leave the group
+			if membership, ok := event.Data.(models.GroupMembership); ok && event.Type == events.MembershipRemoved {
+				return membership.Username != callingUsername
+			}
+
+			return true
+
+		case <-keepAlive.C:
+			c.SSEvent("keep-alive", time.Now().UTC().Unix())
+			return true
+
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+
+	Info.Printf("User %s unsubscribed from events for group %s\n", callingUsername, group.ID)
+}
+
+// publishes an event to everyone streaming the group's updates
+func publishGroupEvent(groupId string, eventType events.EventType, data interface{}) {
+	if len(groupId) <= 0 {
+		return
+	}
+
+	broker.Publish(events.Event{
+		Type:    eventType,
+		GroupID: groupId,
+		Data:    data,
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,45 +25,230 @@ func GenerateToken(c *gin.Context) {
 		return
 	}
 
+	// throttle before checking the password, since hashing it is expensive
+	ipAddress := c.ClientIP()
+	if !checkLoginThrottle(ctx, c, models.IPAddressThrottle, ipAddress) {
+		return
+	}
+
 	// check if email exists and password is correct
 	success, user := db.GetUserByEmail(ctx, request.Email)
 	if !success {
 		Error.Printf("Could not get user with email %s\n", request.Email)
+		recordLoginFailure(ctx, c, models.IPAddressThrottle, ipAddress)
 		c.AbortWithStatus(http.StatusServiceUnavailable)
 		return
 	}
 
+	if !checkLoginThrottle(ctx, c, models.AccountThrottle, user.Username) {
+		return
+	}
+
 	credentialError := user.CheckPassword(request.Password)
 	if credentialError != nil {
 		Error.Println("Invalid password")
+		recordLoginFailure(ctx, c, models.AccountThrottle, user.Username)
+		recordLoginFailure(ctx, c, models.IPAddressThrottle, ipAddress)
 		c.AbortWithError(http.StatusUnauthorized, credentialError)
 		return
 	}
 
-	tokenString, err := auth.GenerateJWT(user)
-	if err != nil {
-		Error.Printf("Could not generate token for user with email %s\n", user.Email)
-		c.AbortWithError(http.StatusInternalServerError, err)
+	if user.Disabled {
+		Error.Printf("User %s is disabled\n", user.Username)
+		c.AbortWithStatus(http.StatusForbidden)
 		return
 	}
 
-	Info.Printf("Generated token for user %s\n", user.Username)
+	if requireVerifiedEmailForLogin && !user.EmailVerified {
+		Error.Printf("User %s has not verified their email address\n", user.Username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"token": tokenString})
+	// failures are only forgotten once the user has fully logged in, so that knowing the
+	// password doesn't allow unlimited guesses of the second factor
+	if user.TwoFactor.Enabled {
+		startTwoFactorChallenge(ctx, c, user)
+		return
+	}
+
+	if success, throttle := db.GetLoginThrottle(ctx, models.AccountThrottle, user.Username); success && throttle.FailedAttempts > 0 {
+		resetLoginThrottle(ctx, models.AccountThrottle, user.Username)
+	}
+
+	logIn(ctx, c, user)
 }
 
+// starts a new session for the user and responds with its tokens
+func logIn(ctx context.Context, c *gin.Context, user *models.User) {
+	if user.Disabled {
+		Error.Printf("User %s is disabled\n", user.Username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	session := models.Session{
+		Username:  user.Username,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+
+	if success := startSession(ctx, &session); !success {
+		Error.Printf("Could not start session for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Started session %s for user %s\n", session.ID, user.Username)
+
+	respondWithTokens(ctx, c, user, &session)
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// Each refresh token can only be used once, so if one is used again it has probably been
+// stolen, and the whole session is revoked
 func RefreshToken(c *gin.Context) {
-	currentToken := c.GetString("token")
-	callingUsername := c.GetString("username")
+	ctx := context.TODO()
+
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.RefreshToken) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, token := db.GetUserTokenByHash(ctx, auth.HashOpaqueToken(request.RefreshToken))
+	if !success || token.Purpose != models.RefreshToken {
+		Error.Println("Refresh token does not exist")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	success, session := db.GetSession(ctx, token.Username, token.Data)
+	if !success {
+		Error.Printf("Session %s of user %s does not exist\n", token.Data, token.Username)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if token.Used {
+		Error.Printf("Refresh token %s was reused, revoking session %s of user %s\n", token.ID, session.ID, session.Username)
+		revokeSession(ctx, session)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now().UTC().Unix()
+	if !token.IsUsable(now) || !session.IsActive(now) {
+		Error.Printf("Session %s of user %s has expired or been revoked\n", session.ID, session.Username)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
 
-	tokenString, err := auth.RefreshJWT(currentToken)
+	if success := db.UseUserToken(ctx, token); !success {
+		// another request used the token after it was read
+		if success, latest := db.GetUserTokenByHash(ctx, token.TokenHash); success && latest.Used {
+			Error.Printf("Refresh token %s was reused, revoking session %s of user %s\n", token.ID, session.ID, session.Username)
+			revokeSession(ctx, session)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		Error.Printf("Could not mark refresh token %s as used\n", token.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// get the user again so that the new access token has their current details
+	success, user := db.GetUser(ctx, session.Username)
+	if !success {
+		Error.Printf("Could not get user %s\n", session.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if user.Disabled {
+		Error.Printf("User %s is disabled, revoking session %s\n", user.Username, session.ID)
+		revokeSession(ctx, session)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if requireVerifiedEmailForLogin && !user.EmailVerified {
+		Error.Printf("User %s has not verified their email address\n", user.Username)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	Info.Printf("Refreshed session %s for user %s\n", session.ID, user.Username)
+
+	respondWithTokens(ctx, c, user, session)
+}
+
+// Logout revokes the session that the given refresh token belongs to
+func Logout(c *gin.Context) {
+	ctx := context.TODO()
+
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil || len(request.RefreshToken) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, token := db.GetUserTokenByHash(ctx, auth.HashOpaqueToken(request.RefreshToken))
+	if !success || token.Purpose != models.RefreshToken {
+		Info.Println("Refresh token does not exist, nothing to log out of")
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	success, session := db.GetSession(ctx, token.Username, token.Data)
+	if !success {
+		Info.Printf("Session %s of user %s does not exist, nothing to log out of\n", token.Data, token.Username)
+		c.IndentedJSON(http.StatusNoContent, nil)
+		return
+	}
+
+	if success := revokeSession(ctx, session); !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Logged out of session %s for user %s\n", session.ID, session.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// GetJWKS returns the public keys that access tokens can be verified with
+func GetJWKS(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, auth.JWKS())
+}
+
+// issues a new refresh token for the session, and responds with it and a new access token
+func respondWithTokens(ctx context.Context, c *gin.Context, user *models.User, session *models.Session) {
+	success, refreshToken := issueRefreshToken(ctx, session)
+	if !success {
+		Error.Printf("Could not issue refresh token for session %s\n", session.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	tokenString, err := auth.GenerateJWT(user, session.ID)
 	if err != nil {
-		Error.Printf("Could not refresh token for user %s\n", callingUsername)
+		Error.Printf("Could not generate token for user with email %s\n", user.Email)
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
 
-	Info.Printf("Refreshed token for user %s\n", callingUsername)
+	Info.Printf("Generated token for user %s\n", user.Username)
 
-	c.IndentedJSON(http.StatusOK, gin.H{"token": tokenString})
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"token":        tokenString,
+		"refreshToken": refreshToken,
+	})
 }
@@ -0,0 +1,382 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"phrasmotica/bore-score-api/auth"
+	"phrasmotica/bore-score-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+// how long the user has to enter their code after entering their password
+const twoFactorChallengeLifetime = 5 * time.Minute
+
+const recoveryCodeCount = 10
+
+// the issuer shown in authenticator apps
+const totpIssuer = "BoreScore"
+
+type TwoFactorEnrolmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code"`
+}
+
+type PasswordRequest struct {
+	Password string `json:"password"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorTokenRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
+
+// EnrolTwoFactor generates a new TOTP secret for the calling user. It isn't used until
+// the user confirms it with a code from their authenticator app
+func EnrolTwoFactor(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if user.TwoFactor.Enabled {
+		Error.Printf("User %s already has two-factor authentication enabled\n", user.Username)
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		Error.Printf("Could not generate TOTP secret: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	user.TwoFactor.Secret = secret
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not start two-factor enrolment for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Started two-factor enrolment for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, TwoFactorEnrolmentResponse{
+		Secret: secret,
+		URI:    auth.TOTPURI(totpIssuer, user.Username, secret),
+	})
+}
+
+// ConfirmTwoFactor enables two-factor authentication for the calling user once they've
+// entered a code for their new secret, and returns their recovery codes
+func ConfirmTwoFactor(c *gin.Context) {
+	ctx := context.TODO()
+
+	var request ConfirmTwoFactorRequest
+	if err := c.BindJSON(&request); err != nil || len(request.Code) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	if user.TwoFactor.Enabled {
+		Error.Printf("User %s already has two-factor authentication enabled\n", user.Username)
+		c.AbortWithStatus(http.StatusConflict)
+		return
+	}
+
+	if len(user.TwoFactor.Secret) <= 0 {
+		Error.Printf("User %s has not started two-factor enrolment\n", user.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	valid, step := auth.ValidateTOTP(user.TwoFactor.Secret, request.Code, time.Now().UTC())
+	if !valid {
+		Error.Printf("Invalid two-factor code for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, recoveryCodes := setRecoveryCodes(user)
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	user.TwoFactor.Enabled = true
+	user.TwoFactor.LastStep = step
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not enable two-factor authentication for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Enabled two-factor authentication for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// DisableTwoFactor turns off two-factor authentication for the calling user, after
+// checking their password
+func DisableTwoFactor(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUserWithPassword(ctx, c)
+	if !success {
+		return
+	}
+
+	user.TwoFactor = models.TwoFactorSettings{
+		RecoveryCodes: []string{},
+	}
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not disable two-factor authentication for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Disabled two-factor authentication for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+// RegenerateRecoveryCodes replaces the calling user's recovery codes, after checking
+// their password
+func RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUserWithPassword(ctx, c)
+	if !success {
+		return
+	}
+
+	if !user.TwoFactor.Enabled {
+		Error.Printf("User %s does not have two-factor authentication enabled\n", user.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	success, recoveryCodes := setRecoveryCodes(user)
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update recovery codes for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Regenerated recovery codes for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// GenerateTwoFactorToken is the second step of logging in for users with two-factor
+// authentication. It takes the challenge token from GenerateToken, plus either a code
+// from the user's authenticator app or one of their recovery codes
+func GenerateTwoFactorToken(c *gin.Context) {
+	ctx := context.TODO()
+
+	var request TwoFactorTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if len(request.ChallengeToken) <= 0 || (len(request.Code) <= 0 && len(request.RecoveryCode) <= 0) {
+		Error.Println("Error validating two-factor token request: challenge token or code is missing")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	if !checkLoginThrottle(ctx, c, models.IPAddressThrottle, ipAddress) {
+		return
+	}
+
+	success, challenge := db.GetUserTokenByHash(ctx, auth.HashOpaqueToken(request.ChallengeToken))
+	if !success || challenge.Purpose != models.TwoFactorChallenge || !challenge.IsUsable(time.Now().UTC().Unix()) {
+		Error.Println("Two-factor challenge is invalid or has expired")
+		recordLoginFailure(ctx, c, models.IPAddressThrottle, ipAddress)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if !checkLoginThrottle(ctx, c, models.AccountThrottle, challenge.Username) {
+		return
+	}
+
+	success, user := db.GetUser(ctx, challenge.Username)
+	if !success {
+		Error.Printf("Could not get user %s\n", challenge.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	if !checkSecondFactor(user, &request) {
+		Error.Printf("Invalid two-factor code for user %s\n", user.Username)
+		recordLoginFailure(ctx, c, models.AccountThrottle, user.Username)
+		recordLoginFailure(ctx, c, models.IPAddressThrottle, ipAddress)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	if !markUserTokenUsed(ctx, challenge) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// the user's last used code or remaining recovery codes have changed
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update two-factor settings for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	resetLoginThrottle(ctx, models.AccountThrottle, user.Username)
+
+	logIn(ctx, c, user)
+}
+
+// responds with a token that the user exchanges for a session once they've entered their code
+func startTwoFactorChallenge(ctx context.Context, c *gin.Context, user *models.User) {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		Error.Printf("Could not generate two-factor challenge: %s\n", err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	challenge := models.UserToken{
+		Username:    user.Username,
+		Purpose:     models.TwoFactorChallenge,
+		TokenHash:   tokenHash,
+		TimeExpires: time.Now().UTC().Add(twoFactorChallengeLifetime).Unix(),
+	}
+
+	if success := db.AddUserToken(ctx, &challenge); !success {
+		Error.Printf("Could not add two-factor challenge for user %s\n", user.Username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Issued two-factor challenge %s for user %s\n", challenge.ID, user.Username)
+
+	c.IndentedJSON(http.StatusOK, gin.H{
+		"twoFactorRequired": true,
+		"challengeToken":    token,
+	})
+}
+
+// checks the code or recovery code in the request, and updates the user so that it
+// can't be used again
+func checkSecondFactor(user *models.User, request *TwoFactorTokenRequest) bool {
+	if len(request.Code) > 0 {
+		valid, step := auth.ValidateTOTP(user.TwoFactor.Secret, request.Code, time.Now().UTC())
+		if !valid || step <= user.TwoFactor.LastStep {
+			return false
+		}
+
+		user.TwoFactor.LastStep = step
+		return true
+	}
+
+	codeHash := auth.HashOpaqueToken(auth.NormaliseRecoveryCode(request.RecoveryCode))
+
+	index := slices.Index(user.TwoFactor.RecoveryCodes, codeHash)
+	if index < 0 {
+		return false
+	}
+
+	user.TwoFactor.RecoveryCodes = slices.Delete(user.TwoFactor.RecoveryCodes, index, index+1)
+	return true
+}
+
+// gives the user new recovery codes, returning them so they can be shown once
+func setRecoveryCodes(user *models.User) (bool, []string) {
+	recoveryCodes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		Error.Printf("Could not generate recovery codes: %s\n", err)
+		return false, nil
+	}
+
+	user.TwoFactor.RecoveryCodes = make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		user.TwoFactor.RecoveryCodes[i] = auth.HashOpaqueToken(auth.NormaliseRecoveryCode(code))
+	}
+
+	return true, recoveryCodes
+}
+
+// gets the user in the username parameter, aborting the request if it wasn't made by them
+func getCallingUser(ctx context.Context, c *gin.Context) (bool, *models.User) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if callingUsername != username {
+		Error.Println("Cannot manage the account of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return false, nil
+	}
+
+	exists, user := db.GetUser(ctx, username)
+	if !exists {
+		Error.Printf("User %s does not exist", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return false, nil
+	}
+
+	return true, user
+}
+
+// like getCallingUser, but also checks the password in the request body
+func getCallingUserWithPassword(ctx context.Context, c *gin.Context) (bool, *models.User) {
+	var request PasswordRequest
+	if err := c.BindJSON(&request); err != nil || len(request.Password) <= 0 {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, nil
+	}
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return false, nil
+	}
+
+	if credentialError := user.CheckPassword(request.Password); credentialError != nil {
+		Error.Println("Invalid password")
+		c.AbortWithError(http.StatusUnauthorized, credentialError)
+		return false, nil
+	}
+
+	return true, user
+}
@@ -0,0 +1,354 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"phrasmotica/bore-score-api/blobs"
+	"phrasmotica/bore-score-api/images"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// the largest image file that can be uploaded
+const maxImageSize = 5 << 20
+
+// the longest side of an image's thumbnail, in pixels
+const thumbnailSize = 256
+
+// uploaded images are served from URLs with this prefix
+const imagesPath = "/images/"
+
+// images attached to results have names with this prefix, and are only served to users
+// who can see the result
+const attachmentPrefix = "attachment-"
+
+var blobStore blobs.Store
+
+// ImageResponse contains the URLs of an uploaded image and its thumbnail
+type ImageResponse struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// an image that's been uploaded and checked, but not stored yet
+type uploadedImage struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// StartBlobStore sets up the store that uploaded images are kept in
+func StartBlobStore(ctx context.Context) {
+	store, err := blobs.NewStoreFromEnv()
+	if err != nil {
+		Error.Printf("Could not create blob store, image uploads are disabled: %s\n", err)
+		return
+	}
+
+	blobStore = store
+
+	Info.Printf("Storing uploaded images in %T\n", store)
+}
+
+// GetImage serves an uploaded image or thumbnail. Images attached to results aren't
+// served here
+func GetImage(c *gin.Context) {
+	name := c.Param("name")
+
+	if strings.HasPrefix(name, attachmentPrefix) {
+		Error.Printf("Image %s is attached to a result\n", name)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	// images are never changed once they've been uploaded, only replaced by new ones
+	serveImage(c, name, "public, max-age=31536000, immutable")
+}
+
+// UploadProfilePicture replaces the calling user's profile picture with an uploaded image
+func UploadProfilePicture(c *gin.Context) {
+	ctx := context.TODO()
+
+	success, user := getCallingUser(ctx, c)
+	if !success {
+		return
+	}
+
+	success, image := readUploadedImage(c)
+	if !success {
+		return
+	}
+
+	success, name := storeImage(ctx, image, "")
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	oldPicture := user.UploadedPicture
+	user.UploadedPicture = name
+	user.ProfilePicture = imagesPath + name
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update profile picture for user %s\n", user.Username)
+		deleteImage(ctx, name)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	deleteImage(ctx, oldPicture)
+
+	Info.Printf("Uploaded profile picture for user %s\n", user.Username)
+
+	c.IndentedJSON(http.StatusOK, createImageResponse(name))
+}
+
+// UploadGroupPicture replaces the group's profile picture with an uploaded image
+func UploadGroupPicture(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if !isGroupAdmin(group, c.GetString("username")) {
+		Error.Println("Cannot change the picture of a group that someone else created")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, image := readUploadedImage(c)
+	if !success {
+		return
+	}
+
+	success, name := storeImage(ctx, image, "")
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	oldPicture := group.UploadedPicture
+	group.UploadedPicture = name
+	group.ProfilePicture = imagesPath + name
+
+	if success := db.UpdateGroup(ctx, group); !success {
+		Error.Printf("Could not update profile picture for group %s\n", groupId)
+		deleteImage(ctx, name)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	deleteImage(ctx, oldPicture)
+
+	Info.Printf("Uploaded profile picture for group %s\n", groupId)
+
+	c.IndentedJSON(http.StatusOK, createImageResponse(name))
+}
+
+// UploadGameImage replaces the game's image with an uploaded one
+func UploadGameImage(c *gin.Context) {
+	gameId := c.Param("gameId")
+
+	ctx := context.TODO()
+
+	success, game := db.GetGame(ctx, gameId)
+	if !success {
+		Error.Printf("Game %s does not exist\n", gameId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	success, image := readUploadedImage(c)
+	if !success {
+		return
+	}
+
+	success, name := storeImage(ctx, image, "")
+	if !success {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	oldImage := game.UploadedImage
+	game.UploadedImage = name
+	game.ImageLink = imagesPath + name
+
+	if success := db.UpdateGame(ctx, game); !success {
+		Error.Printf("Could not update image for game %s\n", gameId)
+		deleteImage(ctx, name)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	deleteImage(ctx, oldImage)
+
+	Info.Printf("Uploaded image for game %s\n", gameId)
+
+	c.IndentedJSON(http.StatusOK, createImageResponse(name))
+}
+
+// reads the image in the request's multipart form, aborting the request if it's missing,
+// too big or not an image
+func readUploadedImage(c *gin.Context) (bool, *uploadedImage) {
+	if blobStore == nil {
+		Error.Println("Image uploads are disabled")
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return false, nil
+	}
+
+	// leave some room for the rest of the multipart form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageSize+(64<<10))
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			Error.Println("Uploaded image is too large")
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return false, nil
+		}
+
+		Error.Printf("Invalid image upload: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, nil
+	}
+
+	if header.Size > maxImageSize {
+		Error.Printf("Uploaded image is too large (%d bytes)\n", header.Size)
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return false, nil
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		Error.Printf("Could not open uploaded image: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, nil
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		Error.Printf("Could not read uploaded image: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, nil
+	}
+
+	// the client's content type isn't trusted
+	contentType, extension, err := images.Sniff(data)
+	if errors.Is(err, images.ErrUnsupportedType) {
+		Error.Println("Uploaded file is not a JPEG, PNG or GIF image")
+		c.AbortWithStatus(http.StatusUnsupportedMediaType)
+		return false, nil
+	}
+
+	if errors.Is(err, images.ErrTooLarge) {
+		Error.Println("Uploaded image has too many pixels")
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return false, nil
+	}
+
+	if err != nil {
+		Error.Printf("Uploaded image is invalid: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return false, nil
+	}
+
+	return true, &uploadedImage{
+		Data:        data,
+		ContentType: contentType,
+		Extension:   extension,
+	}
+}
+
+// stores the image along with its thumbnail under a new name starting with the prefix,
+// which is returned
+func storeImage(ctx context.Context, image *uploadedImage, prefix string) (bool, string) {
+	thumbnail, err := images.Thumbnail(image.Data, thumbnailSize)
+	if err != nil {
+		Error.Printf("Could not create thumbnail: %s\n", err)
+		return false, ""
+	}
+
+	name := prefix + uuid.NewString() + image.Extension
+
+	if err := blobStore.Put(ctx, name, image.ContentType, image.Data); err != nil {
+		Error.Printf("Could not store image %s: %s\n", name, err)
+		return false, ""
+	}
+
+	if err := blobStore.Put(ctx, thumbnailName(name), "image/jpeg", thumbnail); err != nil {
+		Error.Printf("Could not store thumbnail of image %s: %s\n", name, err)
+		blobStore.Delete(ctx, name)
+		return false, ""
+	}
+
+	return true, name
+}
+
+// responds with the uploaded image with the given name
+func serveImage(c *gin.Context, name string, cacheControl string) {
+	if blobStore == nil {
+		Error.Println("Image uploads are disabled")
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	data, contentType, err := blobStore.Get(context.TODO(), name)
+	if errors.Is(err, blobs.ErrNotFound) {
+		Error.Printf("Image %s does not exist\n", name)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		Error.Printf("Could not get image %s: %s\n", name, err)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	c.Header("Cache-Control", cacheControl)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func createImageResponse(name string) *ImageResponse {
+	return &ImageResponse{
+		URL:          imagesPath + name,
+		ThumbnailURL: imagesPath + thumbnailName(name),
+	}
+}
+
+// deletes the uploaded image with the given name, along with its thumbnail
+func deleteImage(ctx context.Context, name string) {
+	if blobStore == nil || len(name) <= 0 {
+		return
+	}
+
+	for _, n := range []string{name, thumbnailName(name)} {
+		if err := blobStore.Delete(ctx, n); err != nil && !errors.Is(err, blobs.ErrNotFound) {
+			Error.Printf("Could not delete image %s: %s\n", n, err)
+		}
+	}
+}
+
+func thumbnailName(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + "-thumbnail.jpg"
+}
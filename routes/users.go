@@ -19,8 +19,22 @@ type CreateUserRequest struct {
 }
 
 type GetUserResponse struct {
-	Username string `json:"username" bson:"username"`
-	Email    string `json:"email" bson:"email"`
+	Username       string `json:"username" bson:"username"`
+	Email          string `json:"email" bson:"email"`
+	DisplayName    string `json:"displayName" bson:"displayName"`
+	ProfilePicture string `json:"profilePicture" bson:"profilePicture"`
+
+	EmailVerified    *bool                    `json:"emailVerified,omitempty" bson:"emailVerified,omitempty"`
+	TwoFactorEnabled *bool                    `json:"twoFactorEnabled,omitempty" bson:"twoFactorEnabled,omitempty"`
+	EmailPreferences *models.EmailPreferences `json:"emailPreferences,omitempty" bson:"emailPreferences,omitempty"`
+
+	FriendRestrictions *models.FriendRestrictions `json:"friendRestrictions,omitempty" bson:"friendRestrictions,omitempty"`
+	Privacy            *models.PrivacySettings    `json:"privacy,omitempty" bson:"privacy,omitempty"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName    string `json:"displayName" bson:"displayName"`
+	ProfilePicture string `json:"profilePicture" bson:"profilePicture"`
 }
 
 type UpdatePasswordRequest struct {
@@ -51,16 +65,30 @@ func GetUser(c *gin.Context) {
 		return
 	}
 
+	callingUsername := c.GetString("username")
+
+	if !canSeeProfile(ctx, callingUsername, user) {
+		Error.Printf("User %s cannot see user %s\n", callingUsername, username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
 	Info.Printf("Got user %s\n", username)
 
 	res := &GetUserResponse{
-		Username: username,
+		Username:       username,
+		DisplayName:    user.DisplayName,
+		ProfilePicture: user.ProfilePicture,
 	}
 
 	// only return this user's email address if the request was made by this user
-	callingUsername := c.GetString("username")
 	if callingUsername == username {
 		res.Email = user.Email
+		res.EmailVerified = &user.EmailVerified
+		res.TwoFactorEnabled = &user.TwoFactor.Enabled
+		res.EmailPreferences = &user.EmailPreferences
+		res.FriendRestrictions = &user.FriendRestrictions
+		res.Privacy = &user.Privacy
 	}
 
 	c.IndentedJSON(http.StatusOK, res)
@@ -82,7 +110,18 @@ func RegisterUser(c *gin.Context) {
 		TimeCreated: time.Now().UTC().Unix(),
 		Email:       request.Email,
 		Password:    request.Password,
+		HasPassword: true,
 		Permissions: []string{},
+
+		DisplayName:    request.DisplayName,
+		ProfilePicture: request.ProfilePicture,
+
+		EmailPreferences: models.DefaultEmailPreferences(),
+		Privacy:          models.DefaultPrivacySettings(),
+	}
+
+	if len(newUser.DisplayName) <= 0 {
+		newUser.DisplayName = newUser.Username
 	}
 
 	if err := newUser.HashPassword(newUser.Password); err != nil {
@@ -97,32 +136,34 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	success := db.AddUser(ctx, &newUser)
-	if !success {
-		Error.Printf("Could not add user %s\n", newUser.Username)
-		c.AbortWithStatus(http.StatusServiceUnavailable)
+	if models.IsGuestUsername(newUser.Username) {
+		Error.Printf("Username %s is reserved for guest players\n", newUser.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
-	Info.Printf("Created new user %s\n", newUser.Username)
+	if db.UserExists(ctx, newUser.Username) {
+		Error.Printf("User %s already exists\n", newUser.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
 
-	// create a player record that corresponds to the new user
-	newPlayer := models.Player{
-		ID:             uuid.NewString(),
-		Username:       newUser.Username,
-		TimeCreated:    time.Now().UTC().Unix(),
-		DisplayName:    request.DisplayName,
-		ProfilePicture: request.ProfilePicture,
+	if db.IsUsernameReserved(ctx, newUser.Username) {
+		Error.Printf("Username %s belonged to a deleted account\n", newUser.Username)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
 	}
 
-	playerSuccess := db.AddPlayer(ctx, &newPlayer)
-	if !playerSuccess {
-		Error.Printf("Could not add player record for user %s\n", newUser.Username)
+	success := db.AddUser(ctx, &newUser)
+	if !success {
+		Error.Printf("Could not add user %s\n", newUser.Username)
 		c.AbortWithStatus(http.StatusServiceUnavailable)
 		return
 	}
 
-	Info.Printf("Created player record for new user %s\n", newUser.Username)
+	Info.Printf("Created new user %s\n", newUser.Username)
+
+	sendVerificationEmail(ctx, &newUser, newUser.Email)
 
 	c.IndentedJSON(http.StatusNoContent, nil)
 }
@@ -186,6 +227,8 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
+	revokeOtherSessions(ctx, user.Username, c.GetString("sessionId"))
+
 	Info.Printf("Updated password for user %s\n", request.Username)
 
 	c.IndentedJSON(http.StatusNoContent, nil)
@@ -206,3 +249,245 @@ func validateUpdatePasswordRequest(request *UpdatePasswordRequest) (bool, string
 
 	return true, ""
 }
+
+func UpdateEmailPreferences(c *gin.Context) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if username != callingUsername {
+		Error.Println("Cannot update email preferences of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	var preferences models.EmailPreferences
+
+	if err := c.BindJSON(&preferences); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	exists, user := db.GetUser(ctx, username)
+	if !exists {
+		Error.Printf("User %s does not exist", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	user.EmailPreferences = preferences
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update email preferences for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Updated email preferences for user %s\n", username)
+
+	c.IndentedJSON(http.StatusOK, user.EmailPreferences)
+}
+
+// UpdateProfile sets the display name and profile picture that the user is shown with
+func UpdateProfile(c *gin.Context) {
+	username := c.Param("username")
+	callingUsername := c.GetString("username")
+
+	if username != callingUsername {
+		Error.Println("Cannot update profile of a different user")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	var request UpdateProfileRequest
+
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if len(request.DisplayName) <= 0 {
+		Error.Println("Error validating profile: display name is missing")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	exists, user := db.GetUser(ctx, username)
+	if !exists {
+		Error.Printf("User %s does not exist", username)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	user.DisplayName = request.DisplayName
+	user.ProfilePicture = request.ProfilePicture
+
+	if success := db.UpdateUser(ctx, user); !success {
+		Error.Printf("Could not update profile for user %s\n", username)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Updated profile for user %s\n", username)
+
+	c.IndentedJSON(http.StatusOK, user.ToPlayer())
+}
+
+// MigratePlayers merges any players left over from before players and users were the
+// same thing into their users. It's safe to call on every startup
+func MigratePlayers(ctx context.Context) {
+	success, migratedCount := db.MigratePlayers(ctx)
+	if !success {
+		Error.Printf("Could not migrate all players into users, %d were migrated\n", migratedCount)
+		return
+	}
+
+	if migratedCount > 0 {
+		Info.Printf("Migrated %d players into users\n", migratedCount)
+	}
+}
+
+// removes the user from all results, groups and invitations, and deletes their account.
+// Their username is reserved so that it can't be registered again
+func deleteAccount(ctx context.Context, username string) bool {
+	success, scrubbedCount := db.ScrubResultsWithPlayer(ctx, username)
+	if !success {
+		Error.Printf("Could not scrub user %s from results\n", username)
+		return false
+	}
+
+	Info.Printf("Scrubbed user %s from %d results\n", username, scrubbedCount)
+
+	// the approvals are kept so that the results keep their approval status
+	success, approvals := db.GetApprovalsByUser(ctx, username)
+	if !success {
+		Error.Printf("Could not get approvals for user %s\n", username)
+		return false
+	}
+
+	for _, a := range approvals {
+		a.Username = ""
+
+		if success := db.UpdateApproval(ctx, &a); !success {
+			Error.Printf("Could not scrub user %s from approval %s\n", username, a.ID)
+			return false
+		}
+	}
+
+	// comments are kept as deleted so that replies to them still make sense
+	success, comments := db.GetCommentsByUser(ctx, username)
+	if !success {
+		Error.Printf("Could not get comments for user %s\n", username)
+		return false
+	}
+
+	for _, c := range comments {
+		c.Username = ""
+		c.Text = ""
+		c.Deleted = true
+
+		if success := db.UpdateComment(ctx, &c); !success {
+			Error.Printf("Could not scrub user %s from comment %s\n", username, c.ID)
+			return false
+		}
+	}
+
+	if success, reactions := db.GetReactionsByUser(ctx, username); success {
+		for _, r := range reactions {
+			db.DeleteReaction(ctx, r.ResultID, r.ID)
+		}
+	}
+
+	success, scrubbedCount = db.ScrubActivityWithUser(ctx, username)
+	if !success {
+		Error.Printf("Could not scrub user %s from group activity\n", username)
+		return false
+	}
+
+	Info.Printf("Scrubbed user %s from %d activity entries\n", username, scrubbedCount)
+
+	success, memberships := db.GetGroupMemberships(ctx, username)
+	if !success {
+		Error.Printf("Could not get group memberships for user %s\n", username)
+		return false
+	}
+
+	for _, m := range memberships {
+		if success := db.DeleteGroupMembership(ctx, m.GroupID, username); !success {
+			Error.Printf("Could not remove user %s from group %s\n", username, m.GroupID)
+			return false
+		}
+	}
+
+	success, invitations := db.GetGroupInvitationsInvolving(ctx, username)
+	if !success {
+		Error.Printf("Could not get group invitations for user %s\n", username)
+		return false
+	}
+
+	for _, i := range invitations {
+		if success := db.DeleteGroupInvitation(ctx, i.ID); !success {
+			Error.Printf("Could not delete group invitation %s\n", i.ID)
+			return false
+		}
+	}
+
+	Info.Printf("Removed user %s from %d groups and %d invitations\n", username, len(memberships), len(invitations))
+
+	if success, friendships := db.GetFriendships(ctx, username); success {
+		for _, f := range friendships {
+			db.DeleteFriendship(ctx, f.ID)
+		}
+	}
+
+	// nobody should be able to log in as this user again, even if someone else
+	// registers the same username later
+	if success, identities := db.GetExternalIdentities(ctx, username); success {
+		for _, i := range identities {
+			db.DeleteExternalIdentity(ctx, username, i.ID)
+		}
+	}
+
+	if success, keys := db.GetAPIKeys(ctx, username); success {
+		for _, k := range keys {
+			if !k.Revoked {
+				k.Revoked = true
+				k.TimeRevoked = time.Now().UTC().Unix()
+				db.UpdateAPIKey(ctx, &k)
+			}
+		}
+	}
+
+	revokeOtherSessions(ctx, username, "")
+
+	if success, deletedCount := db.DeleteUserTokens(ctx, username); success {
+		Info.Printf("Deleted %d tokens for user %s\n", deletedCount, username)
+	}
+
+	if success, deletedCount := db.DeleteNotifications(ctx, username); success {
+		Info.Printf("Deleted %d notifications for user %s\n", deletedCount, username)
+	}
+
+	if exists, user := db.GetUser(ctx, username); exists {
+		deleteImage(ctx, user.UploadedPicture)
+	}
+
+	// the username can't be used again, so that nobody inherits the groups this user
+	// created or shows up in the history they're still named in
+	if success := db.ReserveUsername(ctx, username); !success {
+		Error.Printf("Could not reserve username %s\n", username)
+		return false
+	}
+
+	if success := db.DeleteUser(ctx, username); !success {
+		Error.Printf("Could not delete user %s\n", username)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,410 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"phrasmotica/bore-score-api/models"
+	"phrasmotica/bore-score-api/webhooks"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+// how often pending webhook deliveries are checked for ones that are due to be retried
+const webhookRetryInterval = 10 * time.Second
+
+type CreateWebhookRequest struct {
+	URL    string                    `json:"url" bson:"url"`
+	Secret string                    `json:"secret" bson:"secret"`
+	Events []models.WebhookEventType `json:"events" bson:"events"`
+}
+
+// like models.Webhook, but without the shared secret
+type WebhookResponse struct {
+	ID          string                    `json:"id" bson:"id"`
+	GroupID     string                    `json:"groupId" bson:"groupId"`
+	TimeCreated int64                     `json:"timeCreated" bson:"timeCreated"`
+	CreatedBy   string                    `json:"createdBy" bson:"createdBy"`
+	URL         string                    `json:"url" bson:"url"`
+	Events      []models.WebhookEventType `json:"events" bson:"events"`
+}
+
+type WebhookPayload struct {
+	Event       models.WebhookEventType `json:"event" bson:"event"`
+	GroupID     string                  `json:"groupId" bson:"groupId"`
+	TimeCreated int64                   `json:"timeCreated" bson:"timeCreated"`
+	Data        interface{}             `json:"data" bson:"data"`
+}
+
+// secrets shorter than this are too easy to guess
+const minWebhookSecretLength = 16
+
+var webhookEventTypes = []models.WebhookEventType{
+	models.WebhookResultCreated,
+	models.WebhookResultApproved,
+	models.WebhookMemberJoined,
+}
+
+func GetWebhooks(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot manage webhooks for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, groupWebhooks := db.GetWebhooks(ctx, group.ID)
+	if !success {
+		Error.Printf("Could not get webhooks for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	responses := []WebhookResponse{}
+
+	for _, w := range groupWebhooks {
+		responses = append(responses, createWebhookResponse(&w))
+	}
+
+	Info.Printf("Got %d webhooks for group %s\n", len(responses), group.ID)
+
+	c.IndentedJSON(http.StatusOK, responses)
+}
+
+func PostWebhook(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	var request CreateWebhookRequest
+
+	if err := c.BindJSON(&request); err != nil {
+		Error.Println("Invalid body format")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if success, err := validateCreateWebhookRequest(&request); !success {
+		Error.Printf("Error validating new webhook: %s\n", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot manage webhooks for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	if group.Archived {
+		Error.Printf("Group %s is archived\n", group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	newWebhook := models.Webhook{
+		GroupID:   group.ID,
+		CreatedBy: callingUsername,
+		URL:       request.URL,
+		Secret:    request.Secret,
+		Events:    request.Events,
+	}
+
+	if success := db.AddWebhook(ctx, &newWebhook); !success {
+		Error.Printf("Could not add webhook for group %s\n", group.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Added webhook %s for group %s\n", newWebhook.ID, group.ID)
+
+	c.IndentedJSON(http.StatusCreated, createWebhookResponse(&newWebhook))
+}
+
+func validateCreateWebhookRequest(request *CreateWebhookRequest) (bool, string) {
+	parsedUrl, err := url.Parse(request.URL)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || len(parsedUrl.Host) <= 0 {
+		return false, "webhook URL must be an absolute HTTP or HTTPS URL"
+	}
+
+	// hostnames are checked when the webhook is sent, once they've been resolved
+	if ip := net.ParseIP(parsedUrl.Hostname()); ip != nil && !webhooks.IsPublicIP(ip) {
+		return false, "webhook URL must point to a public address"
+	}
+
+	if len(request.Secret) < minWebhookSecretLength {
+		return false, "webhook secret is too short"
+	}
+
+	if len(request.Events) <= 0 {
+		return false, "webhook has no events"
+	}
+
+	for _, e := range request.Events {
+		if !slices.Contains(webhookEventTypes, e) {
+			return false, "webhook has an unknown event"
+		}
+	}
+
+	return true, ""
+}
+
+func DeleteWebhook(c *gin.Context) {
+	groupId := c.Param("groupId")
+	webhookId := c.Param("webhookId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot manage webhooks for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, webhook := db.GetWebhook(ctx, webhookId)
+	if !success || webhook.GroupID != group.ID {
+		Error.Printf("Webhook %s does not exist in group %s\n", webhookId, group.ID)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if success := deleteWebhook(ctx, webhook); !success {
+		Error.Printf("Could not delete webhook %s\n", webhook.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Deleted webhook %s from group %s\n", webhook.ID, group.ID)
+
+	c.IndentedJSON(http.StatusNoContent, nil)
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	groupId := c.Param("groupId")
+	webhookId := c.Param("webhookId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot manage webhooks for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, webhook := db.GetWebhook(ctx, webhookId)
+	if !success || webhook.GroupID != group.ID {
+		Error.Printf("Webhook %s does not exist in group %s\n", webhookId, group.ID)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	success, deliveries := db.GetWebhookDeliveries(ctx, webhook.ID)
+	if !success {
+		Error.Printf("Could not get deliveries for webhook %s\n", webhook.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// newest first
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].TimeCreated > deliveries[j].TimeCreated
+	})
+
+	Info.Printf("Got %d deliveries for webhook %s\n", len(deliveries), webhook.ID)
+
+	c.IndentedJSON(http.StatusOK, deliveries)
+}
+
+func ReplayWebhookDelivery(c *gin.Context) {
+	groupId := c.Param("groupId")
+	webhookId := c.Param("webhookId")
+	deliveryId := c.Param("deliveryId")
+
+	ctx := context.TODO()
+
+	success, group := db.GetGroup(ctx, groupId)
+	if !success {
+		Error.Printf("Group %s does not exist\n", groupId)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	callingUsername := c.GetString("username")
+
+	if !isGroupAdmin(group, callingUsername) {
+		Error.Printf("User %s cannot manage webhooks for group %s\n", callingUsername, group.ID)
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	success, webhook := db.GetWebhook(ctx, webhookId)
+	if !success || webhook.GroupID != group.ID {
+		Error.Printf("Webhook %s does not exist in group %s\n", webhookId, group.ID)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	success, delivery := db.GetWebhookDelivery(ctx, deliveryId)
+	if !success || delivery.WebhookID != webhook.ID {
+		Error.Printf("Delivery %s does not exist for webhook %s\n", deliveryId, webhook.ID)
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if delivery.Status != models.DeliveryFailed {
+		Error.Printf("Delivery %s has not failed\n", delivery.ID)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	replay := models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		GroupID:   group.ID,
+		EventType: delivery.EventType,
+		Payload:   delivery.Payload,
+		Status:    models.DeliveryPending,
+		ReplayOf:  delivery.ID,
+
+		TimeNextAttempt: time.Now().UTC().Add(webhooks.LeaseDuration).Unix(),
+	}
+
+	if success := db.AddWebhookDelivery(ctx, &replay); !success {
+		Error.Printf("Could not add replay of delivery %s\n", delivery.ID)
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	Info.Printf("Replaying delivery %s for webhook %s as delivery %s\n", delivery.ID, webhook.ID, replay.ID)
+
+	go attemptWebhookDelivery(*webhook, replay)
+
+	c.IndentedJSON(http.StatusAccepted, replay)
+}
+
+func createWebhookResponse(webhook *models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:          webhook.ID,
+		GroupID:     webhook.GroupID,
+		TimeCreated: webhook.TimeCreated,
+		CreatedBy:   webhook.CreatedBy,
+		URL:         webhook.URL,
+		Events:      webhook.Events,
+	}
+}
+
+// deletes the webhook along with its delivery log
+func deleteWebhook(ctx context.Context, webhook *models.Webhook) bool {
+	success, deletedCount := db.DeleteWebhookDeliveries(ctx, webhook.ID)
+	if !success {
+		Error.Printf("Could not delete deliveries for webhook %s\n", webhook.ID)
+		return false
+	}
+
+	Info.Printf("Deleted %d deliveries for webhook %s\n", deletedCount, webhook.ID)
+
+	return db.DeleteWebhook(ctx, webhook.ID)
+}
+
+// StartWebhooks retries failed webhook deliveries in the background
+func StartWebhooks(ctx context.Context) {
+	go webhooks.RunDeliveries(ctx, db, webhookRetryInterval)
+}
+
+// records a delivery of the event to each of the group's webhooks that is subscribed
+// to it, and makes the first attempt at them in the background
+func triggerWebhooks(ctx context.Context, groupId string, eventType models.WebhookEventType, data interface{}) {
+	if len(groupId) <= 0 {
+		return
+	}
+
+	success, groupWebhooks := db.GetWebhooks(ctx, groupId)
+	if !success {
+		Error.Printf("Could not get webhooks for group %s\n", groupId)
+		return
+	}
+
+	payload, err := json.Marshal(WebhookPayload{
+		Event:       eventType,
+		GroupID:     groupId,
+		TimeCreated: time.Now().UTC().Unix(),
+		Data:        data,
+	})
+
+	if err != nil {
+		Error.Println(err)
+		return
+	}
+
+	for _, w := range groupWebhooks {
+		if !slices.Contains(w.Events, eventType) {
+			continue
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID: w.ID,
+			GroupID:   groupId,
+			EventType: eventType,
+			Payload:   string(payload),
+			Status:    models.DeliveryPending,
+
+			TimeNextAttempt: time.Now().UTC().Add(webhooks.LeaseDuration).Unix(),
+		}
+
+		if success := db.AddWebhookDelivery(ctx, &delivery); !success {
+			Error.Printf("Could not add %s delivery for webhook %s\n", eventType, w.ID)
+			continue
+		}
+
+		go attemptWebhookDelivery(w, delivery)
+	}
+}
+
+// makes the first attempt at a delivery, which is leased when it's added. Any retries are
+// made by webhooks.RunDeliveries
+func attemptWebhookDelivery(webhook models.Webhook, delivery models.WebhookDelivery) {
+	webhooks.Attempt(context.Background(), db, &webhook, &delivery)
+}
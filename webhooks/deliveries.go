@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"log"
+	"os"
+	"phrasmotica/bore-score-api/models"
+	"time"
+)
+
+// TODO: put these in a more central place, or inject them as dependencies
+var (
+	Info  *log.Logger = log.New(os.Stdout, "INFO: ", log.LstdFlags|log.Lshortfile)
+	Error *log.Logger = log.New(os.Stdout, "ERROR: ", log.LstdFlags|log.Lshortfile)
+)
+
+// LeaseDuration is how long a delivery is left alone by RunDeliveries once an attempt at
+// it has started. It's longer than an attempt can take, so that each attempt is only
+// made once
+const LeaseDuration = 1 * time.Minute
+
+// DeliveryStore is the part of the database that webhook deliveries need
+type DeliveryStore interface {
+	GetWebhook(ctx context.Context, webhookId string) (bool, *models.Webhook)
+	GetPendingWebhookDeliveries(ctx context.Context) (bool, []models.WebhookDelivery)
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) bool
+}
+
+// RunDeliveries retries pending deliveries every interval until the context is cancelled.
+// Deliveries stay pending until they've succeeded or have run out of attempts, so they
+// survive restarts
+func RunDeliveries(ctx context.Context, store DeliveryStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ProcessDeliveries(ctx, store, time.Now().UTC())
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ProcessDeliveries attempts every pending delivery that is due
+func ProcessDeliveries(ctx context.Context, store DeliveryStore, now time.Time) {
+	success, deliveries := store.GetPendingWebhookDeliveries(ctx)
+	if !success {
+		Error.Println("Could not get pending webhook deliveries")
+		return
+	}
+
+	for _, d := range deliveries {
+		if d.TimeNextAttempt > now.Unix() {
+			continue
+		}
+
+		success, webhook := store.GetWebhook(ctx, d.WebhookID)
+		if !success {
+			Error.Printf("Webhook %s of delivery %s no longer exists\n", d.WebhookID, d.ID)
+
+			d.Status = models.DeliveryFailed
+			d.LastError = "webhook no longer exists"
+
+			if success := store.UpdateWebhookDelivery(ctx, &d); !success {
+				Error.Printf("Could not update delivery %s\n", d.ID)
+			}
+
+			continue
+		}
+
+		d.TimeNextAttempt = now.Add(LeaseDuration).Unix()
+
+		if success := store.UpdateWebhookDelivery(ctx, &d); !success {
+			Error.Printf("Could not lease delivery %s\n", d.ID)
+			continue
+		}
+
+		Attempt(ctx, store, webhook, &d)
+	}
+}
+
+// Attempt sends the delivery once and records the outcome. If it fails and has attempts
+// left, it's scheduled to be retried after a backoff. The delivery should already have
+// been leased so that RunDeliveries doesn't attempt it at the same time
+func Attempt(ctx context.Context, store DeliveryStore, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	responseStatus, err := Send(ctx, webhook.URL, webhook.Secret, string(delivery.EventType), delivery.ID, []byte(delivery.Payload))
+
+	now := time.Now().UTC()
+
+	delivery.Attempts++
+	delivery.TimeLastAttempt = now.Unix()
+	delivery.ResponseStatus = responseStatus
+
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.LastError = ""
+	} else {
+		Error.Printf("Attempt %d of delivery %s failed: %s\n", delivery.Attempts, delivery.ID, err)
+
+		delivery.LastError = err.Error()
+		delivery.TimeNextAttempt = now.Add(Backoff(delivery.Attempts)).Unix()
+
+		if delivery.Attempts >= MaxAttempts {
+			delivery.Status = models.DeliveryFailed
+		}
+	}
+
+	if success := store.UpdateWebhookDelivery(ctx, delivery); !success {
+		Error.Printf("Could not update delivery %s\n", delivery.ID)
+	}
+
+	if delivery.Status != models.DeliveryPending {
+		Info.Printf("Delivery %s for webhook %s %s after %d attempt(s)\n", delivery.ID, webhook.ID, delivery.Status, delivery.Attempts)
+	}
+}
@@ -0,0 +1,150 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is attempted before it is marked as failed
+	MaxAttempts = 5
+
+	baseBackoff = 2 * time.Second
+	maxBackoff  = 5 * time.Minute
+)
+
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// address ranges that aren't covered by the net.IP methods, but still shouldn't be
+// reachable from webhooks
+var forbiddenNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved
+	"64:ff9b::/96",  // NAT64, which can map to any IPv4 address
+)
+
+// webhooks are only ever sent to public addresses, so that group members can't use
+// them to reach the API's own network. The address is checked when connecting rather
+// than when the webhook is created, since DNS can change in between. Redirects aren't
+// followed, and proxies aren't used because they would be checked instead
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// IsPublicIP returns whether the IP address is reachable on the public internet
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, n := range forbiddenNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := []*net.IPNet{}
+
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, n)
+	}
+
+	return networks
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the body, keyed with the webhook's shared secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns how long to wait after the given (one-indexed) failed attempt
+// before trying again. The delay doubles with each attempt, up to a limit
+func Backoff(attempt int) time.Duration {
+	backoff := baseBackoff
+
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// Send posts the payload to the URL, signed with the secret. It returns the
+// response status code, and an error if the payload was not accepted
+func Send(ctx context.Context, url string, secret string, eventType string, deliveryId string, payload []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "BoreScore-Webhooks")
+	request.Header.Set("X-BoreScore-Event", eventType)
+	request.Header.Set("X-BoreScore-Delivery", deliveryId)
+	request.Header.Set("X-BoreScore-Signature", "sha256="+Sign(secret, payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
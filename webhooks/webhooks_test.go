@@ -0,0 +1,83 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// https://en.wikipedia.org/wiki/HMAC#Examples
+	actual := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+	expected := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+
+	if actual != expected {
+		t.Errorf("Computed signature was incorrect! Actual: %s, expected: %s", actual, expected)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tables := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 16 * time.Second},
+		{20, 5 * time.Minute},
+	}
+
+	for _, table := range tables {
+		actual := Backoff(table.attempt)
+		if actual != table.expected {
+			t.Errorf("Computed backoff was incorrect! Actual: %s, expected: %s", actual, table.expected)
+		}
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tables := []struct {
+		ip       string
+		expected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+
+	for _, table := range tables {
+		actual := IsPublicIP(net.ParseIP(table.ip))
+		if actual != table.expected {
+			t.Errorf("Wrong result for %s! Expected: %t, actual: %t", table.ip, table.expected, actual)
+		}
+	}
+}
+
+func TestSendRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Webhook was sent to a loopback address")
+	}))
+
+	defer server.Close()
+
+	_, err := Send(context.Background(), server.URL, "secret", "result-created", "delivery", []byte("{}"))
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Sending to a loopback address did not fail with the right error! Actual: %v", err)
+	}
+}